@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/talav/talav/pkg/component/zorya"
+	"github.com/talav/talav/pkg/component/zorya/clientgen"
+)
+
+// NewGenerateClientCmd creates the generate-client command.
+// The command writes a typed Go client for the operations registered with the API.
+// Operations the generator cannot create a method for are reported on stderr.
+func NewGenerateClientCmd(api zorya.API) *cobra.Command {
+	var packageName, output string
+
+	command := &cobra.Command{
+		Use:   "generate-client",
+		Short: "Generate a typed Go client for the HTTP API",
+		Long: `Generate a typed Go client for the operations registered with the HTTP API.
+The client reuses the input and output structs of the handlers and is written
+to stdout unless --output is given.`,
+		Example: `  myapp generate-client --package usersclient --output usersclient/client.go
+  myapp generate-client > client.go`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := clientgen.Generate(api, packageName)
+			if err != nil {
+				return err
+			}
+
+			for _, skipped := range result.Skipped {
+				fmt.Fprintf(cmd.ErrOrStderr(), "skipped %s %s: %s\n", skipped.Method, skipped.Path, skipped.Reason)
+			}
+
+			if output == "" {
+				_, err = cmd.OutOrStdout().Write(result.Source)
+
+				return err
+			}
+
+			if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+
+			return os.WriteFile(output, result.Source, 0o644) //nolint:gosec // Generated source is not secret
+		},
+	}
+
+	command.Flags().StringVar(&packageName, "package", "client", "package name of the generated client")
+	command.Flags().StringVarP(&output, "output", "o", "", "file to write the client to (default stdout)")
+
+	return command
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/talav/talav/pkg/component/zorya"
+	"github.com/talav/talav/pkg/component/zorya/openapidiff"
+)
+
+// ErrBreakingChanges is returned by the openapi-diff command when the compared
+// documents contain breaking changes.
+var ErrBreakingChanges = errors.New("breaking OpenAPI changes found")
+
+// NewOpenAPIDiffCmd creates the openapi-diff command.
+// The command compares the API's OpenAPI document, or a second file, with a baseline file
+// and fails when the changes would break existing clients.
+func NewOpenAPIDiffCmd(api zorya.API) *cobra.Command {
+	var format string
+
+	command := &cobra.Command{
+		Use:   "openapi-diff BASELINE [REVISION]",
+		Short: "Report breaking changes between OpenAPI documents",
+		Long: `Compare the OpenAPI document of the HTTP API with a baseline file, or two
+OpenAPI files, and classify the changes as breaking or non-breaking.
+The command exits with an error when breaking changes are found.`,
+		Example: `  myapp openapi-diff openapi.json
+  myapp openapi-diff --format json old.yaml new.yaml`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("unsupported format %q, use text or json", format)
+			}
+
+			base, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			var revision []byte
+			if len(args) == 2 {
+				revision, err = os.ReadFile(args[1])
+			} else {
+				revision, err = json.Marshal(api.OpenAPI())
+			}
+			if err != nil {
+				return err
+			}
+
+			report, err := openapidiff.Compare(base, revision)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				err = report.WriteJSON(cmd.OutOrStdout())
+			} else {
+				err = report.WriteText(cmd.OutOrStdout())
+			}
+			if err != nil {
+				return err
+			}
+
+			if report.HasBreaking() {
+				cmd.SilenceUsage = true
+
+				return ErrBreakingChanges
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&format, "format", "text", "output format: text or json")
+
+	return command
+}
@@ -0,0 +1,27 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/talav/talav/pkg/component/zorya"
+)
+
+// NewCompressionMiddleware creates a response compression middleware from the provided configuration.
+// It returns an error for unsupported encodings.
+func NewCompressionMiddleware(cfg CompressionConfig) (func(http.Handler) http.Handler, error) {
+	for _, encoding := range cfg.Encodings {
+		switch encoding {
+		case zorya.EncodingZstd, zorya.EncodingGzip, zorya.EncodingDeflate:
+		default:
+			return nil, fmt.Errorf(
+				"httpserver compression.encodings: unsupported encoding %q, use zstd, gzip or deflate", encoding)
+		}
+	}
+
+	return zorya.Compress(zorya.CompressionConfig{
+		Encodings:            cfg.Encodings,
+		MinSize:              cfg.MinSize,
+		ExcludedContentTypes: cfg.ExcludedContentTypes,
+	}), nil
+}
@@ -1,6 +1,10 @@
 package httpserver
 
-import "github.com/talav/talav/pkg/component/zorya"
+import (
+	"fmt"
+
+	"github.com/talav/talav/pkg/component/zorya"
+)
 
 // Config represents the HTTP server configuration.
 type Config struct {
@@ -18,6 +22,9 @@ type Config struct {
 
 	// CORS configuration for cross-origin requests.
 	CORS CORSConfig `config:"cors"`
+
+	// Compression configuration for response bodies.
+	Compression CompressionConfig `config:"compression"`
 }
 
 // ServerConfig contains HTTP server settings.
@@ -75,7 +82,8 @@ type LoggingConfig struct {
 // APIConfig contains Zorya behavioral configuration.
 type APIConfig struct {
 	// SpecPath is the path to the OpenAPI spec without extension.
-	// Default: "/openapi" (serves /openapi.json and /openapi.yaml).
+	// Default: "/openapi" (serves /openapi.json, /openapi.yaml and the
+	// OpenAPI 3.0 renditions /openapi-3.0.json and /openapi-3.0.yaml).
 	SpecPath string `config:"specPath"`
 
 	// DocsPath is the path to the API documentation UI.
@@ -93,6 +101,16 @@ type APIConfig struct {
 	// NoFormatFallback disables fallback to application/json.
 	// Default: false.
 	NoFormatFallback bool `config:"noFormatFallback"`
+
+	// ResponseValidation validates responses against their OpenAPI schema:
+	// "log", "fail" (500 problem) or "header". Meant for development and staging.
+	// Default: "" (disabled).
+	ResponseValidation string `config:"responseValidation"`
+
+	// Locales are the languages error responses are localized in, as BCP 47 tags in
+	// preference order; the first is the default. Requires a universal translator.
+	// Default: none (error responses are not localized).
+	Locales []string `config:"locales"`
 }
 
 // OpenAPIConfig contains metadata for the OpenAPI specification.
@@ -175,6 +193,24 @@ type CORSConfig struct {
 	MaxAge int `config:"max_age"`
 }
 
+// CompressionConfig contains response compression settings.
+type CompressionConfig struct {
+	// Enabled enables the compression middleware.
+	Enabled bool `config:"enabled"`
+
+	// MinSize is the minimum body size in bytes compressed (default: 1024).
+	// Use a negative value to compress bodies of any size.
+	MinSize int `config:"minSize"`
+
+	// Encodings are the offered content codings in preference order: zstd, gzip, deflate
+	// (default: all three).
+	Encodings []string `config:"encodings"`
+
+	// ExcludedContentTypes are media types never compressed, "type/*" excludes a whole type
+	// (default: images, video, audio, fonts, archives and PDF).
+	ExcludedContentTypes []string `config:"excludedContentTypes"`
+}
+
 // DefaultConfig returns a Config with all default values set.
 func DefaultConfig() Config {
 	return Config{
@@ -216,8 +252,9 @@ func (c *Config) ToZoryaOpenAPI() *zorya.OpenAPI {
 	return spec
 }
 
-// ToZoryaConfig converts the API config to a Zorya Config.
-func (c *Config) ToZoryaConfig() *zorya.Config {
+// ToZoryaConfig converts the API config to a Zorya Config. It returns an
+// error for invalid settings.
+func (c *Config) ToZoryaConfig() (*zorya.Config, error) {
 	cfg := zorya.DefaultConfig()
 
 	// Apply API config (defaults are already applied via AsConfigWithDefaults)
@@ -226,8 +263,30 @@ func (c *Config) ToZoryaConfig() *zorya.Config {
 	cfg.SchemasPath = c.API.SchemasPath
 	cfg.DefaultFormat = c.API.DefaultFormat
 	cfg.NoFormatFallback = c.API.NoFormatFallback
+	mode, err := c.buildResponseValidation()
+	if err != nil {
+		return nil, err
+	}
+	cfg.ResponseValidation = mode
 
-	return cfg
+	return cfg, nil
+}
+
+// buildResponseValidation maps the response validation setting to a Zorya mode.
+func (c *Config) buildResponseValidation() (zorya.ResponseValidationMode, error) {
+	switch c.API.ResponseValidation {
+	case "":
+		return zorya.ResponseValidationOff, nil
+	case "log":
+		return zorya.ResponseValidationLog, nil
+	case "fail":
+		return zorya.ResponseValidationFail, nil
+	case "header":
+		return zorya.ResponseValidationHeader, nil
+	default:
+		return zorya.ResponseValidationOff, fmt.Errorf(
+			"httpserver api.responseValidation: unknown value %q, use log, fail or header", c.API.ResponseValidation)
+	}
 }
 
 // buildInfo constructs the Info section of OpenAPI spec.
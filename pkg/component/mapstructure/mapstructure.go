@@ -95,6 +95,8 @@ func (u *Unmarshaler) unmarshalValue(data any, rv reflect.Value, fieldPath strin
 		return u.unmarshalPtr(data, rv, fieldPath)
 	case reflect.Slice:
 		return u.unmarshalSlice(data, rv, fieldPath)
+	case reflect.Map:
+		return u.unmarshalMap(data, rv, fieldPath)
 	case reflect.Struct:
 		return u.unmarshalStruct(data, rv, fieldPath)
 	default:
@@ -189,6 +191,37 @@ func (u *Unmarshaler) unmarshalSliceElements(dataVal, rv reflect.Value, fieldPat
 	return nil
 }
 
+// unmarshalMap unmarshals a map with string keys, converting each value to
+// the element type.
+func (u *Unmarshaler) unmarshalMap(data any, rv reflect.Value, fieldPath string) error {
+	// nil is acceptable for maps
+	if data == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+
+		return nil
+	}
+
+	typ := rv.Type()
+	dataVal := reflect.ValueOf(data)
+	if dataVal.Kind() != reflect.Map || dataVal.Type().Key().Kind() != reflect.String || typ.Key().Kind() != reflect.String {
+		return conversionError(fieldPath, data, typ, nil)
+	}
+
+	result := reflect.MakeMapWithSize(typ, dataVal.Len())
+	iter := dataVal.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		elem := reflect.New(typ.Elem()).Elem()
+		if err := u.unmarshalValue(iter.Value().Interface(), elem, fieldPath+"["+key+"]"); err != nil {
+			return err
+		}
+		result.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), elem)
+	}
+	rv.Set(result)
+
+	return nil
+}
+
 // unmarshalStruct unmarshals a struct value using cached field metadata.
 func (u *Unmarshaler) unmarshalStruct(data any, rv reflect.Value, fieldPath string) error {
 	// Expect map[string]any for struct data
@@ -107,6 +107,74 @@ func TestUnmarshaler_Unmarshal_Slices(t *testing.T) {
 	}
 }
 
+func TestUnmarshaler_Unmarshal_Maps(t *testing.T) {
+	type Label string
+	type Inner struct {
+		Value int
+	}
+	type MapString struct {
+		Headers map[string]string
+	}
+	type MapInt struct {
+		Counts map[Label]int
+	}
+	type MapStruct struct {
+		Items map[string]Inner
+	}
+
+	tests := []struct {
+		name     string
+		data     map[string]any
+		target   any
+		expected any
+	}{
+		{
+			name:     "map of any to string",
+			data:     map[string]any{"Headers": map[string]any{"Accept": "application/json"}},
+			target:   &MapString{},
+			expected: &MapString{Headers: map[string]string{"Accept": "application/json"}},
+		},
+		{
+			name:     "map with converted values and key type",
+			data:     map[string]any{"Counts": map[string]any{"a": "1", "b": 2.0}},
+			target:   &MapInt{},
+			expected: &MapInt{Counts: map[Label]int{"a": 1, "b": 2}},
+		},
+		{
+			name:     "map of structs",
+			data:     map[string]any{"Items": map[string]any{"x": map[string]any{"Value": 3}}},
+			target:   &MapStruct{},
+			expected: &MapStruct{Items: map[string]Inner{"x": {Value: 3}}},
+		},
+		{
+			name:     "nil map",
+			data:     map[string]any{"Headers": nil},
+			target:   &MapString{},
+			expected: &MapString{},
+		},
+	}
+
+	u := testUnmarshaler()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := u.Unmarshal(tt.data, tt.target)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tt.target)
+		})
+	}
+
+	var target MapInt
+	err := u.Unmarshal(map[string]any{"Counts": map[string]any{"a": "x"}}, &target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Counts[a]")
+
+	err = u.Unmarshal(map[string]any{"Counts": []any{1}}, &target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot convert")
+}
+
 func TestUnmarshaler_Unmarshal_BasicTypes(t *testing.T) {
 	type Basic struct {
 		Name   string
@@ -279,6 +347,12 @@ func TestUnmarshaler_Unmarshal_Errors(t *testing.T) {
 			target:      &Target{},
 			errContains: "cannot convert",
 		},
+		{
+			name:        "single value to slice",
+			data:        map[string]any{"Data": "42"},
+			target:      &Target{},
+			errContains: "cannot convert",
+		},
 		{
 			name:        "non-pointer result",
 			data:        map[string]any{"Name": "test"},
@@ -0,0 +1,54 @@
+package zorya
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+)
+
+// DeprecatedCallsMetric is the name of the counter of calls to deprecated operations.
+const DeprecatedCallsMetric = "zorya_deprecated_operation_calls_total"
+
+// NewDeprecationMiddleware creates middleware counting calls to deprecated
+// Zorya operations in a Prometheus counter, labeled by method, path and
+// operation ID. Register it as API middleware:
+//
+//	middleware, err := zorya.NewDeprecationMiddleware(registry)
+//	if err != nil {
+//	    return err
+//	}
+//	api.UseMiddleware(middleware)
+//
+// Operations are deprecated with zorya.Deprecated, zorya.VersionDeprecated or
+// by setting Operation.Deprecated. The counter is shared when the middleware
+// is created several times for the same registerer.
+func NewDeprecationMiddleware(registerer prometheus.Registerer) (func(http.Handler) http.Handler, error) {
+	calls := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: DeprecatedCallsMetric,
+		Help: "Total calls to deprecated API operations.",
+	}, []string{"method", "path", "operation_id"})
+
+	if err := registerer.Register(calls); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			return nil, err
+		}
+		existing, ok := registered.ExistingCollector.(*prometheus.CounterVec)
+		if !ok {
+			return nil, err
+		}
+		calls = existing
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if dep := zoryapkg.GetRouteDeprecationContext(r); dep != nil {
+				calls.WithLabelValues(dep.Method, dep.Path, dep.OperationID).Inc()
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
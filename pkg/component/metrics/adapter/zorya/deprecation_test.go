@@ -0,0 +1,53 @@
+package zorya
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+	"github.com/talav/talav/pkg/component/zorya/adapters"
+)
+
+type statusOutput struct {
+	Body struct {
+		Status string
+	} `body:"structured"`
+}
+
+func getStatus(ctx context.Context, input *struct{}) (*statusOutput, error) {
+	return &statusOutput{}, nil
+}
+
+func TestNewDeprecationMiddleware(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	middleware, err := NewDeprecationMiddleware(registry)
+	require.NoError(t, err)
+
+	router := chi.NewMux()
+	api := zoryapkg.NewAPI(adapters.NewChi(router))
+	api.UseMiddleware(middleware)
+	zoryapkg.Get(api, "/old", getStatus, zoryapkg.Deprecated(time.Time{}, time.Time{}, ""))
+	zoryapkg.Get(api, "/new", getStatus)
+
+	for _, path := range []string{"/old", "/old", "/new"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, DeprecatedCallsMetric, families[0].GetName())
+	require.Len(t, families[0].GetMetric(), 1)
+	assert.InDelta(t, 2, families[0].GetMetric()[0].GetCounter().GetValue(), 0)
+
+	// The counter is reused for the same registry.
+	_, err = NewDeprecationMiddleware(registry)
+	require.NoError(t, err)
+}
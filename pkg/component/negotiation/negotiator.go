@@ -4,6 +4,7 @@ import (
 	"maps"
 	"slices"
 	"sort"
+	"strings"
 )
 
 // headerFactory creates Header instances from string values.
@@ -118,6 +119,39 @@ func (c *Negotiator) GetOrderedElements(header string) ([]*Header, error) {
 	return elements, nil
 }
 
+// Acceptable returns the priorities the header does not refuse. Negotiate
+// matches priorities regardless of their quality, so filter them with
+// Acceptable first to honor q=0: a value listed with q=0 is refused, and
+// "*;q=0" refuses every value the header does not list. Values are compared
+// case-insensitively.
+func (c *Negotiator) Acceptable(header string, priorities []string) []string {
+	elements, err := c.parseAcceptHeaders(header, false)
+	if err != nil {
+		return priorities
+	}
+
+	listed := make(map[string]bool)
+	refused := make(map[string]bool)
+	for _, element := range elements {
+		value := strings.ToLower(element.Type)
+		listed[value] = true
+		if element.Quality == 0 {
+			refused[value] = true
+		}
+	}
+
+	acceptable := make([]string, 0, len(priorities))
+	for _, priority := range priorities {
+		value := strings.ToLower(priority)
+		if refused[value] || (refused["*"] && !listed[value]) {
+			continue
+		}
+		acceptable = append(acceptable, priority)
+	}
+
+	return acceptable
+}
+
 // parseAcceptHeaders parses an Accept* header string into Header instances.
 // Parses once to avoid redundant parsing (performance critical).
 func (c *Negotiator) parseAcceptHeaders(header string, strict bool) ([]*Header, error) {
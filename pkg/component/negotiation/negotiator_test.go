@@ -0,0 +1,29 @@
+package negotiation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiator_Acceptable(t *testing.T) {
+	tests := []struct {
+		name       string
+		negotiator *Negotiator
+		header     string
+		priorities []string
+		want       []string
+	}{
+		{"nothing refused", NewEncodingNegotiator(), "gzip, br;q=0.5", []string{"br", "gzip", "identity"}, []string{"br", "gzip", "identity"}},
+		{"q=0 refuses value", NewEncodingNegotiator(), "gzip;q=0, br", []string{"br", "gzip"}, []string{"br"}},
+		{"wildcard refuses unlisted values", NewEncodingNegotiator(), "gzip, *;q=0", []string{"br", "gzip", "identity"}, []string{"gzip"}},
+		{"case-insensitive", NewLanguageNegotiator(), "EN-us;q=0, fr", []string{"en-US", "fr"}, []string{"fr"}},
+		{"everything refused", NewLanguageNegotiator(), "*;q=0", []string{"en", "fr"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.negotiator.Acceptable(tt.header, tt.priorities))
+		})
+	}
+}
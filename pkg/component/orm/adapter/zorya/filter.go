@@ -0,0 +1,84 @@
+package zorya
+
+import (
+	"reflect"
+	"strings"
+
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// likeEscaper escapes the LIKE wildcards of user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// FilterScope returns a GORM scope applying the conditions of a filter
+// specification. Columns come from the whitelist of the filter struct and
+// values are bound as parameters, so requests cannot inject SQL.
+//
+//	spec := input.FilterSpec()
+//	users, err := repo.FindKeyset(ctx, keyset, zorya.FilterScope(spec))
+func FilterScope(spec zoryapkg.FilterSpec) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, condition := range spec.Conditions {
+			db = db.Where(filterExpression(condition))
+		}
+
+		return db
+	}
+}
+
+// SortScope returns a GORM scope ordering by the sort fields of a filter
+// specification. Keyset pagination orders by its own column, so use it with
+// offset pagination.
+func SortScope(spec zoryapkg.FilterSpec) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, field := range spec.Sort {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Column}, Desc: field.Desc})
+		}
+
+		return db
+	}
+}
+
+// filterExpression returns the SQL expression of a filter condition.
+func filterExpression(condition zoryapkg.FilterCondition) clause.Expression {
+	column := clause.Column{Name: condition.Column}
+
+	switch condition.Operator {
+	case zoryapkg.FilterNe:
+		return clause.Neq{Column: column, Value: condition.Value}
+	case zoryapkg.FilterGt:
+		return clause.Gt{Column: column, Value: condition.Value}
+	case zoryapkg.FilterGte:
+		return clause.Gte{Column: column, Value: condition.Value}
+	case zoryapkg.FilterLt:
+		return clause.Lt{Column: column, Value: condition.Value}
+	case zoryapkg.FilterLte:
+		return clause.Lte{Column: column, Value: condition.Value}
+	case zoryapkg.FilterIn:
+		values, _ := condition.Value.([]any)
+
+		return clause.IN{Column: column, Values: values}
+	case zoryapkg.FilterContains:
+		return likeExpression(column, "%"+likeEscaper.Replace(likeValue(condition.Value))+"%")
+	case zoryapkg.FilterPrefix:
+		return likeExpression(column, likeEscaper.Replace(likeValue(condition.Value))+"%")
+	case zoryapkg.FilterEq:
+		fallthrough
+	default:
+		return clause.Eq{Column: column, Value: condition.Value}
+	}
+}
+
+// likeValue returns the string of a contains or prefix value. Values keep
+// the type of their filter field, which can be a named string type.
+func likeValue(value any) string {
+	return reflect.ValueOf(value).String()
+}
+
+// likeExpression returns a case-insensitive LIKE expression. The escape
+// character is declared since SQLite has no default one.
+func likeExpression(column clause.Column, pattern string) clause.Expression {
+	return clause.Expr{SQL: `LOWER(?) LIKE LOWER(?) ESCAPE '\'`, Vars: []any{column, pattern}}
+}
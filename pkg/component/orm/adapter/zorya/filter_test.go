@@ -0,0 +1,111 @@
+package zorya
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+	"gorm.io/gorm"
+)
+
+type filterTestStatus string
+
+// filterTestName lowercases filter values when decoded.
+type filterTestName string
+
+func (n *filterTestName) UnmarshalText(text []byte) error {
+	*n = filterTestName(strings.ToLower(string(text)))
+
+	return nil
+}
+
+type filterTestItem struct {
+	ID    uint
+	Name  string
+	Score int
+	Group string
+}
+
+// newFilterTestName decodes a filter value like zorya does for
+// encoding.TextUnmarshaler fields.
+func newFilterTestName(t *testing.T, raw string) any {
+	t.Helper()
+
+	var name filterTestName
+	require.NoError(t, name.UnmarshalText([]byte(raw)))
+
+	return name
+}
+
+func newFilterTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := newTestDB(t, &filterTestItem{})
+	require.NoError(t, db.Create([]*filterTestItem{
+		{ID: 1, Name: "Alpha", Score: 10, Group: "b"},
+		{ID: 2, Name: "a_b", Score: 20, Group: "a"},
+		{ID: 3, Name: "axb", Score: 30, Group: "b"},
+		{ID: 4, Name: "50%", Score: 40, Group: "a"},
+		{ID: 5, Name: `back\slash`, Score: 50, Group: "b"},
+		{ID: 6, Name: "Beta", Score: 60, Group: "a"},
+	}).Error)
+
+	return db
+}
+
+func TestFilterScope(t *testing.T) {
+	db := newFilterTestDB(t)
+
+	tests := []struct {
+		name       string
+		conditions []zoryapkg.FilterCondition
+		want       []uint
+	}{
+		{"eq", []zoryapkg.FilterCondition{{Column: "score", Operator: zoryapkg.FilterEq, Value: 20}}, []uint{2}},
+		{"ne", []zoryapkg.FilterCondition{{Column: "score", Operator: zoryapkg.FilterNe, Value: 20}}, []uint{1, 3, 4, 5, 6}},
+		{"gt", []zoryapkg.FilterCondition{{Column: "score", Operator: zoryapkg.FilterGt, Value: 40}}, []uint{5, 6}},
+		{"gte", []zoryapkg.FilterCondition{{Column: "score", Operator: zoryapkg.FilterGte, Value: 40}}, []uint{4, 5, 6}},
+		{"lt", []zoryapkg.FilterCondition{{Column: "score", Operator: zoryapkg.FilterLt, Value: 20}}, []uint{1}},
+		{"lte", []zoryapkg.FilterCondition{{Column: "score", Operator: zoryapkg.FilterLte, Value: 20}}, []uint{1, 2}},
+		{"in", []zoryapkg.FilterCondition{{Column: "score", Operator: zoryapkg.FilterIn, Value: []any{10, 30}}}, []uint{1, 3}},
+		{"contains is case-insensitive", []zoryapkg.FilterCondition{{Column: "name", Operator: zoryapkg.FilterContains, Value: "ETA"}}, []uint{6}},
+		{"prefix is case-insensitive", []zoryapkg.FilterCondition{{Column: "name", Operator: zoryapkg.FilterPrefix, Value: "a"}}, []uint{1, 2, 3}},
+		{"underscore is literal", []zoryapkg.FilterCondition{{Column: "name", Operator: zoryapkg.FilterContains, Value: "a_b"}}, []uint{2}},
+		{"percent is literal", []zoryapkg.FilterCondition{{Column: "name", Operator: zoryapkg.FilterContains, Value: "0%"}}, []uint{4}},
+		{"backslash is literal", []zoryapkg.FilterCondition{{Column: "name", Operator: zoryapkg.FilterPrefix, Value: `back\`}}, []uint{5}},
+		{"named string type", []zoryapkg.FilterCondition{{Column: "name", Operator: zoryapkg.FilterContains, Value: filterTestStatus("eta")}}, []uint{6}},
+		{"text unmarshaler string type", []zoryapkg.FilterCondition{{Column: "name", Operator: zoryapkg.FilterPrefix, Value: newFilterTestName(t, "AL")}}, []uint{1}},
+		{"all conditions match", []zoryapkg.FilterCondition{
+			{Column: "group", Operator: zoryapkg.FilterEq, Value: "a"},
+			{Column: "score", Operator: zoryapkg.FilterGt, Value: 20},
+		}, []uint{4, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []uint
+			err := db.Model(&filterTestItem{}).
+				Scopes(FilterScope(zoryapkg.FilterSpec{Conditions: tt.conditions})).
+				Order("id").
+				Pluck("id", &ids).Error
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestSortScope(t *testing.T) {
+	db := newFilterTestDB(t)
+
+	var ids []uint
+	err := db.Model(&filterTestItem{}).
+		Scopes(SortScope(zoryapkg.FilterSpec{Sort: []zoryapkg.SortField{
+			{Column: "group"},
+			{Column: "score", Desc: true},
+		}})).
+		Pluck("id", &ids).Error
+	require.NoError(t, err)
+	assert.Equal(t, []uint{6, 4, 2, 5, 3, 1}, ids)
+}
@@ -0,0 +1,147 @@
+package zorya
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKey is the GORM model of a stored idempotent request. Key is
+// the hex SHA-256 of the composite key built by zorya, which embeds the
+// client's Idempotency-Key header and can be longer than an indexable column.
+type IdempotencyKey struct {
+	Key         string `gorm:"column:idempotency_key;primaryKey;size:64"`
+	Fingerprint string `gorm:"not null"`
+	Completed   bool   `gorm:"not null;default:false"`
+	Status      int
+	Header      []byte
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+}
+
+// TableName returns the table storing idempotency keys.
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// IdempotencyStore is a zorya.IdempotencyStore backed by GORM. Reservations
+// rely on the primary key, so concurrent duplicates are detected across
+// instances sharing the database.
+type IdempotencyStore struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyStore creates a store using the idempotency_keys table.
+func NewIdempotencyStore(db *gorm.DB) *IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+// Reserve inserts an in-flight record for key unless an unexpired record exists.
+func (s *IdempotencyStore) Reserve(
+	ctx context.Context,
+	key, fingerprint string,
+	ttl time.Duration,
+) (*zoryapkg.IdempotencyRecord, bool, error) {
+	key = storeKey(key)
+	now := time.Now()
+	db := s.db.WithContext(ctx)
+
+	// An expired key can be reused.
+	if err := db.Where("idempotency_key = ? AND expires_at <= ?", key, now).Delete(&IdempotencyKey{}).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to delete expired idempotency key: %w", err)
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&IdempotencyKey{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(ttl),
+	})
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil, true, nil
+	}
+
+	var existing IdempotencyKey
+	if err := db.Where("idempotency_key = ?", key).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Released concurrently; let the client retry.
+			return &zoryapkg.IdempotencyRecord{Fingerprint: fingerprint}, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+
+	record := &zoryapkg.IdempotencyRecord{
+		Fingerprint: existing.Fingerprint,
+		Completed:   existing.Completed,
+		Status:      existing.Status,
+		Body:        existing.Body,
+	}
+	if len(existing.Header) > 0 {
+		if err := json.Unmarshal(existing.Header, &record.Header); err != nil {
+			return nil, false, fmt.Errorf("failed to decode idempotency key headers: %w", err)
+		}
+	}
+
+	return record, false, nil
+}
+
+// Complete stores the response of a reserved request.
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, record *zoryapkg.IdempotencyRecord) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency key headers: %w", err)
+	}
+
+	err = s.db.WithContext(ctx).Model(&IdempotencyKey{}).Where("idempotency_key = ?", storeKey(key)).Updates(map[string]any{
+		"completed": true,
+		"status":    record.Status,
+		"header":    header,
+		"body":      record.Body,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release deletes a reservation.
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := s.db.WithContext(ctx).Where("idempotency_key = ?", storeKey(key)).Delete(&IdempotencyKey{}).Error; err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes expired records and returns how many were deleted.
+// Run it periodically to keep the table small.
+func (s *IdempotencyStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&IdempotencyKey{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// storeKey returns the primary key storing an idempotency key.
+func storeKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+var _ zoryapkg.IdempotencyStore = (*IdempotencyStore)(nil)
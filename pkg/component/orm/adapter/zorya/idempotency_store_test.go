@@ -0,0 +1,130 @@
+package zorya
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an in-memory SQLite database with the given models migrated.
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Every connection to :memory: opens its own database.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(models...))
+
+	return db
+}
+
+func TestIdempotencyStore_Lifecycle(t *testing.T) {
+	store := NewIdempotencyStore(newTestDB(t, &IdempotencyKey{}))
+	ctx := t.Context()
+
+	record, reserved, err := store.Reserve(ctx, "key-1", "fp-1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Nil(t, record)
+
+	// In flight.
+	record, reserved, err = store.Reserve(ctx, "key-1", "fp-2", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, &zoryapkg.IdempotencyRecord{Fingerprint: "fp-1"}, record)
+
+	require.NoError(t, store.Complete(ctx, "key-1", &zoryapkg.IdempotencyRecord{
+		Fingerprint: "fp-1",
+		Completed:   true,
+		Status:      http.StatusCreated,
+		Header:      http.Header{"Content-Type": {"application/json"}},
+		Body:        []byte(`{"id":1}`),
+	}))
+
+	record, reserved, err = store.Reserve(ctx, "key-1", "fp-1", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, &zoryapkg.IdempotencyRecord{
+		Fingerprint: "fp-1",
+		Completed:   true,
+		Status:      http.StatusCreated,
+		Header:      http.Header{"Content-Type": {"application/json"}},
+		Body:        []byte(`{"id":1}`),
+	}, record)
+
+	// Other keys are independent.
+	_, reserved, err = store.Reserve(ctx, "key-2", "fp-1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
+
+func TestIdempotencyStore_Release(t *testing.T) {
+	store := NewIdempotencyStore(newTestDB(t, &IdempotencyKey{}))
+	ctx := t.Context()
+
+	_, reserved, err := store.Reserve(ctx, "key-1", "fp-1", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	require.NoError(t, store.Release(ctx, "key-1"))
+
+	_, reserved, err = store.Reserve(ctx, "key-1", "fp-2", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
+
+func TestIdempotencyStore_Expiry(t *testing.T) {
+	store := NewIdempotencyStore(newTestDB(t, &IdempotencyKey{}))
+	ctx := t.Context()
+
+	_, reserved, err := store.Reserve(ctx, "expired", "fp-1", -time.Second)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	_, reserved, err = store.Reserve(ctx, "kept", "fp-1", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	// Expired keys can be reused.
+	_, reserved, err = store.Reserve(ctx, "expired", "fp-2", -time.Second)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	deleted, err := store.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	_, reserved, err = store.Reserve(ctx, "kept", "fp-1", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+}
+
+func TestIdempotencyStore_LongKey(t *testing.T) {
+	db := newTestDB(t, &IdempotencyKey{})
+	store := NewIdempotencyStore(db)
+	ctx := t.Context()
+	key := strings.Repeat("k", 1000)
+
+	_, reserved, err := store.Reserve(ctx, key, "fp-1", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	var stored IdempotencyKey
+	require.NoError(t, db.First(&stored).Error)
+	assert.Len(t, stored.Key, 64)
+
+	_, reserved, err = store.Reserve(ctx, key, "fp-1", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+}
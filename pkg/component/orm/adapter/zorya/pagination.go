@@ -0,0 +1,101 @@
+package zorya
+
+import (
+	"github.com/talav/talav/pkg/component/orm"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+)
+
+// PageCursor is the position encoded in the cursors of keyset paginated pages,
+// with the order of the keyset it was created for. Keys go through JSON, so
+// numeric keys decode as float64; use string keys for integers beyond 2^53.
+type PageCursor struct {
+	// Column, Tiebreaker and Desc are the order of the keyset.
+	Column     string `json:"c"`
+	Tiebreaker string `json:"t,omitempty"`
+	Desc       bool   `json:"d,omitempty"`
+
+	// Key is the column value of the entity the page starts after or ends
+	// before, or its column and tiebreaker values for keysets with a tiebreaker.
+	Key any `json:"k"`
+
+	// Backward reports whether the cursor selects the entities before Key.
+	Backward bool `json:"b,omitempty"`
+}
+
+// KeysetFromInput returns the keyset of the page requested by input, ordered
+// by column. A non-empty tiebreaker orders entities with equal column values,
+// which is required unless column is unique. It returns a 400 StatusError if
+// the cursor is invalid or was created for another order.
+//
+//	keyset, err := zorya.KeysetFromInput(codec, &input.PageInput, "created_at", "id", false)
+//	if err != nil {
+//		return nil, err
+//	}
+//	page, err := repo.FindKeyset(ctx, keyset)
+func KeysetFromInput(codec *zoryapkg.CursorCodec, input *zoryapkg.PageInput, column, tiebreaker string, desc bool) (orm.Keyset, error) {
+	keyset := orm.Keyset{Column: column, Tiebreaker: tiebreaker, Desc: desc, Limit: input.PageLimit()}
+	if input.Cursor == "" {
+		return keyset, nil
+	}
+
+	var cursor PageCursor
+	if err := codec.Decode(input.Cursor, &cursor); err != nil {
+		return orm.Keyset{}, err
+	}
+
+	// Keys hold the column and tiebreaker values with a tiebreaker and the
+	// column value alone without one.
+	key, ok := cursor.Key.(orm.KeysetKey)
+	if ok != (tiebreaker != "") || (ok && len(key) != 2) ||
+		cursor.Column != column || cursor.Tiebreaker != tiebreaker || cursor.Desc != desc {
+		return orm.Keyset{}, zoryapkg.Error400BadRequest("invalid cursor", &zoryapkg.ErrorDetail{
+			Code:     "invalid_cursor",
+			Message:  "cursor does not match the page order",
+			Location: "query.cursor",
+		})
+	}
+
+	if cursor.Backward {
+		keyset.Before = cursor.Key
+	} else {
+		keyset.After = cursor.Key
+	}
+
+	return keyset, nil
+}
+
+// NewPage converts a page of keyset into a zorya Page of views, with cursors
+// pointing after its last entity and before its first one. key returns the
+// keyset column value of an entity, or an orm.KeysetKey of its column and
+// tiebreaker values if the keyset has a tiebreaker.
+func NewPage[T, V any](codec *zoryapkg.CursorCodec, keyset orm.Keyset, page *orm.KeysetPage[T], key func(*T) any, view func(*T) V) (*zoryapkg.Page[V], error) {
+	result := &zoryapkg.Page[V]{}
+	result.Body.Items = make([]V, len(page.Items))
+	for i, entity := range page.Items {
+		result.Body.Items[i] = view(entity)
+	}
+
+	if len(page.Items) == 0 {
+		return result, nil
+	}
+
+	cursor := PageCursor{Column: keyset.Column, Tiebreaker: keyset.Tiebreaker, Desc: keyset.Desc}
+
+	var err error
+	if page.HasNext {
+		next := cursor
+		next.Key = key(page.Items[len(page.Items)-1])
+		if result.Body.NextCursor, err = codec.Encode(next); err != nil {
+			return nil, err
+		}
+	}
+	if page.HasPrev {
+		prev := cursor
+		prev.Key, prev.Backward = key(page.Items[0]), true
+		if result.Body.PrevCursor, err = codec.Encode(prev); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package zorya
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/talav/talav/pkg/component/orm"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+)
+
+type pageTestItem struct {
+	ID    uint
+	Score int
+}
+
+func TestKeysetPagination_Tiebreaker(t *testing.T) {
+	db := newTestDB(t, &pageTestItem{})
+	require.NoError(t, db.Create([]*pageTestItem{
+		{ID: 1, Score: 10},
+		{ID: 2, Score: 20},
+		{ID: 3, Score: 20},
+		{ID: 4, Score: 20},
+		{ID: 5, Score: 30},
+	}).Error)
+	repo := orm.NewBaseRepository[pageTestItem](db)
+	codec := zoryapkg.NewCursorCodec([]byte("secret"))
+
+	list := func(cursor string) *zoryapkg.Page[uint] {
+		t.Helper()
+
+		input := &zoryapkg.PageInput{Cursor: cursor, Limit: 2}
+		keyset, err := KeysetFromInput(codec, input, "score", "id", false)
+		require.NoError(t, err)
+		page, err := repo.FindKeyset(t.Context(), keyset)
+		require.NoError(t, err)
+		result, err := NewPage(codec, keyset, page,
+			func(item *pageTestItem) any { return orm.KeysetKey{item.Score, item.ID} },
+			func(item *pageTestItem) uint { return item.ID })
+		require.NoError(t, err)
+
+		return result
+	}
+
+	first := list("")
+	assert.Equal(t, []uint{1, 2}, first.Body.Items)
+
+	second := list(first.Body.NextCursor)
+	assert.Equal(t, []uint{3, 4}, second.Body.Items)
+
+	last := list(second.Body.NextCursor)
+	assert.Equal(t, []uint{5}, last.Body.Items)
+	assert.Empty(t, last.Body.NextCursor)
+
+	assert.Equal(t, []uint{3, 4}, list(last.Body.PrevCursor).Body.Items)
+	assert.Equal(t, []uint{1, 2}, list(second.Body.PrevCursor).Body.Items)
+}
+
+func TestKeysetFromInput_CursorMismatch(t *testing.T) {
+	codec := zoryapkg.NewCursorCodec([]byte("secret"))
+	encode := func(cursor PageCursor) string {
+		t.Helper()
+
+		encoded, err := codec.Encode(cursor)
+		require.NoError(t, err)
+
+		return encoded
+	}
+	pair := encode(PageCursor{Column: "score", Tiebreaker: "id", Key: orm.KeysetKey{20, 3}})
+
+	tests := map[string]struct {
+		cursor     string
+		column     string
+		tiebreaker string
+		desc       bool
+	}{
+		"single key with tiebreaker": {
+			cursor: encode(PageCursor{Column: "score", Tiebreaker: "id", Key: 20}), column: "score", tiebreaker: "id",
+		},
+		"key pair without tiebreaker": {
+			cursor: encode(PageCursor{Column: "score", Key: orm.KeysetKey{20, 3}}), column: "score",
+		},
+		"other column":     {cursor: pair, column: "created_at", tiebreaker: "id"},
+		"other tiebreaker": {cursor: pair, column: "score", tiebreaker: "uuid"},
+		"other direction":  {cursor: pair, column: "score", tiebreaker: "id", desc: true},
+	}
+	for name, test := range tests {
+		input := &zoryapkg.PageInput{Cursor: test.cursor}
+		_, err := KeysetFromInput(codec, input, test.column, test.tiebreaker, test.desc)
+		var statusErr zoryapkg.StatusError
+		require.ErrorAs(t, err, &statusErr, name)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus(), name)
+	}
+
+	keyset, err := KeysetFromInput(codec, &zoryapkg.PageInput{Cursor: pair}, "score", "id", false)
+	require.NoError(t, err)
+	assert.Equal(t, orm.KeysetKey{float64(20), float64(3)}, keyset.After)
+}
@@ -6,12 +6,15 @@ require (
 	github.com/go-playground/validator/v10 v10.29.0
 	github.com/golang-migrate/migrate/v4 v4.19.0
 	github.com/spf13/cobra v1.10.1
+	github.com/talav/talav/pkg/component/zorya v0.0.0-20260113034123-9da34ad44376
 	gorm.io/driver/postgres v1.6.0
+	gorm.io/driver/sqlite v1.6.0
 	gorm.io/gorm v1.31.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/fxamacker/cbor/v2 v2.9.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.11 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
@@ -26,9 +29,15 @@ require (
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/lib/pq v1.10.9 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
+	github.com/talav/talav/pkg/component/mapstructure v0.0.0-20251212040909-717bc712a8cc // indirect
+	github.com/talav/talav/pkg/component/negotiation v0.0.0-20251213015208-199315015cbe // indirect
+	github.com/talav/talav/pkg/component/schema v0.0.0-20251213015208-199315015cbe // indirect
+	github.com/talav/talav/pkg/component/tagparser v0.0.0-20251210172924-f671c53a0295 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.62.0 // indirect
 	golang.org/x/crypto v0.45.0 // indirect
 	golang.org/x/sync v0.18.0 // indirect
@@ -2,8 +2,11 @@ package orm
 
 import (
 	"context"
+	"errors"
+	"slices"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BaseRepositoryInterface defines the common CRUD operations interface.
@@ -17,6 +20,7 @@ type BaseRepositoryInterface[T any] interface {
 	FindOneWithPreloads(ctx context.Context, field string, value any, preloads ...string) (*T, error)
 	Find(ctx context.Context, limit, offset int) ([]*T, error)
 	FindWithPreloads(ctx context.Context, limit, offset int, preloads ...string) ([]*T, error)
+	FindKeyset(ctx context.Context, keyset Keyset, scopes ...func(*gorm.DB) *gorm.DB) (*KeysetPage[T], error)
 	Create(ctx context.Context, entity *T) error
 	Update(ctx context.Context, entity *T) error
 	Delete(ctx context.Context, id string) error
@@ -97,6 +101,129 @@ func (r *BaseRepository[T]) FindWithPreloads(ctx context.Context, limit, offset
 	return entities, nil
 }
 
+// Keyset selects a page of entities ordered by a unique key, relative to the
+// key of a known entity. Unlike offsets, keysets stay stable while
+// entities are inserted or deleted and use the column index on deep pages.
+type Keyset struct {
+	// Column is the indexed column ordering the entities (e.g. "id"). It must
+	// be unique unless Tiebreaker is set.
+	Column string
+
+	// Tiebreaker is a unique column ordering the entities with equal Column
+	// values (e.g. "id"). If set, After and Before are KeysetKey values.
+	Tiebreaker string
+
+	// Desc orders the entities by descending column values.
+	Desc bool
+
+	// After selects the entities following this column value.
+	After any
+
+	// Before selects the entities preceding this column value. Ignored if After is set.
+	Before any
+
+	// Limit is the page size.
+	Limit int
+}
+
+// KeysetKey is the position of an entity in a keyset with a Tiebreaker: its
+// Column value followed by its Tiebreaker value.
+type KeysetKey = []any
+
+// KeysetPage is a page of entities returned by FindKeyset.
+type KeysetPage[T any] struct {
+	// Items are the entities of the page, in keyset order.
+	Items []*T
+
+	// HasNext reports whether entities follow the page.
+	HasNext bool
+
+	// HasPrev reports whether entities precede the page.
+	HasPrev bool
+}
+
+// FindKeyset retrieves a page of entities selected by keyset. Scopes can add
+// conditions and preloads to the query.
+func (r *BaseRepository[T]) FindKeyset(ctx context.Context, keyset Keyset, scopes ...func(*gorm.DB) *gorm.DB) (*KeysetPage[T], error) {
+	if keyset.Limit <= 0 {
+		return nil, errors.New("keyset limit must be positive")
+	}
+
+	backward := keyset.After == nil && keyset.Before != nil
+	query := r.db.WithContext(ctx).Scopes(scopes...)
+
+	switch {
+	case keyset.After != nil:
+		condition, err := keyset.condition(keyset.After, keyset.Desc)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition)
+	case backward:
+		condition, err := keyset.condition(keyset.Before, !keyset.Desc)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition)
+	}
+
+	// Fetch one extra entity to know whether more follow. Backward pages are
+	// read in reverse order from the keyset and flipped afterwards.
+	desc := keyset.Desc != backward
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: keyset.Column}, Desc: desc})
+	if keyset.Tiebreaker != "" {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: keyset.Tiebreaker}, Desc: desc})
+	}
+
+	var entities []*T
+	if err := query.Limit(keyset.Limit + 1).Find(&entities).Error; err != nil {
+		return nil, err
+	}
+
+	more := len(entities) > keyset.Limit
+	if more {
+		entities = entities[:keyset.Limit]
+	}
+
+	if backward {
+		slices.Reverse(entities)
+
+		return &KeysetPage[T]{Items: entities, HasNext: true, HasPrev: more}, nil
+	}
+
+	return &KeysetPage[T]{Items: entities, HasNext: more, HasPrev: keyset.After != nil}, nil
+}
+
+// condition returns the condition selecting the entities past the key.
+func (k Keyset) condition(key any, desc bool) (clause.Expression, error) {
+	column := clause.Column{Name: k.Column}
+	if k.Tiebreaker == "" {
+		return keysetCondition(column, key, desc), nil
+	}
+
+	values, ok := key.(KeysetKey)
+	if !ok || len(values) != 2 {
+		return nil, errors.New("keyset key must hold the column and tiebreaker values")
+	}
+
+	return clause.Or(
+		keysetCondition(column, values[0], desc),
+		clause.And(
+			clause.Eq{Column: column, Value: values[0]},
+			keysetCondition(clause.Column{Name: k.Tiebreaker}, values[1], desc),
+		),
+	), nil
+}
+
+// keysetCondition returns the condition selecting the column values past value.
+func keysetCondition(column clause.Column, value any, desc bool) clause.Expression {
+	if desc {
+		return clause.Lt{Column: column, Value: value}
+	}
+
+	return clause.Gt{Column: column, Value: value}
+}
+
 // Exists checks if an entity exists with given conditions (optimized with LIMIT 1).
 func (r *BaseRepository[T]) Exists(ctx context.Context, conditions map[string]any) (bool, error) {
 	var exists bool
@@ -32,6 +32,16 @@ func TestCodec_DecodeRequest(t *testing.T) {
 		Body bodyStruct `body:"structured"`
 	}
 
+	type PageParams struct {
+		Cursor string `schema:"cursor,location=query"`
+		Limit  int    `schema:"limit,location=query"`
+	}
+
+	type embeddedStruct struct {
+		PageParams
+		Name string `schema:"name,location=query"`
+	}
+
 	tests := []struct {
 		name         string
 		method       string
@@ -67,6 +77,13 @@ func TestCodec_DecodeRequest(t *testing.T) {
 			result:      &mixedStruct{},
 			want:        &mixedStruct{Name: "John", Body: bodyStruct{Title: "Post", Content: "Content"}},
 		},
+		{
+			name:   "embedded struct parameters",
+			method: "GET",
+			url:    "/test?name=John&cursor=abc&limit=10",
+			result: &embeddedStruct{},
+			want:   &embeddedStruct{PageParams: PageParams{Cursor: "abc", Limit: 10}, Name: "John"},
+		},
 	}
 
 	codec := NewDefaultCodec()
@@ -1,49 +1,92 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
+	"reflect"
 )
 
+// BodyUnmarshaler decodes raw request body bytes into v.
+type BodyUnmarshaler func(data []byte, v any) error
+
+// DecoderOption configures the default decoder.
+type DecoderOption func(*defaultDecoder)
+
 // defaultDecoder handles decoding of parameter strings to maps.
 type defaultDecoder struct {
-	schemaTag string
-	bodyTag   string
-	metadata  *Metadata
+	schemaTag    string
+	bodyTag      string
+	metadata     *Metadata
+	unmarshalers map[string]BodyUnmarshaler
 }
 
 // newDefaultDecoder creates a new decoder.
-func NewDecoder(metadata *Metadata, schemaTag string, bodyTag string) Decoder {
-	return &defaultDecoder{
+// Structured bodies are decoded as JSON unless other unmarshalers are registered.
+func NewDecoder(metadata *Metadata, schemaTag string, bodyTag string, opts ...DecoderOption) Decoder {
+	d := &defaultDecoder{
 		metadata:  metadata,
 		schemaTag: schemaTag,
 		bodyTag:   bodyTag,
+		unmarshalers: map[string]BodyUnmarshaler{
+			"application/json": json.Unmarshal,
+			"json":             json.Unmarshal, // For +json suffix matching
+		},
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+func NewDefaultDecoder(opts ...DecoderOption) Decoder {
+	return NewDecoder(NewDefaultMetadata(), defaultSchemaTag, defaultBodyTag, opts...)
+}
+
+// WithBodyUnmarshaler registers an unmarshaler for structured bodies of the given content type.
+// A bare suffix such as "cbor" matches structured syntax suffixes like `application/problem+cbor`.
+func WithBodyUnmarshaler(contentType string, unmarshal BodyUnmarshaler) DecoderOption {
+	return func(d *defaultDecoder) {
+		d.unmarshalers[contentType] = unmarshal
 	}
 }
 
-func NewDefaultDecoder() Decoder {
-	return NewDecoder(NewDefaultMetadata(), defaultSchemaTag, defaultBodyTag)
+// WithBodyUnmarshalers replaces all structured body unmarshalers, including the JSON default.
+// Requests without a Content-Type are still decoded as JSON.
+func WithBodyUnmarshalers(unmarshalers map[string]BodyUnmarshaler) DecoderOption {
+	return func(d *defaultDecoder) {
+		d.unmarshalers = make(map[string]BodyUnmarshaler, len(unmarshalers))
+		maps.Copy(d.unmarshalers, unmarshalers)
+	}
 }
 
 // Decode decodes HTTP request parameters into a map.
 func (d *defaultDecoder) Decode(request *http.Request, routerParams map[string]string, metadata *StructMetadata) (map[string]any, error) {
-	queryResult, err := d.decodeQuery(request, metadata)
+	fields, err := d.paramFields(metadata)
 	if err != nil {
 		return nil, err
 	}
 
-	headerResult, err := d.decodeHeader(request, metadata)
+	queryResult, err := d.decodeQuery(request, fields)
 	if err != nil {
 		return nil, err
 	}
 
-	cookieResult, err := d.decodeCookie(request, metadata)
+	headerResult, err := d.decodeHeader(request, fields)
 	if err != nil {
 		return nil, err
 	}
 
-	pathResult, err := d.decodePath(routerParams, metadata)
+	cookieResult, err := d.decodeCookie(request, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	pathResult, err := d.decodePath(routerParams, fields)
 	if err != nil {
 		return nil, err
 	}
@@ -56,10 +99,36 @@ func (d *defaultDecoder) Decode(request *http.Request, routerParams map[string]s
 	return mergeMaps(queryResult, headerResult, cookieResult, pathResult, bodyResult), nil
 }
 
+// paramFields returns the fields of a struct, with the fields of embedded
+// structs in place of the embedded fields. The unmarshaler promotes the
+// parameters of embedded structs the same way.
+func (d *defaultDecoder) paramFields(metadata *StructMetadata) ([]FieldMetadata, error) {
+	fields := make([]FieldMetadata, 0, len(metadata.Fields))
+	for _, field := range metadata.Fields {
+		if !field.Embedded || field.Type.Kind() != reflect.Struct || field.HasTag(d.bodyTag) {
+			fields = append(fields, field)
+
+			continue
+		}
+
+		embedded, err := d.metadata.GetStructMetadata(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get struct metadata: %w", err)
+		}
+		embeddedFields, err := d.paramFields(embedded)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, embeddedFields...)
+	}
+
+	return fields, nil
+}
+
 // decodePath decodes path parameters from router params.
-func (d *defaultDecoder) decodePath(routerParams map[string]string, metadata *StructMetadata) (map[string]any, error) {
+func (d *defaultDecoder) decodePath(routerParams map[string]string, fields []FieldMetadata) (map[string]any, error) {
 	result := make(map[string]any)
-	for _, field := range filterByLocation(metadata.Fields, LocationPath) {
+	for _, field := range filterByLocation(fields, LocationPath) {
 		schemaMeta, ok := GetTagMetadata[*SchemaMetadata](&field, d.schemaTag)
 		if !ok {
 			continue
@@ -75,9 +144,9 @@ func (d *defaultDecoder) decodePath(routerParams map[string]string, metadata *St
 }
 
 // decodeCookie decodes cookie parameters from HTTP request.
-func (d *defaultDecoder) decodeCookie(request *http.Request, metadata *StructMetadata) (map[string]any, error) {
+func (d *defaultDecoder) decodeCookie(request *http.Request, fields []FieldMetadata) (map[string]any, error) {
 	result := make(map[string]any)
-	for _, field := range filterByLocation(metadata.Fields, LocationCookie) {
+	for _, field := range filterByLocation(fields, LocationCookie) {
 		schemaMeta, ok := GetTagMetadata[*SchemaMetadata](&field, d.schemaTag)
 		if !ok {
 			continue
@@ -96,14 +165,15 @@ func (d *defaultDecoder) decodeCookie(request *http.Request, metadata *StructMet
 		// Use ParamName (tag name) directly - mapstructure will map to struct fields
 		result[schemaMeta.ParamName] = v
 	}
+	d.wrapSingleValues(result, fields)
 
 	return result, nil
 }
 
 // decodeHeader decodes header parameters from HTTP request.
-func (d *defaultDecoder) decodeHeader(request *http.Request, metadata *StructMetadata) (map[string]any, error) {
+func (d *defaultDecoder) decodeHeader(request *http.Request, fields []FieldMetadata) (map[string]any, error) {
 	result := make(map[string]any)
-	for _, field := range filterByLocation(metadata.Fields, LocationHeader) {
+	for _, field := range filterByLocation(fields, LocationHeader) {
 		schemaMeta, ok := GetTagMetadata[*SchemaMetadata](&field, d.schemaTag)
 		if !ok {
 			continue
@@ -114,16 +184,17 @@ func (d *defaultDecoder) decodeHeader(request *http.Request, metadata *StructMet
 		}
 		result[schemaMeta.ParamName] = v
 	}
+	d.wrapSingleValues(result, fields)
 
 	return result, nil
 }
 
 // decodeQuery decodes query parameters from HTTP request.
-func (d *defaultDecoder) decodeQuery(request *http.Request, metadata *StructMetadata) (map[string]any, error) {
+func (d *defaultDecoder) decodeQuery(request *http.Request, fields []FieldMetadata) (map[string]any, error) {
 	result := make(map[string]any)
 
 	// Filter fields by location, we only want query fields
-	queryFields := filterByLocation(metadata.Fields, LocationQuery)
+	queryFields := filterByLocation(fields, LocationQuery)
 	if len(queryFields) == 0 {
 		return result, nil
 	}
@@ -156,10 +227,43 @@ func (d *defaultDecoder) decodeQuery(request *http.Request, metadata *StructMeta
 		// Merge decoded map into result
 		result = mergeMaps(result, decodedMap)
 	}
+	d.wrapSingleValues(result, queryFields)
 
 	return result, nil
 }
 
+// wrapSingleValues turns the single values of slice fields into one-element
+// slices, as for a query parameter sent once (?tags=go). Bodies are left
+// alone, so a single value is still rejected for a slice there.
+func (d *defaultDecoder) wrapSingleValues(result map[string]any, fields []FieldMetadata) {
+	for _, field := range fields {
+		schemaMeta, ok := GetTagMetadata[*SchemaMetadata](&field, d.schemaTag)
+		if !ok {
+			continue
+		}
+
+		typ := field.Type
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		// []byte is converted from strings as a whole
+		if typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 {
+			continue
+		}
+
+		value, ok := result[schemaMeta.ParamName]
+		if !ok || value == nil || value == "" {
+			continue
+		}
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			continue
+		default:
+			result[schemaMeta.ParamName] = []any{value}
+		}
+	}
+}
+
 // decodeValueByStyle dispatches to the appropriate style-specific decoder for single values.
 func (d *defaultDecoder) decodeValueByStyle(value string, style Style, explode bool) (any, error) {
 	switch style {
@@ -8,8 +8,19 @@ import (
 	"mime/multipart"
 	"net/http"
 	"reflect"
+	"strings"
 )
 
+// UnsupportedMediaTypeError is returned when the request body has a Content-Type
+// that the decoder cannot handle.
+type UnsupportedMediaTypeError struct {
+	ContentType string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("unsupported media type %q", e.ContentType)
+}
+
 // decodeBody decodes the HTTP request body based on content type.
 func (d *defaultDecoder) decodeBody(request *http.Request, metadata *StructMetadata) (map[string]any, error) {
 	// Extract body field by iterating through fields
@@ -52,12 +63,42 @@ func (d *defaultDecoder) decodeBody(request *http.Request, metadata *StructMetad
 		return d.decodeFileBody(bodyBytes, bodyField)
 	}
 
+	if bodyContentType.isStructured() {
+		if unmarshal, ok := d.unmarshalerFor(bodyContentType.mediaType()); ok {
+			return d.decodeStructuredBody(bodyBytes, bodyField, unmarshal)
+		}
+	}
+
 	if bodyContentType.isXML() {
 		return d.decodeXMLBody(bodyBytes, bodyField)
 	}
 
-	// try JSON as a fallback
-	return d.decodeJSONBody(bodyBytes, bodyField)
+	return nil, &UnsupportedMediaTypeError{ContentType: bodyContentType.contentType}
+}
+
+// unmarshalerFor returns the body unmarshaler for a media type. The full media type
+// is tried first, then its structured syntax suffix (e.g. "json" for `application/problem+json`).
+// Requests without a Content-Type are decoded as JSON.
+func (d *defaultDecoder) unmarshalerFor(mediaType string) (BodyUnmarshaler, bool) {
+	if mediaType == "" {
+		if unmarshal, ok := d.unmarshalers["application/json"]; ok {
+			return unmarshal, true
+		}
+
+		return json.Unmarshal, true
+	}
+
+	if unmarshal, ok := d.unmarshalers[mediaType]; ok {
+		return unmarshal, true
+	}
+
+	if idx := strings.LastIndex(mediaType, "+"); idx != -1 {
+		if unmarshal, ok := d.unmarshalers[mediaType[idx+1:]]; ok {
+			return unmarshal, true
+		}
+	}
+
+	return nil, false
 }
 
 // decodeXMLBody decodes XML body content.
@@ -75,16 +116,16 @@ func (d *defaultDecoder) decodeXMLBody(bodyBytes []byte, bodyField *FieldMetadat
 	return map[string]any{bodyMeta.MapKey: parsed}, nil
 }
 
-// decodeJSONBody decodes JSON body content.
-func (d *defaultDecoder) decodeJSONBody(bodyBytes []byte, bodyField *FieldMetadata) (map[string]any, error) {
+// decodeStructuredBody decodes structured body content with a registered unmarshaler.
+func (d *defaultDecoder) decodeStructuredBody(bodyBytes []byte, bodyField *FieldMetadata, unmarshal BodyUnmarshaler) (map[string]any, error) {
 	bodyMeta, ok := GetTagMetadata[*BodyMetadata](bodyField, d.bodyTag)
 	if !ok {
 		return nil, fmt.Errorf("field is not a body field")
 	}
 
 	var parsed any
-	if err := json.Unmarshal(bodyBytes, &parsed); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	if err := unmarshal(bodyBytes, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal body: %w", err)
 	}
 
 	return map[string]any{bodyMeta.MapKey: parsed}, nil
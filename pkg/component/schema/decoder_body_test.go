@@ -2,12 +2,14 @@ package schema
 
 import (
 	"bytes"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -188,6 +190,99 @@ func TestDecoder_DecodeBody_JSON(t *testing.T) {
 	}
 }
 
+func TestDecoder_DecodeBody_ContentTypeDispatch(t *testing.T) {
+	// upperUnmarshaler stands in for a custom format such as CBOR.
+	upperUnmarshaler := func(data []byte, v any) error {
+		//nolint:forcetypeassert // Test code - safe to assert
+		*v.(*any) = map[string]any{"name": strings.ToUpper(string(data))}
+
+		return nil
+	}
+
+	decoder := NewDecoder(NewDefaultMetadata(), "schema", "body",
+		WithBodyUnmarshaler("application/upper", upperUnmarshaler),
+		WithBodyUnmarshaler("upper", upperUnmarshaler),
+	)
+	metadata := createBodyMetadata(BodyTypeStructured, reflect.TypeFor[testBodyStruct]())
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        map[string]any
+	}{
+		{
+			name:        "registered media type",
+			body:        "test",
+			contentType: "application/upper",
+			want:        map[string]any{"Body": map[string]any{"name": "TEST"}},
+		},
+		{
+			name:        "registered suffix",
+			body:        "test",
+			contentType: "application/vnd.example+upper; charset=utf-8",
+			want:        map[string]any{"Body": map[string]any{"name": "TEST"}},
+		},
+		{
+			name:        "json suffix",
+			body:        `{"name": "test"}`,
+			contentType: "application/merge-patch+json",
+			want:        map[string]any{"Body": map[string]any{"name": "test"}},
+		},
+		{
+			name:        "media type is case insensitive",
+			body:        `{"name": "test"}`,
+			contentType: "Application/JSON",
+			want:        map[string]any{"Body": map[string]any{"name": "test"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createBodyRequest(tt.contentType, []byte(tt.body))
+
+			result, err := decoder.Decode(req, nil, metadata)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestDecoder_DecodeBody_UnsupportedMediaType(t *testing.T) {
+	decoder := newTestDecoder()
+	metadata := createBodyMetadata(BodyTypeStructured, reflect.TypeFor[testBodyStruct]())
+
+	req := createBodyRequest("text/plain", []byte(`{"name": "test"}`))
+
+	_, err := decoder.Decode(req, nil, metadata)
+
+	var unsupported *UnsupportedMediaTypeError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "text/plain", unsupported.ContentType)
+}
+
+func TestDecoder_DecodeBody_ReplacedUnmarshalers(t *testing.T) {
+	decoder := NewDecoder(NewDefaultMetadata(), "schema", "body",
+		WithBodyUnmarshalers(map[string]BodyUnmarshaler{"application/xml": xml.Unmarshal}),
+	)
+	metadata := createBodyMetadata(BodyTypeStructured, reflect.TypeFor[testBodyStruct]())
+
+	// JSON is no longer registered for explicit content types.
+	req := createBodyRequest("application/json", []byte(`{"name": "test"}`))
+	_, err := decoder.Decode(req, nil, metadata)
+
+	var unsupported *UnsupportedMediaTypeError
+	require.ErrorAs(t, err, &unsupported)
+
+	// Requests without a Content-Type are still decoded as JSON.
+	req = createBodyRequest("", []byte(`{"name": "test"}`))
+	result, err := decoder.Decode(req, nil, metadata)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Body": map[string]any{"name": "test"}}, result)
+}
+
 func TestDecoder_DecodeBody_File(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -167,6 +167,28 @@ func TestDecoder_DecodeQuery(t *testing.T) {
 				"ids": []any{"1", "2", "3"},
 			},
 		},
+		{
+			name:        "array parameter with single value",
+			queryString: "ids=1",
+			structVal: struct {
+				IDs []string `schema:"ids,location=query,style=form,explode=true"`
+			}{},
+			want: map[string]any{
+				"ids": []any{"1"},
+			},
+		},
+		{
+			name:        "pointer to array parameter with single value",
+			queryString: "tags=go&name=john",
+			structVal: struct {
+				Tags *[]string `schema:"tags,location=query,style=form,explode=false"`
+				Name string    `schema:"name,location=query,style=form,explode=true"`
+			}{},
+			want: map[string]any{
+				"tags": []any{"go"},
+				"name": "john",
+			},
+		},
 		{
 			name:        "deep object style",
 			queryString: "filter%5Btype%5D=car&filter%5Bcolor%5D=red",
@@ -243,7 +265,7 @@ func TestDecoder_DecodeQuery(t *testing.T) {
 
 			req := createQueryRequest(tt.queryString)
 
-			result, err := decoder.decodeQuery(req, structMeta)
+			result, err := decoder.decodeQuery(req, structMeta.Fields)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -334,7 +356,7 @@ func TestDecoder_DecodePath(t *testing.T) {
 			structMeta, err := metadata.GetStructMetadata(tt.structType)
 			require.NoError(t, err)
 
-			result, err := decoder.decodePath(tt.routerParams, structMeta)
+			result, err := decoder.decodePath(tt.routerParams, structMeta.Fields)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -383,6 +405,18 @@ func TestDecoder_DecodeHeader(t *testing.T) {
 				"X-Client-Version": "2.0",
 			},
 		},
+		{
+			name: "array header with single value",
+			headers: map[string]string{
+				"X-Tags": "go",
+			},
+			structType: reflect.TypeOf(struct {
+				Tags []string `schema:"X-Tags,location=header"`
+			}{}),
+			want: map[string]any{
+				"X-Tags": []any{"go"},
+			},
+		},
 		{
 			name:    "missing header",
 			headers: map[string]string{},
@@ -418,7 +452,7 @@ func TestDecoder_DecodeHeader(t *testing.T) {
 				req.Header.Set(key, value)
 			}
 
-			result, err := decoder.decodeHeader(req, structMeta)
+			result, err := decoder.decodeHeader(req, structMeta.Fields)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -477,7 +511,7 @@ func TestDecoder_DecodeCookie(t *testing.T) {
 				req.AddCookie(&http.Cookie{Name: name, Value: value})
 			}
 
-			result, err := decoder.decodeCookie(req, structMeta)
+			result, err := decoder.decodeCookie(req, structMeta.Fields)
 
 			if tt.wantErr {
 				require.Error(t, err)
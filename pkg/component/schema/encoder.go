@@ -0,0 +1,644 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BodyMarshaler encodes a structured request body.
+type BodyMarshaler func(v any) ([]byte, error)
+
+// Encoder builds HTTP requests from Go structures. It is the inverse of
+// Decoder: a request built by the encoder decodes back into an equal struct.
+type Encoder interface {
+	// EncodeRequest builds a request for the URL, replacing its `{name}` path
+	// placeholders with the path parameters of v. v must be a struct or a
+	// pointer to a struct.
+	EncodeRequest(ctx context.Context, method, rawURL string, v any) (*http.Request, error)
+}
+
+// EncoderOption configures the default encoder.
+type EncoderOption func(*defaultEncoder)
+
+// defaultEncoder handles encoding of structs to HTTP requests.
+type defaultEncoder struct {
+	schemaTag   string
+	bodyTag     string
+	metadata    *Metadata
+	contentType string
+	marshal     BodyMarshaler
+}
+
+// NewEncoder creates a new encoder.
+// Structured bodies are encoded as JSON unless another marshaler is set.
+func NewEncoder(metadata *Metadata, schemaTag string, bodyTag string, opts ...EncoderOption) Encoder {
+	e := &defaultEncoder{
+		metadata:    metadata,
+		schemaTag:   schemaTag,
+		bodyTag:     bodyTag,
+		contentType: "application/json",
+		marshal:     json.Marshal,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// NewDefaultEncoder creates an encoder using the default "schema" and "body" tags.
+func NewDefaultEncoder(opts ...EncoderOption) Encoder {
+	return NewEncoder(NewDefaultMetadata(), defaultSchemaTag, defaultBodyTag, opts...)
+}
+
+// WithBodyMarshaler sets the content type and marshaler of structured bodies.
+func WithBodyMarshaler(contentType string, marshal BodyMarshaler) EncoderOption {
+	return func(e *defaultEncoder) {
+		e.contentType = contentType
+		e.marshal = marshal
+	}
+}
+
+// EncodeRequest encodes v into a request.
+//
+// Parameters are serialized with the style and explode settings of their
+// `schema` tag, the way the decoder parses them. Zero-valued parameters and
+// body fields are omitted so that defaults apply on the server; use pointers
+// to send zero values. Structured bodies are keyed by `schema` tag names,
+// file bodies accept []byte and io.Reader values, and multipart bodies are
+// written as form fields and, for []byte and io.Reader fields, files.
+func (e *defaultEncoder) EncodeRequest(ctx context.Context, method, rawURL string, v any) (*http.Request, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot encode %T: expected a struct", v)
+	}
+
+	parts := &requestParts{
+		path:   make(map[string]string),
+		query:  url.Values{},
+		header: http.Header{},
+	}
+	if err := e.encodeParams(rv, parts); err != nil {
+		return nil, err
+	}
+
+	target, err := expandPath(rawURL, parts.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + parts.query.Encode()
+	}
+
+	body, contentType, err := e.encodeBody(rv)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	maps.Copy(request.Header, parts.header)
+	for _, cookie := range parts.cookies {
+		request.AddCookie(cookie)
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	return request, nil
+}
+
+// requestParts collects the encoded parameters of a request.
+type requestParts struct {
+	path    map[string]string
+	query   url.Values
+	header  http.Header
+	cookies []*http.Cookie
+}
+
+// encodeParams encodes the parameter fields of a struct, including the
+// fields of embedded structs.
+func (e *defaultEncoder) encodeParams(rv reflect.Value, parts *requestParts) error {
+	metadata, err := e.metadata.GetStructMetadata(rv.Type())
+	if err != nil {
+		return fmt.Errorf("failed to get struct metadata: %w", err)
+	}
+
+	for i := range metadata.Fields {
+		field := &metadata.Fields[i]
+		if field.HasTag(e.bodyTag) {
+			continue
+		}
+
+		fv := rv.Field(field.Index)
+		if field.Embedded {
+			if fv = reflect.Indirect(fv); fv.Kind() == reflect.Struct {
+				if err := e.encodeParams(fv, parts); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		schemaMeta, ok := GetTagMetadata[*SchemaMetadata](field, e.schemaTag)
+		if !ok {
+			continue
+		}
+
+		value := paramTree(fv)
+		if value == nil {
+			if schemaMeta.Location == LocationPath {
+				return fmt.Errorf("missing path parameter %q", schemaMeta.ParamName)
+			}
+
+			continue
+		}
+
+		if err := encodeParam(parts, schemaMeta, value); err != nil {
+			return fmt.Errorf("field %s: %w", field.StructFieldName, err)
+		}
+	}
+
+	return nil
+}
+
+// encodeParam serializes a parameter value into its location.
+func encodeParam(parts *requestParts, meta *SchemaMetadata, value any) error {
+	switch meta.Location {
+	case LocationPath:
+		parts.path[meta.ParamName] = encodePathValue(meta.ParamName, value, meta.Style, meta.Explode)
+	case LocationHeader:
+		parts.header.Set(meta.ParamName, encodeSimpleValue(value, meta.Explode))
+	case LocationCookie:
+		parts.cookies = append(parts.cookies, &http.Cookie{Name: meta.ParamName, Value: encodeSimpleValue(value, false)})
+	case LocationQuery:
+		return encodeQueryValue(parts.query, meta.ParamName, value, meta.Style, meta.Explode)
+	}
+
+	return nil
+}
+
+// encodePathValue serializes a path parameter with the simple, label or matrix style.
+func encodePathValue(name string, value any, style Style, explode bool) string {
+	escape := func(values []string) []string {
+		escaped := make([]string, len(values))
+		for i, v := range values {
+			escaped[i] = url.PathEscape(v)
+		}
+
+		return escaped
+	}
+
+	//nolint:exhaustive // Query styles are not valid for path parameters
+	switch style {
+	case StyleLabel:
+		sep := ","
+		if explode {
+			sep = "."
+		}
+
+		return "." + strings.Join(escape(flattenValue(value)), sep)
+	case StyleMatrix:
+		values := escape(flattenValue(value))
+		if list, ok := value.([]any); ok && explode {
+			pairs := make([]string, len(list))
+			for i, v := range values {
+				pairs[i] = ";" + name + "=" + v
+			}
+
+			return strings.Join(pairs, "")
+		}
+
+		return ";" + name + "=" + strings.Join(values, ",")
+	default:
+		return strings.Join(escape(flattenValue(value)), ",")
+	}
+}
+
+// encodeSimpleValue serializes a header or cookie value as a comma-separated list.
+func encodeSimpleValue(value any, explode bool) string {
+	if object, ok := value.(map[string]any); ok && explode {
+		pairs := make([]string, 0, len(object))
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			pairs = append(pairs, key+"="+strings.Join(flattenValue(object[key]), ","))
+		}
+
+		return strings.Join(pairs, ",")
+	}
+
+	return strings.Join(flattenValue(value), ",")
+}
+
+// encodeQueryValue adds a query parameter serialized with its style.
+func encodeQueryValue(query url.Values, name string, value any, style Style, explode bool) error {
+	//nolint:exhaustive // Path styles are not valid for query parameters
+	switch style {
+	case StyleSpaceDelimited, StylePipeDelimited:
+		if _, ok := value.(map[string]any); ok {
+			return fmt.Errorf("style %q does not support objects", style)
+		}
+		sep := " "
+		if style == StylePipeDelimited {
+			sep = "|"
+		}
+		query.Set(name, strings.Join(flattenValue(value), sep))
+	case StyleDeepObject:
+		addDeepObject(query, name, value)
+	default:
+		addFormValue(query, name, value, explode)
+	}
+
+	return nil
+}
+
+// addFormValue adds a form-style value. Objects use dotted keys (filter.type),
+// which the decoder expands into nested maps.
+func addFormValue(query url.Values, key string, value any, explode bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			addFormValue(query, key+"."+k, v[k], explode)
+		}
+	case []any:
+		values := flattenValue(v)
+		if explode {
+			query[key] = append(query[key], values...)
+		} else {
+			query.Add(key, strings.Join(values, ","))
+		}
+	default:
+		query.Add(key, fmt.Sprint(v))
+	}
+}
+
+// addDeepObject adds a deepObject-style value with bracketed keys (filter[type]).
+func addDeepObject(query url.Values, key string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			addDeepObject(query, key+"["+k+"]", v[k])
+		}
+	case []any:
+		query[key] = append(query[key], flattenValue(v)...)
+	default:
+		query.Add(key, fmt.Sprint(v))
+	}
+}
+
+// flattenValue returns the scalars of a value: the value itself, the list
+// items, or the keys and values of an object in key order.
+func flattenValue(value any) []string {
+	switch v := value.(type) {
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, flattenValue(item)...)
+		}
+
+		return values
+	case map[string]any:
+		values := make([]string, 0, 2*len(v))
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			values = append(values, key)
+			values = append(values, flattenValue(v[key])...)
+		}
+
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// expandPath replaces the `{name}` placeholders of a URL with path values.
+func expandPath(rawURL string, values map[string]string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(rawURL, "{")
+		if start == -1 {
+			b.WriteString(rawURL)
+
+			return b.String(), nil
+		}
+		end := strings.Index(rawURL[start:], "}")
+		if end == -1 {
+			return "", fmt.Errorf("invalid path template %q: unclosed placeholder", rawURL)
+		}
+
+		name := rawURL[start+1 : start+end]
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("missing path parameter %q", name)
+		}
+		b.WriteString(rawURL[:start])
+		b.WriteString(value)
+		rawURL = rawURL[start+end+1:]
+	}
+}
+
+// encodeBody encodes the body field of a struct, returning a nil reader when
+// the struct has no body or the body is zero.
+func (e *defaultEncoder) encodeBody(rv reflect.Value) (io.Reader, string, error) {
+	metadata, err := e.metadata.GetStructMetadata(rv.Type())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get struct metadata: %w", err)
+	}
+
+	for i := range metadata.Fields {
+		field := &metadata.Fields[i]
+		bodyMeta, ok := GetTagMetadata[*BodyMetadata](field, e.bodyTag)
+		if !ok {
+			continue
+		}
+
+		fv := rv.Field(field.Index)
+		if fv.IsZero() {
+			return nil, "", nil
+		}
+
+		switch bodyMeta.BodyType {
+		case BodyTypeFile:
+			return encodeFileBody(fv)
+		case BodyTypeMultipart:
+			return e.encodeMultipartBody(fv)
+		default:
+			data, err := e.marshal(bodyTree(fv))
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to marshal body: %w", err)
+			}
+
+			return bytes.NewReader(data), e.contentType, nil
+		}
+	}
+
+	return nil, "", nil
+}
+
+// encodeFileBody returns a raw body from []byte or io.Reader values.
+func encodeFileBody(fv reflect.Value) (io.Reader, string, error) {
+	switch body := fv.Interface().(type) {
+	case []byte:
+		return bytes.NewReader(body), "application/octet-stream", nil
+	case io.Reader:
+		return body, "application/octet-stream", nil
+	default:
+		return nil, "", fmt.Errorf("cannot encode %s as a file body: expected []byte or io.Reader", fv.Type())
+	}
+}
+
+// encodeMultipartBody writes the fields of a struct as a multipart form.
+func (e *defaultEncoder) encodeMultipartBody(fv reflect.Value) (io.Reader, string, error) {
+	fv = reflect.Indirect(fv)
+	if fv.Kind() != reflect.Struct {
+		return nil, "", fmt.Errorf("cannot encode %s as a multipart body: expected a struct", fv.Type())
+	}
+	metadata, err := e.metadata.GetStructMetadata(fv.Type())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get struct metadata: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for i := range metadata.Fields {
+		field := &metadata.Fields[i]
+		schemaMeta, ok := GetTagMetadata[*SchemaMetadata](field, e.schemaTag)
+		if !ok {
+			continue
+		}
+		if err := writeMultipartField(writer, schemaMeta.ParamName, fv.Field(field.Index)); err != nil {
+			return nil, "", fmt.Errorf("field %s: %w", field.StructFieldName, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to write multipart body: %w", err)
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// writeMultipartField writes a form field, or file parts for []byte and
+// io.Reader values.
+func writeMultipartField(writer *multipart.Writer, name string, fv reflect.Value) error {
+	if fv.IsZero() {
+		return nil
+	}
+
+	var files []any
+	switch body := fv.Interface().(type) {
+	case []byte, io.Reader:
+		files = []any{body}
+	default:
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Implements(readerType) {
+			for i := 0; i < fv.Len(); i++ {
+				files = append(files, fv.Index(i).Interface())
+			}
+		}
+	}
+
+	if files == nil {
+		for _, value := range flattenValue(paramTree(fv)) {
+			if err := writer.WriteField(name, value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, file := range files {
+		part, err := writer.CreateFormFile(name, name)
+		if err != nil {
+			return err
+		}
+		if data, ok := file.([]byte); ok {
+			_, err = part.Write(data)
+		} else {
+			_, err = io.Copy(part, file.(io.Reader))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var (
+	readerType        = reflect.TypeFor[io.Reader]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// paramTree converts a parameter value into strings, []any and
+// map[string]any values. Returns nil for zero values.
+func paramTree(fv reflect.Value) any {
+	return valueTree(fv, formatScalar)
+}
+
+// bodyTree converts a body value into scalars, []any and map[string]any
+// values keyed by `schema` tag names, as the unmarshaler expects them.
+func bodyTree(fv reflect.Value) any {
+	return valueTree(fv, func(v reflect.Value) any {
+		if v.Type().Implements(textMarshalerType) {
+			return formatScalar(v)
+		}
+
+		return v.Interface()
+	})
+}
+
+// valueTree converts a value into a tree of scalars, lists and objects.
+// Struct keys follow the unmarshaler: the `schema` tag name, or the field
+// name. Zero values are omitted and yield nil, but zero values behind
+// pointers and in lists and maps are kept.
+func valueTree(fv reflect.Value, scalar func(reflect.Value) any) any {
+	if !fv.IsValid() || fv.IsZero() {
+		return nil
+	}
+
+	return valueTreeOf(fv, scalar)
+}
+
+// valueTreeOf converts a value, zero or not, into a tree. Returns nil for
+// nil pointers and interfaces.
+func valueTreeOf(fv reflect.Value, scalar func(reflect.Value) any) any {
+	if fv.Type().Implements(textMarshalerType) {
+		if fv.Kind() == reflect.Pointer && fv.IsNil() {
+			return nil
+		}
+
+		return scalar(fv)
+	}
+
+	//nolint:exhaustive // All other kinds are scalars
+	switch fv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if fv.IsNil() {
+			return nil
+		}
+
+		return valueTreeOf(fv.Elem(), scalar)
+	case reflect.Slice, reflect.Array:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			return scalar(fv)
+		}
+		list := make([]any, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			if item := valueTreeOf(fv.Index(i), scalar); item != nil {
+				list = append(list, item)
+			}
+		}
+
+		return list
+	case reflect.Map:
+		object := make(map[string]any, fv.Len())
+		iter := fv.MapRange()
+		for iter.Next() {
+			if item := valueTreeOf(iter.Value(), scalar); item != nil {
+				object[fmt.Sprint(iter.Key().Interface())] = item
+			}
+		}
+
+		return object
+	case reflect.Struct:
+		object := make(map[string]any)
+		addStructFields(object, fv, scalar)
+
+		return object
+	default:
+		return scalar(fv)
+	}
+}
+
+// addStructFields adds the non-zero fields of a struct to an object,
+// promoting the fields of embedded structs.
+func addStructFields(object map[string]any, fv reflect.Value, scalar func(reflect.Value) any) {
+	typ := fv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, skip := structFieldKey(field)
+		if skip {
+			continue
+		}
+
+		value := fv.Field(i)
+		if field.Anonymous {
+			if embedded := reflect.Indirect(value); embedded.Kind() == reflect.Struct {
+				addStructFields(object, embedded, scalar)
+
+				continue
+			}
+		}
+
+		if item := valueTree(value, scalar); item != nil {
+			object[key] = item
+		}
+	}
+}
+
+// structFieldKey returns the key of a struct field in a body object.
+func structFieldKey(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get(defaultSchemaTag)
+	name, _, _ := strings.Cut(tag, ",")
+	switch name {
+	case "-":
+		return "", true
+	case "":
+		return field.Name, false
+	default:
+		return name, false
+	}
+}
+
+// formatScalar formats a scalar value as a parameter string.
+func formatScalar(v reflect.Value) any {
+	if v.Type().Implements(textMarshalerType) {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	//nolint:exhaustive // Other kinds are formatted with fmt
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+	}
+
+	return fmt.Sprint(v.Interface())
+}
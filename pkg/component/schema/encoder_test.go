@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encoderFilter struct {
+	Type  string `schema:"type"`
+	Color string `schema:"color"`
+}
+
+type encoderBody struct {
+	Title string   `schema:"title"`
+	Tags  []string `schema:"tags"`
+	Count int      `schema:"count"`
+}
+
+type encoderRequest struct {
+	ID      string        `schema:"id,location=path"`
+	Label   []string      `schema:"label,location=path,style=label"`
+	Search  string        `schema:"q"`
+	IDs     []string      `schema:"ids,explode=false"`
+	Colors  []string      `schema:"colors,style=pipeDelimited"`
+	Filter  encoderFilter `schema:"filter,style=deepObject"`
+	Page    *int          `schema:"page"`
+	Trace   string        `schema:"X-Trace,location=header"`
+	Session string        `schema:"session,location=cookie"`
+	Body    encoderBody   `body:"structured"`
+}
+
+func TestEncoder_EncodeRequest(t *testing.T) {
+	input := &encoderRequest{
+		ID:      "a b",
+		Label:   []string{"x", "y", "z"},
+		Search:  "hello world",
+		IDs:     []string{"1", "2"},
+		Colors:  []string{"red", "blue"},
+		Filter:  encoderFilter{Type: "car", Color: "red"},
+		Page:    Ptr(0),
+		Trace:   "abc",
+		Session: "s1",
+		Body:    encoderBody{Title: "Hello", Tags: []string{"a", "b"}},
+	}
+
+	request, err := NewDefaultEncoder().EncodeRequest(context.Background(), http.MethodPost, "https://example.com/items/{id}/{label}", input)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/items/a%20b/.x,y,z", request.URL.EscapedPath())
+	assert.Equal(t, "hello world", request.URL.Query().Get("q"))
+	assert.Equal(t, "1,2", request.URL.Query().Get("ids"))
+	assert.Equal(t, "red|blue", request.URL.Query().Get("colors"))
+	assert.Equal(t, "car", request.URL.Query().Get("filter[type]"))
+	// Pointers send zero values.
+	assert.Equal(t, "0", request.URL.Query().Get("page"))
+	assert.Equal(t, "abc", request.Header.Get("X-Trace"))
+	cookie, err := request.Cookie("session")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", cookie.Value)
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(request.Body)
+	require.NoError(t, err)
+	// Zero values are omitted.
+	assert.JSONEq(t, `{"title":"Hello","tags":["a","b"]}`, string(body))
+
+	// The request decodes back into the struct. The decoder does not support
+	// cookies yet.
+	input.Session = ""
+	request, err = NewDefaultEncoder().EncodeRequest(context.Background(), http.MethodPost, "/items/{id}/{label}", input)
+	require.NoError(t, err)
+	var decoded encoderRequest
+	err = NewDefaultCodec().DecodeRequest(request, map[string]string{"id": "a b", "label": ".x,y,z"}, &decoded)
+	require.NoError(t, err)
+	assert.Equal(t, *input, decoded)
+}
+
+func TestEncoder_FormObjectsAndExplode(t *testing.T) {
+	type request struct {
+		IDs    []int         `schema:"ids"`
+		Filter encoderFilter `schema:"filter"`
+	}
+
+	encoded, err := NewDefaultEncoder().EncodeRequest(context.Background(), http.MethodGet, "/items",
+		request{IDs: []int{1, 2}, Filter: encoderFilter{Type: "car"}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "filter.type=car&ids=1&ids=2", encoded.URL.RawQuery)
+	assert.Nil(t, encoded.Body)
+
+	var decoded request
+	require.NoError(t, NewDefaultCodec().DecodeRequest(encoded, nil, &decoded))
+	assert.Equal(t, []int{1, 2}, decoded.IDs)
+	assert.Equal(t, "car", decoded.Filter.Type)
+}
+
+func TestEncoder_FileAndMultipartBodies(t *testing.T) {
+	type fileRequest struct {
+		Body []byte `body:"file"`
+	}
+	type uploadForm struct {
+		Name string    `schema:"name"`
+		File io.Reader `schema:"file"`
+	}
+	type multipartRequest struct {
+		Body uploadForm `body:"multipart"`
+	}
+
+	encoded, err := NewDefaultEncoder().EncodeRequest(context.Background(), http.MethodPut, "/files", fileRequest{Body: []byte("data")})
+	require.NoError(t, err)
+	assert.Equal(t, "application/octet-stream", encoded.Header.Get("Content-Type"))
+	body, err := io.ReadAll(encoded.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(body))
+
+	encoded, err = NewDefaultEncoder().EncodeRequest(context.Background(), http.MethodPost, "/uploads",
+		multipartRequest{Body: uploadForm{Name: "report", File: strings.NewReader("content")}},
+	)
+	require.NoError(t, err)
+	require.NoError(t, encoded.ParseMultipartForm(1<<20))
+	assert.Equal(t, "report", encoded.FormValue("name"))
+	file, _, err := encoded.FormFile("file")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	type pathRequest struct {
+		ID string `schema:"id,location=path"`
+	}
+
+	_, err := NewDefaultEncoder().EncodeRequest(context.Background(), http.MethodGet, "/items/{id}", pathRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing path parameter "id"`)
+
+	_, err = NewDefaultEncoder().EncodeRequest(context.Background(), http.MethodGet, "/items", "not a struct")
+	require.Error(t, err)
+}
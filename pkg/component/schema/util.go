@@ -159,6 +159,15 @@ func (b *bodyContentType) isFile() bool {
 	return b.bodyType == BodyTypeFile
 }
 
+func (b *bodyContentType) isStructured() bool {
+	return b.bodyType == BodyTypeStructured
+}
+
+// mediaType returns the lowercased media type without parameters.
+func (b *bodyContentType) mediaType() string {
+	return strings.ToLower(b.contentType)
+}
+
 func (b *bodyContentType) isXML() bool {
 	return b.bodyType == BodyTypeStructured &&
 		(strings.Contains(b.contentType, "application/xml") || strings.Contains(b.contentType, "text/xml"))
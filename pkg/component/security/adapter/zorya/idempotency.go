@@ -0,0 +1,35 @@
+package zorya
+
+import (
+	"net/http"
+
+	"github.com/talav/talav/pkg/component/security"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+)
+
+// IdempotencyByUser returns a Zorya idempotency scope function scoping keys
+// to the authenticated user (security.AuthUser ID). Anonymous requests are
+// scoped with the fallback scope function, or by their credentials if
+// fallback is nil.
+//
+// Idempotency-Key handling runs after API middlewares, so the authentication
+// middleware storing the AuthUser must be registered with api.UseMiddleware.
+//
+//	zorya.Post(api, "/payments", createPayment,
+//	    zorya.IdempotencyKey(store,
+//	        zorya.IdempotencyScope(securityzorya.IdempotencyByUser(nil)),
+//	    ),
+//	)
+func IdempotencyByUser(fallback zoryapkg.IdempotencyScopeFunc) zoryapkg.IdempotencyScopeFunc {
+	if fallback == nil {
+		fallback = zoryapkg.IdempotencyByCredentials()
+	}
+
+	return func(r *http.Request) string {
+		if user := security.GetAuthUser(r); user != nil {
+			return "user:" + user.ID
+		}
+
+		return fallback(r)
+	}
+}
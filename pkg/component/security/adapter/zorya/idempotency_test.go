@@ -0,0 +1,27 @@
+package zorya
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/talav/talav/pkg/component/security"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+)
+
+func TestIdempotencyByUser(t *testing.T) {
+	scope := IdempotencyByUser(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+	req = security.SetAuthUser(req, &security.AuthUser{ID: "user-1"})
+	assert.Equal(t, "user:user-1", scope(req))
+
+	// Anonymous requests fall back to their credentials.
+	anonymous := httptest.NewRequest(http.MethodPost, "/payments", nil)
+	anonymous.Header.Set("Cookie", "session=abc")
+	assert.Equal(t, zoryapkg.IdempotencyByCredentials()(anonymous), scope(anonymous))
+
+	scope = IdempotencyByUser(func(r *http.Request) string { return "anonymous" })
+	assert.Equal(t, "anonymous", scope(anonymous))
+}
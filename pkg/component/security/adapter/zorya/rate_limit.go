@@ -0,0 +1,34 @@
+package zorya
+
+import (
+	"net/http"
+
+	"github.com/talav/talav/pkg/component/security"
+	zoryapkg "github.com/talav/talav/pkg/component/zorya"
+)
+
+// RateLimitByUser returns a Zorya rate limit key function counting requests
+// per authenticated user (security.AuthUser ID). Anonymous requests are
+// counted with the fallback key function, or per client IP if fallback is nil.
+//
+// The rate limit runs after API middlewares, so the authentication middleware
+// storing the AuthUser must be registered with api.UseMiddleware.
+//
+//	zorya.Get(api, "/reports", listReports,
+//	    zorya.RateLimit(store, 100, time.Minute,
+//	        zorya.RateLimitKey(securityzorya.RateLimitByUser(nil)),
+//	    ),
+//	)
+func RateLimitByUser(fallback zoryapkg.RateLimitKeyFunc) zoryapkg.RateLimitKeyFunc {
+	if fallback == nil {
+		fallback = zoryapkg.RateLimitByIP()
+	}
+
+	return func(r *http.Request) string {
+		if user := security.GetAuthUser(r); user != nil {
+			return "user:" + user.ID
+		}
+
+		return fallback(r)
+	}
+}
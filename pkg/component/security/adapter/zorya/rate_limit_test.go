@@ -0,0 +1,27 @@
+package zorya
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/talav/talav/pkg/component/security"
+)
+
+func TestRateLimitByUser(t *testing.T) {
+	key := RateLimitByUser(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+	req = security.SetAuthUser(req, &security.AuthUser{ID: "user-1"})
+	assert.Equal(t, "user:user-1", key(req))
+
+	// Anonymous requests are counted per client IP by default.
+	anonymous := httptest.NewRequest(http.MethodGet, "/reports", nil)
+	anonymous.RemoteAddr = "192.0.2.1:1234"
+	assert.Equal(t, "ip:192.0.2.1", key(anonymous))
+
+	key = RateLimitByUser(func(r *http.Request) string { return "anonymous" })
+	assert.Equal(t, "anonymous", key(anonymous))
+	assert.Equal(t, "user:user-1", key(req))
+}
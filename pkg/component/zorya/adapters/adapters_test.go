@@ -0,0 +1,108 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/talav/talav/pkg/component/zorya"
+)
+
+type itemInput struct {
+	ID string `schema:"id,location=path,required=true"`
+}
+
+type itemOutput struct {
+	ETag string `schema:"ETag,location=header"`
+	Body struct {
+		ID string
+	} `body:"structured"`
+}
+
+type createItemInput struct {
+	Body struct {
+		Name string
+	} `body:"structured"`
+}
+
+func newAdapters() map[string]zorya.Adapter {
+	return map[string]zorya.Adapter{
+		"chi":    NewChi(chi.NewMux()),
+		"stdlib": NewStdlib(http.NewServeMux()),
+		"fiber":  NewFiber(fiber.New()),
+	}
+}
+
+func registerItems(api zorya.API) {
+	zorya.Get(api, "/items/{id}", func(ctx context.Context, input *itemInput) (*itemOutput, error) {
+		output := &itemOutput{ETag: `"v1"`}
+		output.Body.ID = input.ID
+
+		return output, nil
+	})
+	zorya.Post(api, "/items/{id}", func(ctx context.Context, input *createItemInput) (*itemOutput, error) {
+		return &itemOutput{}, nil
+	})
+}
+
+func serve(adapter zorya.Adapter, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(""))
+	recorder := httptest.NewRecorder()
+	adapter.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestAdapters_Get(t *testing.T) {
+	for name, adapter := range newAdapters() {
+		t.Run(name, func(t *testing.T) {
+			registerItems(zorya.NewAPI(adapter))
+
+			recorder := serve(adapter, http.MethodGet, "/items/42")
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			assert.Equal(t, `"v1"`, recorder.Header().Get("ETag"))
+			assert.JSONEq(t, `{"ID":"42"}`, recorder.Body.String())
+		})
+	}
+}
+
+func TestAdapters_Options(t *testing.T) {
+	for name, adapter := range newAdapters() {
+		t.Run(name, func(t *testing.T) {
+			registerItems(zorya.NewAPI(adapter))
+
+			recorder := serve(adapter, http.MethodOptions, "/items/42")
+
+			assert.Equal(t, http.StatusNoContent, recorder.Code)
+			assert.Equal(t, "GET, HEAD, OPTIONS, POST", recorder.Header().Get("Allow"))
+			assert.Equal(t, "application/cbor, application/json", recorder.Header().Get("Accept-Post"))
+		})
+	}
+}
+
+func TestAdapters_MethodNotAllowed(t *testing.T) {
+	for name, adapter := range newAdapters() {
+		t.Run(name, func(t *testing.T) {
+			registerItems(zorya.NewAPI(adapter))
+
+			recorder := serve(adapter, http.MethodDelete, "/items/42")
+
+			assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+			assert.Equal(t, "GET, HEAD, OPTIONS, POST", recorder.Header().Get("Allow"))
+			assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+			var problem zorya.ErrorModel
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+			assert.Equal(t, http.StatusMethodNotAllowed, problem.Status)
+		})
+	}
+}
@@ -83,6 +83,9 @@ func (a *FiberAdapter) Handle(route *zorya.BaseRoute, handler http.HandlerFunc)
 
 		// Call the standard http.HandlerFunc (with middleware already applied)
 		handler(w, req)
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
 
 		return nil
 	})
@@ -98,23 +101,42 @@ func (a *FiberAdapter) ExtractRouterParams(r *http.Request, route *zorya.BaseRou
 	return make(map[string]string)
 }
 
+// fiberResponseWriter adapts fiber.Ctx to http.ResponseWriter. Headers are
+// collected in an http.Header and copied to the fiber response when the
+// status is written, so handlers can set them through Header().
 type fiberResponseWriter struct {
-	ctx *fiber.Ctx
+	ctx         *fiber.Ctx
+	header      http.Header
+	wroteHeader bool
 }
 
 func (w *fiberResponseWriter) Header() http.Header {
-	h := make(http.Header)
-	w.ctx.Response().Header.VisitAll(func(key, value []byte) {
-		h.Add(string(key), string(value))
-	})
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
 
-	return h
+	return w.header
 }
 
 func (w *fiberResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
 	return w.ctx.Write(data)
 }
 
 func (w *fiberResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	for key, values := range w.header {
+		w.ctx.Response().Header.Del(key)
+		for _, value := range values {
+			w.ctx.Response().Header.Add(key, value)
+		}
+	}
 	w.ctx.Status(statusCode)
 }
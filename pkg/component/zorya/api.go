@@ -2,13 +2,14 @@ package zorya
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"maps"
 	"net/http"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/talav/talav/pkg/component/mapstructure"
@@ -51,19 +52,23 @@ type Validator interface {
 }
 
 // Transformer is a function that transforms response bodies before serialization.
-// Transformers are run in the order they are added.
+// Transformers are run in the order they are added: route transformers first,
+// then group transformers, then API transformers.
 // Parameters:
 //   - r: The HTTP request (for context-aware transformations)
 //   - status: The HTTP status code as an integer (e.g., 200, 404)
-//   - result: The response body value to transform (must be a struct from the output struct's Body field)
+//   - result: The response body value to transform (the value of the output struct's Body field)
 //
 // Returns the transformed value (which may be the same or a different type) and an error.
 // Each transformer receives the output of the previous transformer in the chain.
+// Transformers run after response validation, so the transformed value does not
+// have to match the documented schema. Errors implementing StatusError are
+// written as is, other errors as a 500 problem.
 // Note: Transformers are only called for struct body types. []byte and function bodies
 // bypass transformers and are handled separately.
 type Transformer func(r *http.Request, status int, result any) (any, error)
 
-//nolint:interfacebloat // API is the core framework interface; 14 methods is reasonable for a complete API contract
+//nolint:interfacebloat // API is the core framework interface; 16 methods is reasonable for a complete API contract
 type API interface {
 	// Adapter returns the router adapter for this API, providing a generic
 	// interface to get request information and write responses.
@@ -85,7 +90,8 @@ type API interface {
 	Metadata() *schema.Metadata
 
 	// Negotiate returns the best content type for the response based on the
-	// Accept header. If no match is found, returns the default format.
+	// Accept header. If no match is found, returns the default format, or a
+	// 406 StatusError if Config.NoFormatFallback is set.
 	Negotiate(accept string) (string, error)
 
 	// Marshal writes the value to the writer using the format for the given
@@ -111,6 +117,15 @@ type API interface {
 	// Registry returns the registry for this API.
 	Registry() Registry
 
+	// Config returns the configuration for this API.
+	Config() *Config
+
+	// Logger returns the logger used for diagnostics such as response validation.
+	Logger() *slog.Logger
+
+	// Localizer returns the localizer of error responses, or nil if localization is disabled.
+	Localizer() *Localizer
+
 	RequestSchemaExtractor() *requestSchemaExtractor
 	ResponseSchemaExtractor() *ResponseSchemaExtractor
 }
@@ -130,6 +145,8 @@ type api struct {
 	validator               Validator
 	transformers            []Transformer
 	config                  *Config
+	logger                  *slog.Logger
+	localizer               *Localizer
 	openAPI                 *OpenAPI
 	registry                Registry
 	requestSchemaExtractor  *requestSchemaExtractor
@@ -177,6 +194,18 @@ func (a *api) Registry() Registry {
 	return a.registry
 }
 
+func (a *api) Config() *Config {
+	return a.config
+}
+
+func (a *api) Logger() *slog.Logger {
+	return a.logger
+}
+
+func (a *api) Localizer() *Localizer {
+	return a.localizer
+}
+
 // Transform runs all transformers on the response value in the order they were added.
 func (a *api) Transform(r *http.Request, status int, v any) (any, error) {
 	for _, t := range a.transformers {
@@ -196,6 +225,8 @@ func (a *api) UseTransformer(transformers ...Transformer) {
 }
 
 // Negotiate returns the best content type based on the Accept header.
+// When Config.NoFormatFallback is set, an unmatched Accept header returns
+// a 406 Not Acceptable error listing the available media types.
 func (a *api) Negotiate(accept string) (string, error) {
 	if accept == "" {
 		return a.defaultFormat, nil
@@ -203,6 +234,12 @@ func (a *api) Negotiate(accept string) (string, error) {
 
 	header, err := a.negotiator.Negotiate(accept, a.formatKeys, false)
 	if errors.Is(err, negotiation.ErrNoMatch) {
+		if a.config.NoFormatFallback {
+			available := slices.Sorted(slices.Values(a.formatKeys))
+
+			return "", Error406NotAcceptable("available media types: " + strings.Join(available, ", "))
+		}
+
 		// Fallback to default format when no match
 		return a.defaultFormat, nil
 	}
@@ -250,12 +287,12 @@ func (a *api) Marshal(w io.Writer, ct string, v any) {
 //	api := zorya.NewAPI(adapter, zorya.WithFormatsReplace(formats)) // Replace all formats
 func NewAPI(adapter Adapter, opts ...Option) API {
 	a := &api{
-		adapter:       adapter,
 		middlewares:   Middlewares{},
 		defaultFormat: "application/json",
 		negotiator:    negotiation.NewMediaNegotiator(),
 		transformers:  []Transformer{},
 	}
+	a.adapter = newMethodRouter(adapter, a)
 
 	// Apply options
 	for _, opt := range opts {
@@ -267,13 +304,13 @@ func NewAPI(adapter Adapter, opts ...Option) API {
 	if a.metadata == nil {
 		a.metadata = NewMetadata()
 	}
-	if a.codec == nil {
-		a.codec = schema.NewCodec(a.metadata, mapstructure.NewDefaultUnmarshaler(), schema.NewDefaultDecoder())
-	}
-
 	if a.formats == nil {
 		a.formats = DefaultFormats()
 	}
+	if a.codec == nil {
+		decoder := schema.NewDefaultDecoder(schema.WithBodyUnmarshalers(bodyUnmarshalers(a.formats)))
+		a.codec = schema.NewCodec(a.metadata, mapstructure.NewDefaultUnmarshaler(), decoder)
+	}
 
 	initializeOpenAPI(a)
 
@@ -285,6 +322,9 @@ func NewAPI(adapter Adapter, opts ...Option) API {
 	if a.config == nil {
 		a.config = DefaultConfig()
 	}
+	if a.logger == nil {
+		a.logger = slog.Default()
+	}
 
 	// Build format keys from formats
 	if len(a.formatKeys) == 0 {
@@ -298,10 +338,14 @@ func NewAPI(adapter Adapter, opts ...Option) API {
 	}
 
 	a.requestSchemaExtractor = NewRequestSchemaExtractor(a.registry, a.metadata)
+	a.requestSchemaExtractor.contentTypes = decodableContentTypes(a.formats)
 	a.responseSchemaExtractor = NewResponseSchemaExtractor(a.registry, newSchemaBuilder(a.registry, a.metadata), a.metadata)
+	a.responseSchemaExtractor.contentTypes = slices.Sorted(slices.Values(a.formatKeys))
+	a.responseSchemaExtractor.strictNegotiation = a.config.NoFormatFallback
 
-	registerOpenAPIEndpoint(a)
-	registerDocsEndpoint(a)
+	registerOpenAPIEndpoint(a, a.adapter)
+	registerDocsEndpoint(a, a.adapter)
+	registerSchemasEndpoint(a, a.adapter)
 
 	return a
 }
@@ -322,32 +366,6 @@ func initializeOpenAPI(a *api) {
 	}
 }
 
-// registerOpenAPIEndpoint registers the OpenAPI spec endpoint if configured.
-func registerOpenAPIEndpoint(a *api) {
-	if a.config.OpenAPIPath == "" {
-		return
-	}
-
-	var specJSON []byte
-	a.adapter.Handle(&BaseRoute{
-		Method: http.MethodGet,
-		Path:   a.config.OpenAPIPath,
-	}, func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/vnd.oai.openapi+json")
-		if specJSON == nil {
-			var err error
-			specJSON, err = json.Marshal(a.openAPI)
-			if err != nil {
-				WriteErr(a, r, w, http.StatusInternalServerError, "failed to marshal OpenAPI spec", err)
-
-				return
-			}
-		}
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write(specJSON)
-	})
-}
-
 // WithValidator sets a validator for request validation.
 func WithValidator(validator Validator) Option {
 	return func(a *api) {
@@ -422,6 +440,13 @@ func WithConfig(config *Config) Option {
 	}
 }
 
+// WithLogger sets the logger for API diagnostics. Defaults to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(a *api) {
+		a.logger = logger
+	}
+}
+
 func WithOpenAPI(openAPI *OpenAPI) Option {
 	return func(a *api) {
 		a.openAPI = openAPI
@@ -445,32 +470,93 @@ func Register[I, O any](api API, route BaseRoute, handler func(context.Context,
 		return fmt.Errorf("output type %s must be a struct", outputType)
 	}
 
+	// Apply group-wide route options
+	if applier, ok := api.(routeOptionsApplier); ok {
+		applier.applyRouteOptions(&route)
+	}
+
 	// Initialize and register OpenAPI schemas
 	if err := registerOpenAPISchemas(api, &route, inputType, outputType); err != nil {
 		return err
 	}
 
 	// Create and register HTTP handler
-	httpHandler := createRequestHandler(api, &route, handler)
-
-	// Build middleware chain:
-	// 1. Router params extraction
-	// 2. Security metadata middleware (if Secure() was used)
-	// 3. API-level middlewares
-	// 4. Route-specific middlewares
-	allMiddlewares := Middlewares{newRouterParamsMiddleware(api.Adapter(), &route)}
-	if securityMiddleware := newSecurityMetadataMiddleware(route.Security); securityMiddleware != nil {
-		allMiddlewares = append(allMiddlewares, securityMiddleware)
+	var httpHandler http.Handler = http.HandlerFunc(createRequestHandler(api, &route, describedByLink(api, &route), handler))
+	if outputType == reflect.TypeFor[Accepted]() {
+		// Jobs started by the handler are scoped to the caller.
+		httpHandler = jobCallerMiddleware(httpHandler)
 	}
-	allMiddlewares = append(allMiddlewares, api.Middlewares()...)
-	allMiddlewares = append(allMiddlewares, route.Middlewares...)
-	finalHandler := allMiddlewares.Apply(http.HandlerFunc(httpHandler))
+	finalHandler := routeHandler(api, &route, httpHandler)
 
+	route.inputType = inputType
+	route.outputType = outputType
+	route.api = api
 	api.Adapter().Handle(&route, finalHandler.ServeHTTP)
 
 	return nil
 }
 
+// routeHandler wraps the handler of a route with its middleware chain:
+//  1. Router params extraction
+//  2. Locale resolution (if WithLocalizer() was used)
+//  3. Security metadata middleware (if Secure() was used)
+//  4. Deprecation headers (if the route is deprecated)
+//  5. API-level middlewares
+//  6. Route-specific middlewares
+//  7. Deprecated call logging (if the route is deprecated)
+//  8. 410 Gone after the sunset date (if GoneAfterSunset() was used)
+//  9. Rate limiting (if RateLimit() was used)
+//  10. Idempotency-Key handling (if IdempotencyKey() was used)
+//  11. Field selection (if SparseFields() was used)
+//  12. Handler timeout (if Timeout() was used)
+//
+// The sub-requests of generated PATCH operations skip steps 7 and 9 to 12,
+// which already ran for the PATCH request. PUT sub-requests also skip steps 5
+// and 6, while GET sub-requests run them, so the GET operation's
+// authorization applies to the resource read by the patch.
+func routeHandler(api API, route *BaseRoute, handler http.Handler) http.Handler {
+	allMiddlewares := Middlewares{newRouterParamsMiddleware(api.Adapter(), route)}
+	if localeMiddleware := newLocaleMiddleware(api); localeMiddleware != nil {
+		allMiddlewares = append(allMiddlewares, localeMiddleware)
+	}
+	if securityMiddleware := newSecurityMetadataMiddleware(route.Security); securityMiddleware != nil {
+		allMiddlewares = append(allMiddlewares, securityMiddleware)
+	}
+	if deprecationMiddleware := newDeprecationMiddleware(route); deprecationMiddleware != nil {
+		allMiddlewares = append(allMiddlewares, deprecationMiddleware)
+	}
+
+	userMiddlewares := append(Middlewares{}, api.Middlewares()...)
+	userMiddlewares = append(userMiddlewares, route.Middlewares...)
+	// GET sub-requests of generated PATCH operations are authorized like the
+	// GET requests they stand for. The PATCH request went through the
+	// middlewares of the PUT operation already.
+	allMiddlewares = append(allMiddlewares, skipForAutoPatchSubRequests(userMiddlewares, http.MethodPut))
+	if logMiddleware := newDeprecationLogMiddleware(api, route); logMiddleware != nil {
+		allMiddlewares = append(allMiddlewares, logMiddleware)
+	}
+	if goneMiddleware := newGoneAfterSunsetMiddleware(api, route); goneMiddleware != nil {
+		allMiddlewares = append(allMiddlewares, goneMiddleware)
+	}
+
+	var optionMiddlewares Middlewares
+	if rateLimitMiddleware := newRateLimitMiddleware(api, route); rateLimitMiddleware != nil {
+		optionMiddlewares = append(optionMiddlewares, rateLimitMiddleware)
+	}
+	if idempotencyMiddleware := newIdempotencyMiddleware(api, route); idempotencyMiddleware != nil {
+		optionMiddlewares = append(optionMiddlewares, idempotencyMiddleware)
+	}
+	if sparseFieldsMiddleware := newSparseFieldsMiddleware(api, route); sparseFieldsMiddleware != nil {
+		optionMiddlewares = append(optionMiddlewares, sparseFieldsMiddleware)
+	}
+	if timeoutMiddleware := newTimeoutMiddleware(api, route); timeoutMiddleware != nil {
+		optionMiddlewares = append(optionMiddlewares, timeoutMiddleware)
+	}
+	allMiddlewares = append(allMiddlewares, skipForAutoPatchSubRequests(optionMiddlewares, http.MethodGet, http.MethodPut))
+
+	return allMiddlewares.Apply(handler)
+}
+
 // registerOpenAPISchemas registers the OpenAPI schemas for input and output types.
 func registerOpenAPISchemas(api API, route *BaseRoute, inputType, outputType reflect.Type) error {
 	// Initialize operation if needed
@@ -492,11 +578,50 @@ func registerOpenAPISchemas(api API, route *BaseRoute, inputType, outputType ref
 	// Extract security requirements
 	api.RequestSchemaExtractor().ExtractSecurity(route, op)
 
+	// Document the default and maximum page sizes of paginated operations
+	documentPageLimits(route, inputType)
+
+	// Document the Idempotency-Key header and its error responses
+	documentIdempotency(route)
+
+	// Document the 429 response of rate limited routes
+	documentRateLimit(route)
+
+	// Document the 410 response of routes removed after their sunset date
+	documentDeprecation(route)
+
+	// Document the fields query parameter of routes with sparse fieldsets
+	documentSparseFields(route)
+
+	// Document the 503 response of routes with a handler timeout
+	documentTimeout(route)
+
+	// Document the 202 response of operations starting jobs
+	documentAccepted(route, outputType)
+
 	// Extract OpenAPI response schema (success + error responses)
 	if err := api.ResponseSchemaExtractor().ResponseFromType(outputType, route); err != nil {
 		return fmt.Errorf("failed to extract response schema: %w", err)
 	}
 
+	// Document conditional GET support (304, ETag, Last-Modified)
+	documentConditionalGET(route, outputType)
+
+	// Document the Link header of paginated operations
+	documentPagination(route, outputType)
+
+	// Link the 202 response of operations starting jobs to the status endpoint
+	documentAcceptedLinks(route, outputType)
+
+	// Document the RateLimit and Retry-After headers
+	documentRateLimitHeaders(route)
+
+	// Document the Deprecation, Sunset and Link headers
+	documentDeprecationHeaders(route)
+
+	// Document the Accept-Language and Content-Language headers
+	documentLocalization(api, route)
+
 	// Sync registry schemas to OpenAPI Components
 	maps.Copy(api.OpenAPI().Components.Schemas, api.Registry().Map())
 
@@ -518,7 +643,13 @@ func addOperationToPath(openAPI *OpenAPI, path, method string, op *Operation) er
 }
 
 // createRequestHandler creates the HTTP handler for processing requests.
-func createRequestHandler[I, O any](api API, route *BaseRoute, handler func(context.Context, *I) (*O, error)) func(http.ResponseWriter, *http.Request) {
+// describedBy is the `Link` header value pointing at the response body schema, if any.
+func createRequestHandler[I, O any](
+	api API,
+	route *BaseRoute,
+	describedBy string,
+	handler func(context.Context, *I) (*O, error),
+) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Router params are extracted by RouterParamsMiddleware and stored in context
 		routerParams := GetRouterParams(r)
@@ -542,9 +673,13 @@ func createRequestHandler[I, O any](api API, route *BaseRoute, handler func(cont
 			return
 		}
 
-		// Transform and write response
-		if err := transformAndWriteResponse(api, r, w, output); err != nil {
-			return // Error already written
+		if describedBy != "" {
+			w.Header().Add("Link", describedBy)
+		}
+
+		// Write response, running the transformers on its body
+		if err := writeResponse(api, r, w, route, output, http.StatusOK); err != nil {
+			WriteErr(api, r, w, http.StatusInternalServerError, "failed to write response", err)
 		}
 	}
 }
@@ -555,35 +690,23 @@ func decodeAndValidateRequest[I any](api API, r *http.Request, routerParams map[
 		return err
 	}
 
-	if errs := validateRequest(api, r, input); len(errs) > 0 {
-		return NewError(http.StatusUnprocessableEntity, "validation failed", errs...)
-	}
-
-	return nil
-}
-
-// transformAndWriteResponse transforms the output and writes the response.
-func transformAndWriteResponse[O any](api API, r *http.Request, w http.ResponseWriter, output *O) error {
-	statusCode := http.StatusOK
-	transformed, err := api.Transform(r, statusCode, output)
-	if err != nil {
-		WriteErr(api, r, w, http.StatusInternalServerError, "transformer error", err)
-
-		return err
+	// Inputs embedding PageInput check their limit parameter
+	if resolver, ok := any(input).(pageResolver); ok {
+		defaultLimit, maxLimit := pageLimits(input)
+		if err := resolver.resolvePage(r, defaultLimit, maxLimit); err != nil {
+			return err
+		}
 	}
 
-	transformedOutput, ok := transformed.(*O)
-	if !ok {
-		err := fmt.Errorf("transformer returned unexpected type")
-		WriteErr(api, r, w, http.StatusInternalServerError, "transformer error", err)
-
-		return err
+	// Inputs embedding FilterInput check their filter and sort parameters
+	if resolver, ok := any(input).(filterResolver); ok {
+		if err := resolver.resolveFilter(); err != nil {
+			return err
+		}
 	}
 
-	if err := writeResponse(api, r, w, transformedOutput, statusCode); err != nil {
-		WriteErr(api, r, w, http.StatusInternalServerError, "failed to write response", err)
-
-		return err
+	if errs := validateRequest(api, r, input); len(errs) > 0 {
+		return NewError(http.StatusUnprocessableEntity, "validation failed", errs...)
 	}
 
 	return nil
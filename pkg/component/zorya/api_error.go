@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/talav/talav/pkg/component/schema"
 )
 
 const (
@@ -178,14 +180,33 @@ func WriteErr(api API, r *http.Request, w http.ResponseWriter, status int, msg s
 	// Determine the error to write and its status
 	errToWrite, status := determineErrorToWrite(status, msg, errs)
 
+	// Translate the error into the request locale. The error varies with
+	// Accept-Language whenever a localizer is configured, including requests
+	// without the header, which get the default locale.
+	if api.Localizer() != nil {
+		addVary(w.Header(), "Accept-Language")
+	}
+	if locale := requestLocale(api, r); locale != nil {
+		errToWrite = localizeError(errToWrite, locale)
+		w.Header().Set("Content-Language", locale.Tag)
+	}
+
 	// Set headers if error implements HeadersError
 	applyErrorHeaders(w, errToWrite)
 
 	// Negotiate and set content type
-	ct := negotiateContentType(api, r, errToWrite)
+	ct, fallback := negotiateContentType(api, r, errToWrite)
 	w.Header().Set("Content-Type", ct)
 	w.WriteHeader(status)
 
+	// Errors that could not be negotiated are always encoded as JSON, even if
+	// the API has no JSON format registered, so a 406 is always renderable.
+	if fallback {
+		_ = JSONFormat().Marshal(w, errToWrite)
+
+		return
+	}
+
 	// Marshal and write error (fallback handled internally by Marshal)
 	api.Marshal(w, ct, errToWrite)
 }
@@ -209,11 +230,14 @@ func processExistingError(err error) (StatusError, int) {
 	status := http.StatusInternalServerError
 	var statusErr StatusError
 	var maxBytesErr *http.MaxBytesError
+	var mediaTypeErr *schema.UnsupportedMediaTypeError
 
 	if errors.As(err, &statusErr) {
 		status = statusErr.GetStatus()
 	} else if errors.As(err, &maxBytesErr) {
 		status = http.StatusRequestEntityTooLarge
+	} else if errors.As(err, &mediaTypeErr) {
+		status = http.StatusUnsupportedMediaType
 	}
 
 	// Convert to StatusError if needed
@@ -243,12 +267,15 @@ func applyErrorHeaders(w http.ResponseWriter, errToWrite StatusError) {
 }
 
 // negotiateContentType negotiates the content type for the error response.
-func negotiateContentType(api API, r *http.Request, errToWrite StatusError) string {
+// Errors do not honor Config.NoFormatFallback: if negotiation fails, JSON is
+// used and fallback is true.
+func negotiateContentType(api API, r *http.Request, errToWrite StatusError) (ct string, fallback bool) {
 	// Negotiate content type
 	ct, err := api.Negotiate(r.Header.Get("Accept"))
 	if err != nil || ct == "" {
 		// Fallback to JSON if negotiation fails or returns empty
 		ct = contentTypeJSON
+		fallback = true
 	}
 
 	// Check ContentTypeProvider
@@ -256,7 +283,7 @@ func negotiateContentType(api API, r *http.Request, errToWrite StatusError) stri
 		ct = ctp.ContentType(ct)
 	}
 
-	return ct
+	return ct, fallback
 }
 
 // ErrorWithHeaders wraps an error with additional headers to be sent to the
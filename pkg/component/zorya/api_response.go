@@ -1,6 +1,7 @@
 package zorya
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -16,7 +17,7 @@ type StatusProvider interface {
 }
 
 // writeResponse writes the HTTP response.
-func writeResponse[O any](api API, r *http.Request, w http.ResponseWriter, output *O, statusCode int) error {
+func writeResponse[O any](api API, r *http.Request, w http.ResponseWriter, route *BaseRoute, output *O, statusCode int) error {
 	vo := reflect.ValueOf(output).Elem()
 
 	// Get struct metadata for response handling
@@ -29,6 +30,9 @@ func writeResponse[O any](api API, r *http.Request, w http.ResponseWriter, outpu
 	// Extract and write headers
 	writeHeaders(w, structMeta, vo)
 
+	// Page outputs link to the adjacent pages
+	writePageLinks(r, w, output)
+
 	// Check if output type implements StatusProvider interface.
 	statusProviderType := reflect.TypeOf((*StatusProvider)(nil)).Elem()
 	if structMeta.Type.Implements(statusProviderType) {
@@ -37,6 +41,11 @@ func writeResponse[O any](api API, r *http.Request, w http.ResponseWriter, outpu
 		}
 	}
 
+	// Outputs that know their validators answer conditional requests before serialization.
+	if writeProviderValidators(r, w, output, statusCode) {
+		return nil
+	}
+
 	// Find body field by checking for "body" tag
 	bodyFieldMeta := FindBodyField(structMeta)
 	if bodyFieldMeta == nil {
@@ -47,7 +56,7 @@ func writeResponse[O any](api API, r *http.Request, w http.ResponseWriter, outpu
 	}
 
 	// Extract and write body.
-	writeBody(api, r, w, vo, bodyFieldMeta, statusCode)
+	writeBody(api, r, w, route, vo, bodyFieldMeta, statusCode)
 
 	return nil
 }
@@ -98,7 +107,7 @@ func writeHeaders(w http.ResponseWriter, structMeta *schema.StructMetadata, vo r
 }
 
 // writeBody handles body extraction and writing.
-func writeBody(api API, r *http.Request, w http.ResponseWriter, vo reflect.Value, bodyFieldMeta *schema.FieldMetadata, status int) {
+func writeBody(api API, r *http.Request, w http.ResponseWriter, route *BaseRoute, vo reflect.Value, bodyFieldMeta *schema.FieldMetadata, status int) {
 	bodyField := vo.Field(bodyFieldMeta.Index)
 	if !bodyField.IsValid() {
 		w.WriteHeader(status)
@@ -115,6 +124,13 @@ func writeBody(api API, r *http.Request, w http.ResponseWriter, vo reflect.Value
 
 	body := bodyField.Interface()
 
+	// Handle typed Server-Sent Events streams - no content negotiation.
+	if es, ok := body.(eventStreamBody); ok {
+		es.writeEventStream(w, r, status)
+
+		return
+	}
+
 	// Handle []byte (raw bytes) - no content negotiation.
 	if b, ok := body.([]byte); ok {
 		writeRawBody(w, status, b)
@@ -122,7 +138,7 @@ func writeBody(api API, r *http.Request, w http.ResponseWriter, vo reflect.Value
 		return
 	}
 
-	writeNegotiatedBody(api, r, w, status, body)
+	writeNegotiatedBody(api, r, w, route, status, body)
 }
 
 // writeBodyFunc executes a body callback function for streaming responses.
@@ -143,7 +159,9 @@ func writeRawBody(w http.ResponseWriter, status int, data []byte) {
 }
 
 // writeNegotiatedBody negotiates content type and marshals the body.
-func writeNegotiatedBody(api API, r *http.Request, w http.ResponseWriter, status int, body any) {
+// The body is validated against the route's response schema first if
+// Config.ResponseValidation is enabled, then transformed.
+func writeNegotiatedBody(api API, r *http.Request, w http.ResponseWriter, route *BaseRoute, status int, body any) {
 	var ct string
 	var err error
 	// Check if body implements ContentTypeProvider (e.g., ErrorModel).
@@ -152,12 +170,41 @@ func writeNegotiatedBody(api API, r *http.Request, w http.ResponseWriter, status
 	} else {
 		ct, err = api.Negotiate(r.Header.Get("Accept"))
 		if err != nil {
-			WriteErr(api, r, w, http.StatusNotAcceptable, "Not Acceptable", err)
+			// Strict negotiation already returns a 406 problem listing the available types.
+			var statusErr StatusError
+			if errors.As(err, &statusErr) {
+				WriteErr(api, r, w, 0, "", err)
+			} else {
+				WriteErr(api, r, w, http.StatusNotAcceptable, "Not Acceptable", err)
+			}
 
 			return
 		}
 	}
 
+	if !validateResponse(api, r, w, route, status, ct, body) {
+		return
+	}
+
+	body, err = transformBody(api, r, route, status, body)
+	if err != nil {
+		var statusErr StatusError
+		if errors.As(err, &statusErr) {
+			WriteErr(api, r, w, 0, "", err)
+		} else {
+			WriteErr(api, r, w, http.StatusInternalServerError, "transformer error", err)
+		}
+
+		return
+	}
+
+	// Hash the serialized body unless the output already provided an ETag.
+	if route != nil && route.ETag && isConditionalRead(r, status) && w.Header().Get("ETag") == "" {
+		writeBodyWithETag(api, r, w, status, ct, body)
+
+		return
+	}
+
 	w.Header().Set("Content-Type", ct)
 	w.WriteHeader(status)
 
@@ -165,6 +212,21 @@ func writeNegotiatedBody(api API, r *http.Request, w http.ResponseWriter, status
 	api.Marshal(w, ct, body)
 }
 
+// transformBody runs the route transformers on the body, then those of the
+// group and API.
+func transformBody(api API, r *http.Request, route *BaseRoute, status int, body any) (any, error) {
+	if route != nil {
+		for _, transformer := range route.Transformers {
+			var err error
+			if body, err = transformer(r, status, body); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return api.Transform(r, status, body)
+}
+
 // formatHeaderValue converts a reflect.Value to a string suitable for use as a header value.
 func formatHeaderValue(v reflect.Value) string {
 	if v.CanInterface() {
@@ -0,0 +1,135 @@
+package zorya
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// ResponseValidationMode selects how response schema violations are reported.
+type ResponseValidationMode int
+
+const (
+	// ResponseValidationOff disables response validation.
+	ResponseValidationOff ResponseValidationMode = iota
+
+	// ResponseValidationLog logs violations with the API logger and writes
+	// the response unchanged.
+	ResponseValidationLog
+
+	// ResponseValidationFail replaces an invalid response with a 500 problem
+	// listing the violations.
+	ResponseValidationFail
+
+	// ResponseValidationHeader writes the response unchanged and adds one
+	// HeaderResponseValidationError header per violation.
+	ResponseValidationHeader
+)
+
+// HeaderResponseValidationError is the header used by ResponseValidationHeader.
+const HeaderResponseValidationError = "X-Response-Validation-Error"
+
+// validateResponse validates a structured response body against the schema
+// documented for the route, status and content type. It returns false if the
+// violation has already been written as an error response.
+func validateResponse(api API, r *http.Request, w http.ResponseWriter, route *BaseRoute, status int, ct string, body any) bool {
+	mode := api.Config().ResponseValidation
+	if mode == ResponseValidationOff || route == nil {
+		return true
+	}
+
+	s := documentedResponseSchema(route.Operation, status, ct)
+	if s == nil {
+		return true
+	}
+
+	// Validate the serialized form, which is what clients see.
+	b, err := json.Marshal(body)
+	if err != nil {
+		return true
+	}
+	var value any
+	if err := json.Unmarshal(b, &value); err != nil {
+		return true
+	}
+
+	violations := newSchemaValidator(api.Registry()).Validate(s, "body", value)
+	if len(violations) == 0 {
+		return true
+	}
+
+	switch mode {
+	case ResponseValidationLog:
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			messages[i] = v.Error()
+		}
+		api.Logger().WarnContext(r.Context(), "response does not match schema",
+			"method", route.Method,
+			"path", route.Path,
+			"status", status,
+			"violations", messages,
+		)
+	case ResponseValidationFail:
+		errs := make([]error, len(violations))
+		for i, v := range violations {
+			errs[i] = v
+		}
+		WriteErr(api, r, w, http.StatusInternalServerError, "response does not match schema", errs...)
+
+		return false
+	case ResponseValidationHeader:
+		for _, v := range violations {
+			w.Header().Add(HeaderResponseValidationError, v.Error())
+		}
+	}
+
+	return true
+}
+
+// ValidateResponseBody validates a response body decoded from JSON against
+// the schema the operation documents for the status and content type, as
+// Config.ResponseValidation does for responses written by handlers. It
+// returns nil when the body is valid or no schema is documented.
+func ValidateResponseBody(api API, op *Operation, status int, ct string, body any) []*ErrorDetail {
+	s := documentedResponseSchema(op, status, ct)
+	if s == nil {
+		return nil
+	}
+
+	return newSchemaValidator(api.Registry()).Validate(s, "body", body)
+}
+
+// documentedResponse returns the response documented for the status, falling
+// back to the `default` response.
+func documentedResponse(op *Operation, status int) *Response {
+	if op == nil {
+		return nil
+	}
+	if resp := op.Responses[strconv.Itoa(status)]; resp != nil {
+		return resp
+	}
+
+	return op.Responses["default"]
+}
+
+// documentedResponseSchema returns the body schema documented for the status
+// and content type, falling back to the `default` response and to any media
+// type of the response.
+func documentedResponseSchema(op *Operation, status int, ct string) *Schema {
+	resp := documentedResponse(op, status)
+	if resp == nil || len(resp.Content) == 0 {
+		return nil
+	}
+
+	if mt := resp.Content[ct]; mt != nil {
+		return mt.Schema
+	}
+	for _, mt := range resp.Content {
+		if mt != nil && mt.Schema != nil {
+			return mt.Schema
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DriftingItemOutput struct {
+	Body struct {
+		Name   string   `validate:"required"`
+		Status string   `validate:"oneof=active inactive"`
+		Tags   []string `validate:"required"`
+	} `body:"structured"`
+}
+
+func newResponseValidationTestAPI(t *testing.T, mode ResponseValidationMode, status string, tags []string, opts ...Option) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	config := DefaultConfig()
+	config.ResponseValidation = mode
+	api := NewAPI(&testChiAdapter{router: router}, append([]Option{WithConfig(config)}, opts...)...)
+
+	Get(api, "/items/{id}", func(ctx context.Context, input *GetUserInput) (*DriftingItemOutput, error) {
+		output := &DriftingItemOutput{}
+		output.Body.Name = "Widget"
+		output.Body.Status = status
+		output.Body.Tags = tags
+
+		return output, nil
+	})
+
+	return router
+}
+
+func getItem(router http.Handler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestResponseValidation_ValidResponse(t *testing.T) {
+	for _, mode := range []ResponseValidationMode{ResponseValidationLog, ResponseValidationFail, ResponseValidationHeader} {
+		router := newResponseValidationTestAPI(t, mode, "active", []string{"new"})
+
+		recorder := getItem(router)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Empty(t, recorder.Header().Values(HeaderResponseValidationError))
+	}
+}
+
+func TestResponseValidation_Fail(t *testing.T) {
+	// A nil slice is rendered as null although the field is required.
+	router := newResponseValidationTestAPI(t, ResponseValidationFail, "archived", nil)
+
+	recorder := getItem(router)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+	var problem ErrorModel
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "response does not match schema", problem.Detail)
+	require.Len(t, problem.Errors, 2)
+	assert.Equal(t, "enum", problem.Errors[0].Code)
+	assert.Equal(t, "body.Status", problem.Errors[0].Location)
+	assert.Equal(t, "type", problem.Errors[1].Code)
+	assert.Equal(t, "body.Tags", problem.Errors[1].Location)
+}
+
+func TestResponseValidation_Header(t *testing.T) {
+	router := newResponseValidationTestAPI(t, ResponseValidationHeader, "archived", []string{"new"})
+
+	recorder := getItem(router)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t,
+		[]string{"value must be one of [active inactive] (body.Status)"},
+		recorder.Header().Values(HeaderResponseValidationError),
+	)
+	assert.JSONEq(t, `{"Name":"Widget","Status":"archived","Tags":["new"]}`, recorder.Body.String())
+}
+
+func TestResponseValidation_Log(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	router := newResponseValidationTestAPI(t, ResponseValidationLog, "archived", []string{"new"}, WithLogger(logger))
+
+	recorder := getItem(router)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Values(HeaderResponseValidationError))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "response does not match schema", entry["msg"])
+	assert.Equal(t, "/items/{id}", entry["path"])
+	assert.Equal(t, []any{"value must be one of [active inactive] (body.Status)"}, entry["violations"])
+}
+
+func TestResponseValidation_Off(t *testing.T) {
+	router := newResponseValidationTestAPI(t, ResponseValidationOff, "archived", nil)
+
+	recorder := getItem(router)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Values(HeaderResponseValidationError))
+}
+
+func TestSchemaValidator_Keywords(t *testing.T) {
+	registry := NewMapRegistry("#/components/schemas/", DefaultSchemaNamer, NewMetadata())
+	validator := newSchemaValidator(registry)
+
+	minimum := 1.0
+	minLength := 2
+	maxItems := 1
+	s := &Schema{
+		Type:     TypeObject,
+		Required: []string{"id"},
+		Properties: map[string]*Schema{
+			"id":    {Type: TypeInteger, Minimum: &minimum},
+			"code":  {Type: TypeString, MinLength: &minLength, Pattern: "^[A-Z]+$"},
+			"items": {Type: TypeArray, MaxItems: &maxItems, Items: &Schema{Type: TypeString}},
+		},
+		AdditionalProperties: false,
+	}
+
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(`{"id": 0.5, "code": "a", "items": ["x", 1], "extra": true}`), &value))
+
+	codes := map[string]string{}
+	for _, v := range validator.Validate(s, "body", value) {
+		codes[v.Location+":"+v.Code] = v.Message
+	}
+
+	assert.Equal(t, map[string]string{
+		"body.code:minLength":             "expected length >= 2",
+		"body.code:pattern":               "expected string to match pattern ^[A-Z]+$",
+		"body.extra:additionalProperties": "unexpected property extra",
+		"body.id:type":                    "expected integer, got number",
+		"body.items:maxItems":             "expected array length <= 1",
+		"body.items[1]:type":              "expected string, got number",
+	}, codes)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"code": "AB"}`), &value))
+	violations := validator.Validate(s, "body", value)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "required", violations[0].Code)
+	assert.Equal(t, "body.id", violations[0].Location)
+}
@@ -174,6 +174,11 @@ func TestOpenAPIEndpoint_EndToEnd(t *testing.T) {
         "responses": {
           "200": {
             "content": {
+              "application/cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/GetUserOutputBody"
+                }
+              },
               "application/json": {
                 "schema": {
                   "$ref": "#/components/schemas/GetUserOutputBody"
@@ -184,6 +189,11 @@ func TestOpenAPIEndpoint_EndToEnd(t *testing.T) {
           },
           "422": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -194,6 +204,11 @@ func TestOpenAPIEndpoint_EndToEnd(t *testing.T) {
           },
           "500": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -402,6 +417,11 @@ func TestOpenAPIEndpoint_FileUpload(t *testing.T) {
         "responses": {
           "200": {
             "content": {
+              "application/cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/UploadFileOutputBody"
+                }
+              },
               "application/json": {
                 "schema": {
                   "$ref": "#/components/schemas/UploadFileOutputBody"
@@ -412,6 +432,11 @@ func TestOpenAPIEndpoint_FileUpload(t *testing.T) {
           },
           "422": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -422,6 +447,11 @@ func TestOpenAPIEndpoint_FileUpload(t *testing.T) {
           },
           "500": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -587,6 +617,11 @@ func TestOpenAPIEndpoint_FileDownload(t *testing.T) {
           },
           "422": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -597,6 +632,11 @@ func TestOpenAPIEndpoint_FileDownload(t *testing.T) {
           },
           "500": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -768,6 +808,11 @@ func TestOpenAPIEndpoint_FileInResponse(t *testing.T) {
         "responses": {
           "200": {
             "content": {
+              "application/cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/GetFileWithMetadataOutputBody"
+                }
+              },
               "application/json": {
                 "schema": {
                   "$ref": "#/components/schemas/GetFileWithMetadataOutputBody"
@@ -778,6 +823,11 @@ func TestOpenAPIEndpoint_FileInResponse(t *testing.T) {
           },
           "422": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -788,6 +838,11 @@ func TestOpenAPIEndpoint_FileInResponse(t *testing.T) {
           },
           "500": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -1180,6 +1235,11 @@ func TestOpenAPIEndpoint_ComprehensiveValidations(t *testing.T) {
         ],
         "requestBody": {
           "content": {
+            "application/cbor": {
+              "schema": {
+                "$ref": "#/components/schemas/ComprehensiveValidationBody"
+              }
+            },
             "application/json": {
               "schema": {
                 "$ref": "#/components/schemas/ComprehensiveValidationBody"
@@ -1191,6 +1251,11 @@ func TestOpenAPIEndpoint_ComprehensiveValidations(t *testing.T) {
         "responses": {
           "200": {
             "content": {
+              "application/cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ComprehensiveValidationOutputBody"
+                }
+              },
               "application/json": {
                 "schema": {
                   "$ref": "#/components/schemas/ComprehensiveValidationOutputBody"
@@ -1201,6 +1266,11 @@ func TestOpenAPIEndpoint_ComprehensiveValidations(t *testing.T) {
           },
           "422": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -1211,6 +1281,11 @@ func TestOpenAPIEndpoint_ComprehensiveValidations(t *testing.T) {
           },
           "500": {
             "content": {
+              "application/problem+cbor": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorModel"
+                }
+              },
               "application/problem+json": {
                 "schema": {
                   "$ref": "#/components/schemas/ErrorModel"
@@ -1263,3 +1338,77 @@ func TestDocsEndpoint_EndToEnd(t *testing.T) {
 	assert.Contains(t, htmlBody, "/openapi.json", "Response should reference OpenAPI spec at /openapi.json")
 	assert.Contains(t, htmlBody, "@stoplight/elements", "Response should include Stoplight Elements from CDN")
 }
+
+func TestTransformers_BodyChain(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	var calls []string
+	record := func(name string) Transformer {
+		return func(r *http.Request, status int, result any) (any, error) {
+			calls = append(calls, name)
+
+			return result, nil
+		}
+	}
+
+	api.UseTransformer(record("api"))
+	group := NewGroup(api, "/v1")
+	group.UseTransformer(record("group"))
+
+	Get(group, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*GetUserOutput, error) {
+		output := &GetUserOutput{}
+		output.Body.ID = input.ID
+		output.Body.Name = "John"
+
+		return output, nil
+	}, func(route *BaseRoute) {
+		route.Transformers = append(route.Transformers, record("route"), func(r *http.Request, status int, result any) (any, error) {
+			// Transformers receive the body and may replace it with another type.
+			assert.Equal(t, http.StatusOK, status)
+			user := result.(struct {
+				ID   int    `json:"id"`
+				Name string `json:"name"`
+			})
+
+			return map[string]any{"name": user.Name}, nil
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"name": "John"}`, recorder.Body.String())
+	assert.Equal(t, []string{"route", "group", "api"}, calls)
+}
+
+func TestTransformers_Errors(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*GetUserOutput, error) {
+		return &GetUserOutput{}, nil
+	}, func(route *BaseRoute) {
+		route.Transformers = append(route.Transformers, func(r *http.Request, status int, result any) (any, error) {
+			if r.URL.Query().Get("fail") == "status" {
+				return nil, Error403Forbidden("hidden")
+			}
+
+			return nil, assert.AnError
+		})
+	})
+
+	tests := map[string]int{
+		"/users/1?fail=status": http.StatusForbidden,
+		"/users/1":             http.StatusInternalServerError,
+	}
+	for target, status := range tests {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, status, recorder.Code, target)
+	}
+}
@@ -0,0 +1,352 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Batch defaults.
+const (
+	// DefaultBatchPath is the default path of the batch operation.
+	DefaultBatchPath = "/batch"
+
+	// DefaultBatchMaxRequests is the default maximum number of sub-requests of a batch.
+	DefaultBatchMaxRequests = 20
+)
+
+const (
+	batchCallerContextKey contextKey = "batch_caller_context"
+	batchContextKey       contextKey = "batch_context"
+)
+
+// batchMethods are the methods sub-requests can use.
+var batchMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// batchCallerHeaders are the headers of the batch request that sub-requests
+// always keep, so every sub-request runs with the caller's credentials.
+var batchCallerHeaders = []string{"Authorization", "Cookie"}
+
+// batchDroppedHeaders are the headers of the batch request that describe the
+// batch itself and are not passed on to sub-requests.
+var batchDroppedHeaders = []string{
+	"Content-Type", "Content-Length", "Content-Encoding", "Accept-Encoding", HeaderIdempotencyKey,
+	"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since",
+}
+
+// BatchConfig configures the batch operation registered by Batch.
+type BatchConfig struct {
+	// Path is the path of the batch operation. Defaults to DefaultBatchPath.
+	Path string
+
+	// MaxRequests is the maximum number of sub-requests of a batch. Larger
+	// batches are rejected with a 422 problem. Defaults to DefaultBatchMaxRequests.
+	MaxRequests int
+
+	// Concurrency is the maximum number of sub-requests executed at once.
+	// Values below 2 execute the sub-requests in sequence, in their order.
+	Concurrency int
+}
+
+// BatchInput is the input of the batch operation.
+type BatchInput struct {
+	Body struct {
+		// Requests are the sub-requests to execute.
+		Requests []BatchRequest `json:"requests" schema:"requests"`
+	} `body:"structured"`
+}
+
+// BatchRequest is a sub-request of a batch.
+type BatchRequest struct {
+	// ID identifies the sub-request in the responses. Optional.
+	ID string `json:"id,omitempty" schema:"id" openapi:"description=Identifier echoed in the response."`
+
+	// Method is the HTTP method of the sub-request.
+	Method string `json:"method" schema:"method" validate:"required,oneof=GET HEAD POST PUT PATCH DELETE"`
+
+	// Path is the path of the sub-request, with its query string.
+	Path string `json:"path" schema:"path" validate:"required" openapi:"description=Absolute path with an optional query string."`
+
+	// Headers are the headers of the sub-request. They are added to the
+	// headers of the batch request, except for Authorization and Cookie.
+	Headers map[string]string `json:"headers,omitempty" schema:"headers"`
+
+	// Body is the JSON body of the sub-request.
+	Body any `json:"body,omitempty" schema:"body"`
+}
+
+// BatchOutput is the output of the batch operation.
+type BatchOutput struct {
+	Body struct {
+		// Responses are the responses of the sub-requests, in request order.
+		Responses []BatchResponse `json:"responses" schema:"responses"`
+	} `body:"structured"`
+}
+
+// BatchResponse is the response of a sub-request.
+type BatchResponse struct {
+	// ID is the identifier of the sub-request.
+	ID string `json:"id,omitempty" schema:"id"`
+
+	// Status is the HTTP status code of the sub-request.
+	Status int `json:"status" schema:"status"`
+
+	// Headers are the response headers. Multiple values are joined with commas.
+	Headers map[string]string `json:"headers,omitempty" schema:"headers"`
+
+	// Body is the response body: JSON bodies are embedded, others are strings.
+	Body any `json:"body,omitempty" schema:"body"`
+}
+
+// Batch registers a POST operation executing several requests in one round
+// trip, so clients loading many resources avoid a request per resource:
+//
+//	zorya.Batch(api, zorya.BatchConfig{Concurrency: 4})
+//
+//	POST /batch
+//	{"requests": [
+//		{"id": "me", "method": "GET", "path": "/users/me"},
+//		{"id": "media", "method": "GET", "path": "/media?limit=10"}
+//	]}
+//
+// Sub-requests are dispatched in-process through the adapter with the
+// caller's headers, so authentication and the route middlewares, including
+// security enforcement, apply to each of them. The response lists the status,
+// headers and body of every sub-request in request order; a failing
+// sub-request does not fail the batch. Batches cannot be nested.
+//
+// Call it once per API. Options apply to the batch operation, e.g. Secure or
+// a MaxBodyBytes limit.
+func Batch(api API, config BatchConfig, options ...func(*BaseRoute)) {
+	if config.Path == "" {
+		config.Path = DefaultBatchPath
+	}
+	if config.MaxRequests <= 0 {
+		config.MaxRequests = DefaultBatchMaxRequests
+	}
+
+	adapter := api.Adapter()
+	if router, ok := rootMethodRouter(api); ok {
+		adapter = router
+	}
+	b := &batcher{adapter: adapter, config: config}
+
+	options = append([]func(*BaseRoute){func(route *BaseRoute) {
+		route.Operation = &Operation{
+			OperationID: "batch",
+			Summary:     "Execute a batch of requests",
+			Description: fmt.Sprintf("Executes up to %d requests and returns their responses in request order. "+
+				"Each request is authorized on its own; a failing request does not fail the batch.", config.MaxRequests),
+		}
+		route.Errors = append(route.Errors, http.StatusBadRequest, http.StatusUnprocessableEntity)
+		route.Middlewares = append(route.Middlewares, batchCallerMiddleware)
+	}}, options...)
+
+	Post(api, config.Path, b.handle, options...)
+}
+
+// batchCallerMiddleware stores the batch request in its context, so the
+// handler can build sub-requests from it.
+func batchCallerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), batchCallerContextKey, r)))
+	})
+}
+
+// batcher executes the sub-requests of batches.
+type batcher struct {
+	adapter http.Handler
+	config  BatchConfig
+}
+
+func (b *batcher) handle(ctx context.Context, input *BatchInput) (*BatchOutput, error) {
+	if ctx.Value(batchContextKey) != nil {
+		return nil, Error400BadRequest("batch requests cannot be nested")
+	}
+
+	caller, ok := ctx.Value(batchCallerContextKey).(*http.Request)
+	if !ok {
+		return nil, Error500InternalServerError("batch request is missing")
+	}
+
+	requests := input.Body.Requests
+	if details := b.check(requests); len(details) > 0 {
+		return nil, Error422UnprocessableEntity("invalid batch", details...)
+	}
+
+	// Sub-requests get a fresh context, as the request context carries the
+	// router's routing state, but are canceled with the batch request.
+	subCtx, cancel := context.WithCancel(context.WithValue(context.Background(), batchContextKey, true))
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	output := &BatchOutput{}
+	output.Body.Responses = b.run(subCtx, caller, requests)
+
+	return output, nil
+}
+
+// check validates the number of sub-requests and their methods and paths.
+func (b *batcher) check(requests []BatchRequest) []error {
+	if len(requests) > b.config.MaxRequests {
+		return []error{&ErrorDetail{
+			Code:     "too_many_requests",
+			Message:  fmt.Sprintf("batch has %d requests, the maximum is %d", len(requests), b.config.MaxRequests),
+			Location: "body.requests",
+		}}
+	}
+
+	var details []error
+	for i, request := range requests {
+		location := fmt.Sprintf("body.requests[%d]", i)
+		if !slices.Contains(batchMethods, request.Method) {
+			details = append(details, &ErrorDetail{
+				Code:     "invalid_method",
+				Message:  fmt.Sprintf("method %q is not supported, expected one of %s", request.Method, strings.Join(batchMethods, ", ")),
+				Location: location + ".method",
+			})
+		}
+		if u, err := url.Parse(request.Path); err != nil || u.Scheme != "" || u.Host != "" ||
+			!strings.HasPrefix(request.Path, "/") || strings.HasPrefix(request.Path, "//") {
+			details = append(details, &ErrorDetail{
+				Code:     "invalid_path",
+				Message:  fmt.Sprintf("path %q must be an absolute path", request.Path),
+				Location: location + ".path",
+			})
+		}
+	}
+
+	return details
+}
+
+// run executes the sub-requests, in sequence or at most Concurrency at once.
+func (b *batcher) run(ctx context.Context, caller *http.Request, requests []BatchRequest) []BatchResponse {
+	responses := make([]BatchResponse, len(requests))
+	if b.config.Concurrency < 2 {
+		for i := range requests {
+			responses[i] = b.do(ctx, caller, requests[i])
+		}
+
+		return responses
+	}
+
+	slots := make(chan struct{}, b.config.Concurrency)
+	var wg sync.WaitGroup
+	for i := range requests {
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			responses[i] = b.do(ctx, caller, requests[i])
+		})
+	}
+	wg.Wait()
+
+	return responses
+}
+
+// do executes a sub-request and records its response.
+func (b *batcher) do(ctx context.Context, caller *http.Request, request BatchRequest) BatchResponse {
+	req, err := newBatchSubRequest(ctx, caller, request)
+	if err != nil {
+		return BatchResponse{ID: request.ID, Status: http.StatusBadRequest, Body: err.Error()}
+	}
+
+	recorder := httptest.NewRecorder()
+	b.adapter.ServeHTTP(recorder, req)
+
+	return BatchResponse{
+		ID:      request.ID,
+		Status:  recorder.Code,
+		Headers: batchResponseHeaders(recorder.Header()),
+		Body:    batchResponseBody(recorder.Header().Get("Content-Type"), recorder.Body.Bytes()),
+	}
+}
+
+// newBatchSubRequest creates the request of a sub-request. It keeps the
+// headers of the batch request, except for those describing the batch body,
+// and adds the sub-request headers.
+func newBatchSubRequest(ctx context.Context, caller *http.Request, request BatchRequest) (*http.Request, error) {
+	var body io.Reader = http.NoBody
+	if request.Body != nil {
+		data, err := json.Marshal(request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, request.Method, request.Path, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	req.Header = caller.Header.Clone()
+	for _, name := range batchDroppedHeaders {
+		req.Header.Del(name)
+	}
+	for name, value := range request.Headers {
+		if !slices.ContainsFunc(batchCallerHeaders, func(h string) bool { return strings.EqualFold(h, name) }) {
+			req.Header.Set(name, value)
+		}
+	}
+	if request.Body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Host = caller.Host
+	req.RemoteAddr = caller.RemoteAddr
+	req.TLS = caller.TLS
+
+	return req, nil
+}
+
+// batchResponseHeaders flattens response headers, joining multiple values
+// with commas.
+func batchResponseHeaders(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	return headers
+}
+
+// batchResponseBody returns a recorded body as JSON if its content type is
+// JSON, and as a string otherwise.
+func batchResponseBody(contentType string, data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err == nil {
+			return value
+		}
+	}
+
+	return string(data)
+}
@@ -0,0 +1,295 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchUserKey struct{}
+
+type BatchItemOutput struct {
+	Version string `schema:"X-Version,location=header"`
+	Body    struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+		User string `json:"user"`
+	} `body:"structured"`
+}
+
+type CreateBatchItemInput struct {
+	Body struct {
+		Name string `json:"name" schema:"name"`
+	} `body:"structured"`
+}
+
+func (o BatchItemOutput) Status() int {
+	if o.Body.ID == 100 {
+		return http.StatusCreated
+	}
+
+	return http.StatusOK
+}
+
+// newBatchTestAPI creates an API authenticating requests from their
+// Authorization header at the router level, like a JWT middleware, and
+// enforcing route roles with an API middleware.
+func newBatchTestAPI(t *testing.T, config BatchConfig) (*chi.Mux, API) {
+	t.Helper()
+
+	router := chi.NewMux()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); user != "" {
+				r = r.WithContext(context.WithValue(r.Context(), batchUserKey{}, user))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	api := NewAPI(&testChiAdapter{router: router})
+	api.UseMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if sec := GetRouteSecurityContext(r); sec != nil {
+				user, _ := r.Context().Value(batchUserKey{}).(string)
+				if user == "" {
+					WriteErr(api, r, w, 0, "", Error401Unauthorized("unauthorized"))
+
+					return
+				}
+				if !strings.Contains(strings.Join(sec.Roles, ","), user) {
+					WriteErr(api, r, w, 0, "", Error403Forbidden("forbidden"))
+
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	Get(api, "/items/{id}", func(ctx context.Context, input *GetUserInput) (*BatchItemOutput, error) {
+		output := &BatchItemOutput{Version: "v1"}
+		output.Body.ID = input.ID
+		output.Body.Name = "item"
+		output.Body.User, _ = ctx.Value(batchUserKey{}).(string)
+
+		return output, nil
+	})
+
+	Post(api, "/items", func(ctx context.Context, input *CreateBatchItemInput) (*BatchItemOutput, error) {
+		output := &BatchItemOutput{}
+		output.Body.ID = 100
+		output.Body.Name = input.Body.Name
+
+		return output, nil
+	})
+
+	Get(api, "/admin/items/{id}", func(ctx context.Context, input *GetUserInput) (*BatchItemOutput, error) {
+		return &BatchItemOutput{}, nil
+	}, Secure(Roles("admin")))
+
+	Batch(api, config)
+
+	return router, api
+}
+
+func postBatch(router http.Handler, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestBatch_Sequence(t *testing.T) {
+	router, _ := newBatchTestAPI(t, BatchConfig{})
+
+	recorder := postBatch(router, `{"requests": [
+		{"id": "get", "method": "GET", "path": "/items/1"},
+		{"id": "create", "method": "POST", "path": "/items", "body": {"name": "new"}},
+		{"id": "missing", "method": "GET", "path": "/missing"},
+		{"method": "DELETE", "path": "/items/1"}
+	]}`, map[string]string{"Authorization": "Bearer alice"})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var body struct {
+		Responses []BatchResponse `json:"responses"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Len(t, body.Responses, 4)
+
+	get := body.Responses[0]
+	assert.Equal(t, "get", get.ID)
+	assert.Equal(t, http.StatusOK, get.Status)
+	assert.Equal(t, "v1", get.Headers["X-Version"])
+	assert.Equal(t, "application/json", get.Headers["Content-Type"])
+	assert.Equal(t, map[string]any{"id": float64(1), "name": "item", "user": "alice"}, get.Body)
+
+	create := body.Responses[1]
+	assert.Equal(t, "create", create.ID)
+	assert.Equal(t, http.StatusCreated, create.Status)
+	assert.Equal(t, map[string]any{"id": float64(100), "name": "new", "user": ""}, create.Body)
+
+	assert.Equal(t, http.StatusNotFound, body.Responses[2].Status)
+
+	deleted := body.Responses[3]
+	assert.Empty(t, deleted.ID)
+	assert.Equal(t, http.StatusMethodNotAllowed, deleted.Status)
+	assert.Equal(t, "GET, HEAD, OPTIONS", deleted.Headers["Allow"])
+}
+
+func TestBatch_EnforcesSecurityPerRequest(t *testing.T) {
+	router, _ := newBatchTestAPI(t, BatchConfig{})
+
+	body := `{"requests": [
+		{"method": "GET", "path": "/admin/items/1"},
+		{"method": "GET", "path": "/admin/items/1", "headers": {"Authorization": "Bearer admin"}},
+		{"method": "GET", "path": "/items/1"}
+	]}`
+
+	statuses := func(recorder *httptest.ResponseRecorder) []int {
+		var body struct {
+			Responses []BatchResponse `json:"responses"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		result := make([]int, len(body.Responses))
+		for i, response := range body.Responses {
+			result[i] = response.Status
+		}
+
+		return result
+	}
+
+	// Sub-requests cannot replace the caller's credentials.
+	recorder := postBatch(router, body, map[string]string{"Authorization": "Bearer alice"})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, []int{http.StatusForbidden, http.StatusForbidden, http.StatusOK}, statuses(recorder))
+
+	recorder = postBatch(router, body, nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusOK}, statuses(recorder))
+
+	recorder = postBatch(router, body, map[string]string{"Authorization": "Bearer admin"})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK}, statuses(recorder))
+}
+
+func TestBatch_BoundedConcurrency(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	var running, peak atomic.Int32
+	Get(api, "/slow/{id}", func(ctx context.Context, input *GetUserInput) (*BatchItemOutput, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		output := &BatchItemOutput{}
+		output.Body.ID = input.ID
+
+		return output, nil
+	})
+	Batch(api, BatchConfig{Concurrency: 3})
+
+	requests := make([]string, 9)
+	for i := range requests {
+		requests[i] = `{"method": "GET", "path": "/slow/` + string(rune('1'+i)) + `"}`
+	}
+	recorder := postBatch(router, `{"requests": [`+strings.Join(requests, ",")+`]}`, nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var body struct {
+		Responses []BatchResponse `json:"responses"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Len(t, body.Responses, 9)
+	for i, response := range body.Responses {
+		assert.Equal(t, float64(i+1), response.Body.(map[string]any)["id"])
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, peak.Load(), int32(1))
+}
+
+func TestBatch_InvalidBatches(t *testing.T) {
+	router, _ := newBatchTestAPI(t, BatchConfig{MaxRequests: 2})
+
+	recorder := postBatch(router, `{"requests": [
+		{"method": "GET", "path": "/items/1"},
+		{"method": "GET", "path": "/items/2"},
+		{"method": "GET", "path": "/items/3"}
+	]}`, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.JSONEq(t, `{
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "invalid batch",
+		"errors": [{"code": "too_many_requests", "message": "batch has 3 requests, the maximum is 2", "location": "body.requests"}]
+	}`, recorder.Body.String())
+
+	recorder = postBatch(router, `{"requests": [
+		{"method": "TRACE", "path": "/items/1"},
+		{"method": "GET", "path": "https://example.com/items/2"}
+	]}`, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.JSONEq(t, `{
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "invalid batch",
+		"errors": [
+			{"code": "invalid_method", "message": "method \"TRACE\" is not supported, expected one of GET, HEAD, POST, PUT, PATCH, DELETE", "location": "body.requests[0].method"},
+			{"code": "invalid_path", "message": "path \"https://example.com/items/2\" must be an absolute path", "location": "body.requests[1].path"}
+		]
+	}`, recorder.Body.String())
+}
+
+func TestBatch_NotNested(t *testing.T) {
+	router, _ := newBatchTestAPI(t, BatchConfig{})
+
+	recorder := postBatch(router, `{"requests": [
+		{"method": "POST", "path": "/batch", "body": {"requests": [{"method": "GET", "path": "/items/1"}]}}
+	]}`, nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var body struct {
+		Responses []BatchResponse `json:"responses"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Len(t, body.Responses, 1)
+	assert.Equal(t, http.StatusBadRequest, body.Responses[0].Status)
+	assert.Equal(t, "batch requests cannot be nested", body.Responses[0].Body.(map[string]any)["detail"])
+}
+
+func TestBatch_OpenAPI(t *testing.T) {
+	_, api := newBatchTestAPI(t, BatchConfig{})
+
+	op := api.OpenAPI().Paths["/batch"].Post
+	require.NotNil(t, op)
+	assert.Equal(t, "batch", op.OperationID)
+	assert.Contains(t, op.Description, "up to 20 requests")
+	assert.Contains(t, op.Responses, "200")
+	assert.Contains(t, op.Responses, "422")
+
+	request := api.OpenAPI().Components.Schemas["BatchRequest"]
+	require.NotNil(t, request)
+	assert.Equal(t, []any{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}, request.Properties["method"].Enum)
+	assert.Contains(t, api.OpenAPI().Components.Schemas, "BatchResponse")
+}
@@ -0,0 +1,295 @@
+// Package client calls zorya operations over HTTP with the input and output
+// structs of their handlers. Clients generated by the clientgen package are
+// built on it.
+//
+// Inputs are encoded with the same `schema` and `body` tags, styles and
+// explode settings the server decodes them with. Outputs are decoded from
+// the response headers and JSON body, and error responses are returned as
+// *zorya.ErrorModel.
+package client
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/talav/talav/pkg/component/schema"
+	"github.com/talav/talav/pkg/component/zorya"
+)
+
+// Doer sends HTTP requests. *http.Client implements it.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// RequestEditor modifies requests before they are sent, for example to add
+// authentication headers.
+type RequestEditor func(ctx context.Context, request *http.Request) error
+
+// Option configures a Client.
+type Option func(*Client)
+
+// Client sends requests to a zorya API.
+type Client struct {
+	baseURL  string
+	doer     Doer
+	metadata *schema.Metadata
+	encoder  schema.Encoder
+	editors  []RequestEditor
+}
+
+// New creates a client for the API at baseURL, such as
+// "https://api.example.com". Requests use http.DefaultClient unless
+// WithHTTPClient is given.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		doer:     http.DefaultClient,
+		metadata: zorya.NewMetadata(),
+	}
+	c.encoder = schema.NewEncoder(c.metadata, "schema", "body")
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithHTTPClient sets the client sending requests.
+func WithHTTPClient(doer Doer) Option {
+	return func(c *Client) {
+		c.doer = doer
+	}
+}
+
+// WithRequestEditor adds a function modifying every request before it is
+// sent. Editors run in the order they are added.
+func WithRequestEditor(editor RequestEditor) Option {
+	return func(c *Client) {
+		c.editors = append(c.editors, editor)
+	}
+}
+
+// Do calls the operation at method and path, a path template such as
+// "/users/{id}", with the input struct, and decodes the response into a new
+// output struct O. A nil input sends no parameters or body.
+//
+// Responses with a 4xx or 5xx status return a *zorya.ErrorModel decoded
+// from the RFC 9457 problem document. Responses without one get an error
+// model built from the status.
+//
+//	user, err := client.Do[GetUserOutput](ctx, c, http.MethodGet, "/users/{id}", &GetUserInput{ID: "42"})
+//	var problem *zorya.ErrorModel
+//	if errors.As(err, &problem) && problem.Status == http.StatusNotFound {
+//		// ...
+//	}
+func Do[O any](ctx context.Context, c *Client, method, path string, input any) (*O, error) {
+	if input == nil {
+		input = struct{}{}
+	}
+
+	request, err := c.encoder.EncodeRequest(ctx, method, c.baseURL+path, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	request.Header.Set("Accept", "application/json")
+	for _, edit := range c.editors {
+		if err := edit(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := c.doer.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, decodeError(response)
+	}
+
+	output := new(O)
+	if err := c.decodeOutput(response, output); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return output, nil
+}
+
+// decodeError decodes an error response into an error model.
+func decodeError(response *http.Response) error {
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read error response: %w", err)
+	}
+
+	model := &zorya.ErrorModel{}
+	if !isJSON(response.Header.Get("Content-Type")) || json.Unmarshal(data, model) != nil {
+		model = &zorya.ErrorModel{Detail: strings.TrimSpace(string(data))}
+	}
+	if model.Status == 0 {
+		model.Status = response.StatusCode
+	}
+	if model.Title == "" {
+		model.Title = http.StatusText(response.StatusCode)
+	}
+
+	return model
+}
+
+// decodeOutput sets the header fields and the body field of an output struct.
+func (c *Client) decodeOutput(response *http.Response, output any) error {
+	rv := reflect.ValueOf(output).Elem()
+	structMeta, err := c.metadata.GetStructMetadata(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	for i := range structMeta.Fields {
+		field := &structMeta.Fields[i]
+		schemaMeta, ok := schema.GetTagMetadata[*schema.SchemaMetadata](field, "schema")
+		if !ok || schemaMeta.Location != schema.LocationHeader || field.HasTag("body") {
+			continue
+		}
+
+		values := response.Header.Values(schemaMeta.ParamName)
+		if len(values) == 0 {
+			continue
+		}
+		if err := setFromStrings(rv.Field(field.Index), values); err != nil {
+			return fmt.Errorf("header %s: %w", schemaMeta.ParamName, err)
+		}
+	}
+
+	bodyField := zorya.FindBodyField(structMeta)
+	if bodyField == nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	body := rv.Field(bodyField.Index)
+	if body.Kind() == reflect.Slice && body.Type().Elem().Kind() == reflect.Uint8 {
+		body.SetBytes(data)
+
+		return nil
+	}
+	if !isJSON(response.Header.Get("Content-Type")) {
+		return fmt.Errorf("unsupported content type %q", response.Header.Get("Content-Type"))
+	}
+
+	return json.Unmarshal(data, body.Addr().Interface())
+}
+
+// isJSON reports whether a content type is JSON or has a +json suffix.
+// A missing content type is treated as JSON.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// setFromStrings sets a header field from its values. Slice fields get one
+// item per value, as the server writes them.
+func setFromStrings(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Pointer {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), 0, len(values))
+		for _, value := range values {
+			item := reflect.New(field.Type().Elem()).Elem()
+			if err := setFromString(item, value); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, item)
+		}
+		field.Set(slice)
+
+		return nil
+	}
+
+	return setFromString(field, values[0])
+}
+
+// headerTimeLayouts are the layouts time header values are parsed with: the
+// HTTP date format, RFC 3339 and the time.Time.String format the server
+// writes time fields with.
+var headerTimeLayouts = []string{http.TimeFormat, time.RFC3339Nano, "2006-01-02 15:04:05.999999999 -0700 MST"}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// setFromString parses a header value into a scalar field. Fields of other
+// types are left unset.
+func setFromString(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeFor[time.Time]() {
+		for _, layout := range headerTimeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				field.Set(reflect.ValueOf(t))
+
+				return nil
+			}
+		}
+
+		return fmt.Errorf("cannot parse time %q", value)
+	}
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	//nolint:exhaustive // Other kinds are not supported in headers
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/talav/talav/pkg/component/zorya"
+	"github.com/talav/talav/pkg/component/zorya/adapters"
+)
+
+type Item struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Owner string   `json:"owner"`
+}
+
+type UpdateItemInput struct {
+	ID    string   `schema:"id,location=path"`
+	Tags  []string `schema:"tags,location=query"`
+	Owner string   `schema:"X-Owner,location=header"`
+	Body  struct {
+		Name string `schema:"name"`
+	} `body:"structured"`
+}
+
+type UpdateItemOutput struct {
+	Version int  `schema:"X-Version,location=header"`
+	Body    Item `body:"structured"`
+}
+
+type DeleteItemInput struct {
+	ID string `schema:"id,location=path"`
+}
+
+type DeleteItemOutput struct{}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := zorya.NewAPI(adapters.NewChi(router))
+	zorya.Put(api, "/items/{id}", func(ctx context.Context, input *UpdateItemInput) (*UpdateItemOutput, error) {
+		if input.ID == "missing" {
+			return nil, zorya.Error404NotFound("item not found")
+		}
+
+		return &UpdateItemOutput{
+			Version: 3,
+			Body:    Item{ID: input.ID, Name: input.Body.Name, Tags: input.Tags, Owner: input.Owner},
+		}, nil
+	})
+	zorya.Delete(api, "/items/{id}", func(ctx context.Context, input *DeleteItemInput) (*DeleteItemOutput, error) {
+		return &DeleteItemOutput{}, nil
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDo(t *testing.T) {
+	server := newTestServer(t)
+	c := New(server.URL)
+
+	input := &UpdateItemInput{ID: "a 1", Tags: []string{"x", "y"}, Owner: "ann"}
+	input.Body.Name = "Widget"
+	output, err := Do[UpdateItemOutput](context.Background(), c, http.MethodPut, "/items/{id}", input)
+	require.NoError(t, err)
+	assert.Equal(t, 3, output.Version)
+	assert.Equal(t, Item{ID: "a 1", Name: "Widget", Tags: []string{"x", "y"}, Owner: "ann"}, output.Body)
+
+	_, err = Do[DeleteItemOutput](context.Background(), c, http.MethodDelete, "/items/{id}", &DeleteItemInput{ID: "1"})
+	require.NoError(t, err)
+}
+
+func TestDo_ErrorModel(t *testing.T) {
+	server := newTestServer(t)
+	c := New(server.URL)
+
+	input := &UpdateItemInput{ID: "missing"}
+	input.Body.Name = "Widget"
+	_, err := Do[UpdateItemOutput](context.Background(), c, http.MethodPut, "/items/{id}", input)
+
+	var problem *zorya.ErrorModel
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "item not found", problem.Detail)
+}
+
+func TestDo_ErrorWithoutProblem(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := Do[DeleteItemOutput](context.Background(), New(server.URL), http.MethodDelete, "/items/{id}", &DeleteItemInput{ID: "1"})
+
+	var problem *zorya.ErrorModel
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusBadGateway, problem.Status)
+	assert.Equal(t, "Bad Gateway", problem.Title)
+	assert.Equal(t, "upstream unavailable", problem.Detail)
+}
+
+func TestWithRequestEditor(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithRequestEditor(func(ctx context.Context, r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer token")
+
+		return nil
+	}))
+	_, err := Do[DeleteItemOutput](context.Background(), c, http.MethodDelete, "/items/{id}", &DeleteItemInput{ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token", authorization)
+
+	failing := New(server.URL, WithRequestEditor(func(ctx context.Context, r *http.Request) error {
+		return errors.New("no token")
+	}))
+	_, err = Do[DeleteItemOutput](context.Background(), failing, http.MethodDelete, "/items/{id}", &DeleteItemInput{ID: "1"})
+	require.EqualError(t, err, "no token")
+}
@@ -0,0 +1,349 @@
+// Package clientgen generates typed Go clients for zorya APIs.
+//
+// The generator walks the operations registered with a live zorya.API and
+// emits a package with one method per operation. Methods take the handler's
+// input struct and return its output struct, so the client shares its request
+// and response types with the server and calls them through the client
+// package:
+//
+//	result, err := clientgen.Generate(api, "usersclient")
+//	if err != nil {
+//		return err
+//	}
+//	for _, skipped := range result.Skipped {
+//		log.Printf("skipped %s %s: %s", skipped.Method, skipped.Path, skipped.Reason)
+//	}
+//	return os.WriteFile("usersclient/client.go", result.Source, 0o644)
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/talav/talav/pkg/component/zorya"
+)
+
+// clientImportPath is the import path of the runtime generated clients use.
+const clientImportPath = "github.com/talav/talav/pkg/component/zorya/client"
+
+// Result is the output of Generate.
+type Result struct {
+	// Source is the gofmt-formatted source of the client package.
+	Source []byte
+
+	// Skipped lists the operations without a client method.
+	Skipped []Skipped
+}
+
+// Skipped describes an operation the generator could not create a method for.
+type Skipped struct {
+	Method string
+	Path   string
+	Reason string
+}
+
+// Generate returns the source of a client package named packageName for the
+// operations registered with api.
+//
+// Operations are skipped when their input or output struct cannot be referred
+// to from another package, such as unnamed structs other than struct{} and
+// types declared in package main, and when they stream their response.
+func Generate(api zorya.API, packageName string) (*Result, error) {
+	if !token.IsIdentifier(packageName) {
+		return nil, fmt.Errorf("invalid package name %q", packageName)
+	}
+
+	g := &generator{
+		imports: map[string]string{
+			"context":        "context",
+			clientImportPath: "zoryaclient",
+		},
+		aliases: map[string]bool{"context": true, "zoryaclient": true},
+		names:   map[string]bool{"New": true},
+	}
+
+	var methods bytes.Buffer
+	result := &Result{}
+	for _, route := range zorya.Routes(api) {
+		method, err := g.method(route)
+		if err != nil {
+			result.Skipped = append(result.Skipped, Skipped{Method: route.Method, Path: route.Path, Reason: err.Error()})
+
+			continue
+		}
+		methods.WriteString(method)
+	}
+
+	title := "the API"
+	if info := api.OpenAPI().Info; info != nil && info.Title != "" {
+		title = "the " + info.Title
+		if !strings.HasSuffix(info.Title, "API") {
+			title += " API"
+		}
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by zorya clientgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "// Package %s is a client for %s.\n", packageName, title)
+	fmt.Fprintf(&src, "package %s\n\n", packageName)
+	src.WriteString("import (\n")
+	for _, importPath := range slices.Sorted(maps.Keys(g.imports)) {
+		if alias := g.imports[importPath]; alias != path.Base(importPath) {
+			fmt.Fprintf(&src, "\t%s %q\n", alias, importPath)
+		} else {
+			fmt.Fprintf(&src, "\t%q\n", importPath)
+		}
+	}
+	src.WriteString(")\n\n")
+	fmt.Fprintf(&src, "// Client calls the operations of %s.\n", title)
+	src.WriteString("type Client struct {\n\tclient *zoryaclient.Client\n}\n\n")
+	src.WriteString("// New creates a client for the API at baseURL.\n")
+	src.WriteString("func New(baseURL string, opts ...zoryaclient.Option) *Client {\n")
+	src.WriteString("\treturn &Client{client: zoryaclient.New(baseURL, opts...)}\n}\n")
+	src.Write(methods.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated client: %w", err)
+	}
+	result.Source = formatted
+
+	return result, nil
+}
+
+// generator tracks the imports and method names of a generated client.
+type generator struct {
+	// imports maps import paths to their aliases.
+	imports map[string]string
+	aliases map[string]bool
+	names   map[string]bool
+}
+
+// method returns the source of the client method calling a route.
+func (g *generator) method(route zorya.RouteInfo) (string, error) {
+	if route.Input == nil || route.Output == nil {
+		return "", fmt.Errorf("input and output types are unknown")
+	}
+	if streams(route) {
+		return "", fmt.Errorf("streaming responses are not supported")
+	}
+
+	noInput := route.Input.Name() == "" && route.Input.NumField() == 0
+	noOutput := route.Output.Name() == "" && route.Output.NumField() == 0
+
+	// Imports added for a skipped route would be unused.
+	imports, aliases := maps.Clone(g.imports), maps.Clone(g.aliases)
+	input, output, err := g.signatureTypes(route, noInput, noOutput)
+	if err != nil {
+		g.imports, g.aliases = imports, aliases
+
+		return "", err
+	}
+
+	name := g.methodName(route)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n// %s calls %s %s.\n", name, route.Method, route.Path)
+	if route.Operation != nil && route.Operation.Summary != "" {
+		fmt.Fprintf(&b, "//\n// %s\n", strings.Join(strings.Fields(route.Operation.Summary), " "))
+	}
+	if route.Operation != nil && route.Operation.Deprecated {
+		b.WriteString("//\n// Deprecated: the operation is deprecated by the API.\n")
+	}
+
+	params := "ctx context.Context"
+	inputArg := "nil"
+	if !noInput {
+		params += ", input *" + input
+		inputArg = "input"
+	}
+	call := fmt.Sprintf("zoryaclient.Do[%%s](ctx, c.client, %q, %q, %s)", route.Method, route.Path, inputArg)
+
+	if noOutput {
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) error {\n", name, params)
+		fmt.Fprintf(&b, "\t_, err := "+call+"\n\n\treturn err\n}\n", "struct{}")
+	} else {
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) (*%s, error) {\n", name, params, output)
+		fmt.Fprintf(&b, "\treturn "+call+"\n}\n", output)
+	}
+
+	return b.String(), nil
+}
+
+// signatureTypes returns the expressions of a route's input and output types.
+func (g *generator) signatureTypes(route zorya.RouteInfo, noInput, noOutput bool) (input, output string, err error) {
+	if !noInput {
+		if input, err = g.typeExpr(route.Input); err != nil {
+			return "", "", fmt.Errorf("input: %w", err)
+		}
+	}
+	if !noOutput {
+		if output, err = g.typeExpr(route.Output); err != nil {
+			return "", "", fmt.Errorf("output: %w", err)
+		}
+	}
+
+	return input, output, nil
+}
+
+// streams reports whether a route streams its response, either as a
+// text/event-stream or from a body function.
+func streams(route zorya.RouteInfo) bool {
+	if route.Operation != nil {
+		for _, response := range route.Operation.Responses {
+			if response != nil && response.Content["text/event-stream"] != nil {
+				return true
+			}
+		}
+	}
+
+	for i := range route.Output.NumField() {
+		field := route.Output.Field(i)
+		if _, ok := field.Tag.Lookup("body"); ok && field.Type.Kind() == reflect.Func {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeExpr returns the Go expression referring to t from the generated
+// package, adding the imports it needs.
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if strings.Contains(t.Name(), "[") {
+			return "", fmt.Errorf("generic type %s is not supported", t)
+		}
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if t.PkgPath() == "main" || strings.HasSuffix(t.PkgPath(), "_test") {
+			return "", fmt.Errorf("type %s cannot be imported", t)
+		}
+		if !token.IsExported(t.Name()) {
+			return "", fmt.Errorf("type %s is not exported", t)
+		}
+
+		return g.importAlias(t.PkgPath()) + "." + t.Name(), nil
+	}
+
+	//nolint:exhaustive // Other unnamed types cannot be referred to
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := g.typeExpr(t.Elem())
+
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeExpr(t.Elem())
+
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+
+		return "map[" + key + "]" + elem, err
+	default:
+		return "", fmt.Errorf("unnamed type %s is not supported", t)
+	}
+}
+
+// importAlias returns the alias of an import path, adding the import.
+// Aliases are derived from the last path element and numbered on conflicts.
+func (g *generator) importAlias(importPath string) string {
+	if alias, ok := g.imports[importPath]; ok {
+		return alias
+	}
+
+	base := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, path.Base(importPath))
+	if base == "" || !unicode.IsLetter(rune(base[0])) || token.IsKeyword(base) {
+		base = "pkg" + base
+	}
+
+	alias := base
+	for i := 2; g.aliases[alias]; i++ {
+		alias = base + strconv.Itoa(i)
+	}
+	g.imports[importPath] = alias
+	g.aliases[alias] = true
+
+	return alias
+}
+
+// initialisms are the words written in upper case in method names.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true, "json": true,
+	"sql": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// methodName returns the client method name of a route: the operation ID in
+// PascalCase, or the method and path when the operation has no ID, such as
+// GetUsersByID for GET /users/{id}. Names are numbered on conflicts.
+func (g *generator) methodName(route zorya.RouteInfo) string {
+	var name string
+	if route.Operation != nil && route.Operation.OperationID != "" {
+		name = pascalCase(route.Operation.OperationID)
+	}
+	if name == "" {
+		name = pascalCase(strings.ToLower(route.Method))
+		for _, segment := range strings.Split(route.Path, "/") {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				name += "By" + pascalCase(strings.Trim(segment, "{}"))
+			} else {
+				name += pascalCase(segment)
+			}
+		}
+	}
+	if !unicode.IsLetter(rune(name[0])) {
+		name = "Op" + name
+	}
+
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+
+	return unique
+}
+
+// pascalCase joins the words of s, split at characters other than letters and
+// digits, capitalizing each word.
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package clientgen
+
+import (
+	"context"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/talav/talav/pkg/component/zorya"
+	"github.com/talav/talav/pkg/component/zorya/adapters"
+)
+
+type GetUserInput struct {
+	ID string `schema:"id,location=path"`
+}
+
+type UserOutput struct {
+	Body struct {
+		ID string `json:"id"`
+	} `body:"structured"`
+}
+
+type DeleteUserOutput struct{}
+
+type ProgressOutput struct {
+	Body zorya.EventStream[string] `body:"structured"`
+}
+
+func withOperationID(id string) func(*zorya.BaseRoute) {
+	return func(route *zorya.BaseRoute) {
+		route.Operation = &zorya.Operation{OperationID: id, Summary: "Operation " + id}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	api := zorya.NewAPI(adapters.NewChi(chi.NewMux()))
+	zorya.Get(api, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*UserOutput, error) {
+		return &UserOutput{}, nil
+	}, withOperationID("getUser"))
+	zorya.Delete(api, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*DeleteUserOutput, error) {
+		return &DeleteUserOutput{}, nil
+	}, zorya.Deprecated(time.Time{}, time.Time{}, ""))
+	zorya.Get(api, "/health", func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return &struct{}{}, nil
+	})
+	zorya.Get(api, "/stats", func(ctx context.Context, input *struct{}) (*struct {
+		Body struct{ Count int } `body:"structured"`
+	}, error) {
+		return nil, nil
+	})
+	zorya.Get(api, "/progress", func(ctx context.Context, input *struct{}) (*ProgressOutput, error) {
+		return &ProgressOutput{}, nil
+	})
+
+	result, err := Generate(api, "usersclient")
+	require.NoError(t, err)
+
+	src := string(result.Source)
+	_, err = parser.ParseFile(token.NewFileSet(), "client.go", result.Source, parser.ParseComments)
+	require.NoError(t, err, src)
+	formatted, err := format.Source(result.Source)
+	require.NoError(t, err)
+	assert.Equal(t, src, string(formatted))
+
+	assert.Contains(t, src, "// Code generated by zorya clientgen. DO NOT EDIT.")
+	assert.Contains(t, src, "package usersclient")
+	assert.Contains(t, src, `"github.com/talav/talav/pkg/component/zorya/clientgen"`)
+	assert.Contains(t, src, `zoryaclient "github.com/talav/talav/pkg/component/zorya/client"`)
+	assert.Contains(t, src, "// GetUser calls GET /users/{id}.\n//\n// Operation getUser\n")
+	assert.Contains(t, src, "func (c *Client) GetUser(ctx context.Context, input *clientgen.GetUserInput) (*clientgen.UserOutput, error) {\n"+
+		"\treturn zoryaclient.Do[clientgen.UserOutput](ctx, c.client, \"GET\", \"/users/{id}\", input)\n}")
+	assert.Contains(t, src, "// Deprecated: the operation is deprecated by the API.\n"+
+		"func (c *Client) DeleteUsersByID(ctx context.Context, input *clientgen.GetUserInput) (*clientgen.DeleteUserOutput, error) {")
+	assert.Contains(t, src, "func (c *Client) GetHealth(ctx context.Context) error {\n"+
+		"\t_, err := zoryaclient.Do[struct{}](ctx, c.client, \"GET\", \"/health\", nil)\n\n\treturn err\n}")
+
+	require.Len(t, result.Skipped, 2)
+	assert.Equal(t, Skipped{Method: "GET", Path: "/progress", Reason: "streaming responses are not supported"}, result.Skipped[0])
+	assert.Equal(t, "GET", result.Skipped[1].Method)
+	assert.Equal(t, "/stats", result.Skipped[1].Path)
+	assert.Contains(t, result.Skipped[1].Reason, "output: unnamed type")
+}
+
+func TestGenerate_InvalidPackageName(t *testing.T) {
+	api := zorya.NewAPI(adapters.NewChi(chi.NewMux()))
+
+	_, err := Generate(api, "users-client")
+	require.EqualError(t, err, `invalid package name "users-client"`)
+}
+
+func TestPascalCase(t *testing.T) {
+	tests := map[string]string{
+		"createUser":     "CreateUser",
+		"get-user-by-id": "GetUserByID",
+		"list_api_keys":  "ListAPIKeys",
+		"users.v2.get":   "UsersV2Get",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, pascalCase(input), input)
+	}
+}
@@ -0,0 +1,410 @@
+package zorya
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/talav/talav/pkg/component/negotiation"
+)
+
+// DefaultCompressionMinSize is the default minimum response size compressed (1KB).
+const DefaultCompressionMinSize = 1024
+
+// Supported content codings.
+const (
+	EncodingZstd    = "zstd"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// CompressionConfig configures response compression. See Compress.
+type CompressionConfig struct {
+	// Encodings are the content codings offered, in server preference order.
+	// Supported codings are EncodingZstd, EncodingGzip and EncodingDeflate.
+	// If empty, uses zstd, gzip and deflate.
+	Encodings []string
+
+	// MinSize is the minimum body size in bytes compressed. Smaller bodies are
+	// sent as they are, unless the handler flushes them.
+	// If == 0, uses DefaultCompressionMinSize.
+	// If < 0, compresses bodies of any size.
+	MinSize int
+
+	// ExcludedContentTypes are media types that are never compressed, because
+	// they are already compressed. A "type/*" entry excludes a whole type.
+	// If nil, uses DefaultExcludedContentTypes.
+	ExcludedContentTypes []string
+}
+
+// DefaultExcludedContentTypes are the already compressed media types that are
+// not compressed again. SVG images are text and are compressed.
+var DefaultExcludedContentTypes = []string{
+	"image/*",
+	"video/*",
+	"audio/*",
+	"font/woff",
+	"font/woff2",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zip",
+	"application/zstd",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+}
+
+// encoder is a pooled compressing writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoderFactories create the encoders of the supported content codings.
+var encoderFactories = map[string]func(w io.Writer) encoder{
+	EncodingGzip: func(w io.Writer) encoder { return gzip.NewWriter(w) },
+	EncodingDeflate: func(w io.Writer) encoder {
+		// The HTTP deflate coding is the zlib format (RFC 9110, section 8.4.1.2).
+		return zlib.NewWriter(w)
+	},
+	EncodingZstd: func(w io.Writer) encoder {
+		enc, _ := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+
+		return enc
+	},
+}
+
+// Compress returns a middleware compressing response bodies with the content
+// coding negotiated from the Accept-Encoding request header. It can be used
+// as an API middleware or wrap the whole router:
+//
+//	api.UseMiddleware(zorya.Compress(zorya.CompressionConfig{}))
+//
+// Codings with q=0 are never used, and identity is used when the client
+// prefers it or accepts none of the offered codings. Responses are sent as
+// they are when:
+//   - the handler set Content-Encoding itself
+//   - the status has no body (1xx, 204, 304) or is 206 Partial Content
+//   - the media type is excluded, see CompressionConfig.ExcludedContentTypes
+//   - the body is smaller than CompressionConfig.MinSize and was not flushed
+//
+// Responses that could be compressed carry Vary: Accept-Encoding, including
+// HEAD responses and those sent uncompressed to clients not accepting a
+// supported coding. A strong ETag of a compressed response gets the coding as
+// suffix, e.g. "abc-gzip", since the encoded bytes differ; the suffix is
+// removed from If-Match and If-None-Match before the handler compares them.
+// Flushes, from streaming body functions or Server-Sent Events, flush the
+// compressed stream to the client.
+func Compress(config CompressionConfig) Middleware {
+	c := &compressor{
+		negotiator: negotiation.NewEncodingNegotiator(),
+		encodings:  config.Encodings,
+		minSize:    config.MinSize,
+		excluded:   config.ExcludedContentTypes,
+		pools:      make(map[string]*sync.Pool),
+	}
+	if len(c.encodings) == 0 {
+		c.encodings = []string{EncodingZstd, EncodingGzip, EncodingDeflate}
+	}
+	if c.minSize == 0 {
+		c.minSize = DefaultCompressionMinSize
+	}
+	if c.excluded == nil {
+		c.excluded = DefaultExcludedContentTypes
+	}
+	for _, encoding := range c.encodings {
+		factory, ok := encoderFactories[encoding]
+		if !ok {
+			panic("zorya.Compress: unsupported encoding " + encoding)
+		}
+		c.pools[encoding] = &sync.Pool{New: func() any { return factory(io.Discard) }}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cw := &compressWriter{
+				ResponseWriter: w,
+				compressor:     c,
+				encoding:       c.negotiate(r.Header.Get("Accept-Encoding")),
+				head:           r.Method == http.MethodHead,
+			}
+			defer cw.close()
+
+			if cw.encoding != "" {
+				header := r.Header.Clone()
+				if decodeETags(header, cw.encoding) {
+					r = r.Clone(r.Context())
+					r.Header = header
+					cw.decodedETags = true
+				}
+			}
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// compressor holds the compression settings and encoder pools.
+type compressor struct {
+	negotiator *negotiation.Negotiator
+	encodings  []string
+	minSize    int
+	excluded   []string
+	pools      map[string]*sync.Pool
+}
+
+// negotiate returns the content coding for an Accept-Encoding header, or ""
+// for identity.
+func (c *compressor) negotiate(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	candidates := c.negotiator.Acceptable(header, append(slices.Clone(c.encodings), "identity"))
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	best, err := c.negotiator.Negotiate(header, candidates, false)
+	if err != nil || best.Type == "identity" {
+		return ""
+	}
+
+	return best.Type
+}
+
+// compressible reports whether a media type may be compressed.
+func (c *compressor) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType == ""
+	}
+
+	for _, excluded := range c.excluded {
+		if prefix, ok := strings.CutSuffix(excluded, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") && mediaType != "image/svg+xml" {
+				return false
+			}
+		} else if mediaType == excluded {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compressWriter buffers the start of a response body until it knows whether
+// to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	compressor *compressor
+	encoding   string
+	head       bool
+
+	// decodedETags is set when the request's conditional headers carried
+	// ETags of the encoded variant.
+	decodedETags bool
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder encoder
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if status >= 100 && status < 200 {
+		// Informational responses go out immediately.
+		w.ResponseWriter.WriteHeader(status)
+
+		return
+	}
+
+	w.status = status
+	if w.head || !w.eligible() {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(append(w.buf, p...)))
+		}
+		if w.head || !w.eligible() {
+			w.decide(false)
+		} else {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= w.compressor.minSize {
+				w.decide(true)
+			}
+
+			return len(p), nil
+		}
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(p)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends the buffered body, compressed if the response is eligible, and
+// flushes the underlying writer.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.decide(w.eligible())
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// eligible reports whether the response may be compressed.
+func (w *compressWriter) eligible() bool {
+	if w.status < 200 || w.status == http.StatusNoContent ||
+		w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return false
+	}
+	if w.Header().Get("Content-Encoding") != "" {
+		return false
+	}
+
+	return w.compressor.compressible(w.Header().Get("Content-Type"))
+}
+
+// decide writes the response header, compressing the body from now on if
+// compress is set and a coding was negotiated, and sends the buffered body.
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+
+	// HEAD responses have no body, but vary and name the encoded variant
+	// like the matching GET.
+	headEncoded := false
+	if w.head {
+		compress = false
+		if w.eligible() {
+			addVary(w.Header(), "Accept-Encoding")
+			headEncoded = w.encoding != ""
+		}
+	}
+
+	if compress {
+		addVary(w.Header(), "Accept-Encoding")
+		if w.encoding != "" {
+			w.Header().Set("Content-Encoding", w.encoding)
+			w.Header().Del("Content-Length")
+			w.encoder = w.compressor.pools[w.encoding].Get().(encoder)
+			w.encoder.Reset(w.ResponseWriter)
+		}
+	}
+	if w.encoder != nil || headEncoded || (w.status == http.StatusNotModified && w.decodedETags) {
+		// A 304 answers a validator of the encoded variant, so it names
+		// that variant too.
+		if etag := w.Header().Get("ETag"); etag != "" {
+			w.Header().Set("ETag", encodedETag(etag, w.encoding))
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) > 0 {
+		if w.encoder != nil {
+			_, _ = w.encoder.Write(w.buf)
+		} else {
+			_, _ = w.ResponseWriter.Write(w.buf)
+		}
+		w.buf = nil
+	}
+}
+
+// close sends a body below the minimum size as it is and finishes the
+// compressed stream.
+func (w *compressWriter) close() {
+	if !w.decided && w.status != 0 {
+		if w.eligible() && len(w.buf) > 0 {
+			addVary(w.Header(), "Accept-Encoding")
+		}
+		w.decide(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		w.compressor.pools[w.encoding].Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+// encodedETag returns the ETag of the variant of a representation encoded
+// with a content coding. Strong ETags get the coding as suffix, weak ETags
+// stay the same, since both variants are semantically equivalent.
+func encodedETag(etag, encoding string) string {
+	if len(etag) < 2 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// decodeETags removes the content coding suffix added by encodedETag from the
+// strong ETags in the If-Match and If-None-Match headers, and reports whether
+// it removed any.
+func decodeETags(header http.Header, encoding string) bool {
+	suffix := "-" + encoding + `"`
+	decoded := false
+	for _, name := range []string{"If-Match", "If-None-Match"} {
+		values := slices.Clone(header.Values(name))
+		for i, value := range values {
+			tags := strings.Split(value, ",")
+			for j, tag := range tags {
+				tag = strings.TrimSpace(tag)
+				if strings.HasPrefix(tag, `"`) && strings.HasSuffix(tag, suffix) && len(tag) > len(suffix) {
+					tags[j] = strings.TrimSuffix(tag, suffix) + `"`
+					decoded = true
+				}
+			}
+			values[i] = strings.Join(tags, ",")
+		}
+		header.Del(name)
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+
+	return decoded
+}
+
+// addVary adds a field name to the Vary header unless it is already listed.
+func addVary(header http.Header, field string) {
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" || strings.EqualFold(name, field) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", field)
+}
@@ -0,0 +1,346 @@
+package zorya
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/talav/talav/pkg/component/negotiation"
+)
+
+type CompressedListOutput struct {
+	Body struct {
+		Items []string
+	} `body:"structured"`
+}
+
+type StreamedTextOutput struct {
+	ContentType string                                       `schema:"Content-Type,location=header"`
+	Body        func(w http.ResponseWriter, r *http.Request) `body:"structured"`
+}
+
+func newCompressionTestAPI(t *testing.T, items int) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	router.Use(Compress(CompressionConfig{}))
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/items", func(ctx context.Context, input *struct{}) (*CompressedListOutput, error) {
+		output := &CompressedListOutput{}
+		for range items {
+			output.Body.Items = append(output.Body.Items, "compressible item")
+		}
+
+		return output, nil
+	})
+	Get(api, "/tagged", func(ctx context.Context, input *struct{}) (*CompressedListOutput, error) {
+		output := &CompressedListOutput{}
+		for range items {
+			output.Body.Items = append(output.Body.Items, "compressible item")
+		}
+
+		return output, nil
+	}, AutoETag())
+	Get(api, "/stream", func(ctx context.Context, input *struct{}) (*StreamedTextOutput, error) {
+		return &StreamedTextOutput{
+			ContentType: "text/plain",
+			Body: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, "first chunk\n")
+				_ = http.NewResponseController(w).Flush()
+				_, _ = io.WriteString(w, "second chunk\n")
+			},
+		}, nil
+	})
+	Get(api, "/logo", func(ctx context.Context, input *struct{}) (*StreamedTextOutput, error) {
+		return &StreamedTextOutput{
+			ContentType: "image/png",
+			Body: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(bytes.Repeat([]byte{0x89}, 4096))
+			},
+		}, nil
+	})
+
+	return router
+}
+
+func getCompressed(router http.Handler, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept", "application/json")
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var reader io.Reader
+	var err error
+	switch encoding {
+	case EncodingGzip:
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case EncodingDeflate:
+		reader, err = zlib.NewReader(bytes.NewReader(body))
+	case EncodingZstd:
+		reader, err = zstd.NewReader(bytes.NewReader(body))
+	default:
+		return string(body)
+	}
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestCompress_Negotiation(t *testing.T) {
+	compressor := &compressor{
+		negotiator: negotiation.NewEncodingNegotiator(),
+		encodings:  []string{EncodingZstd, EncodingGzip, EncodingDeflate},
+	}
+
+	tests := map[string]string{
+		"":                       "",
+		"gzip":                   EncodingGzip,
+		"gzip, deflate":          EncodingGzip,
+		"deflate, gzip;q=0.5":    EncodingDeflate,
+		"gzip, zstd":             EncodingZstd,
+		"gzip;q=0.5, zstd;q=0.8": EncodingZstd,
+		"gzip;q=0, deflate":      EncodingDeflate,
+		"gzip;q=0":               "",
+		"*":                      EncodingZstd,
+		"*;q=0":                  "",
+		"*;q=0, gzip":            EncodingGzip,
+		"zstd;q=0, *":            EncodingGzip,
+		"identity, gzip;q=0.5":   "",
+		"identity;q=0, gzip;q=0": "",
+		"br":                     "",
+		"GZIP":                   EncodingGzip,
+	}
+	for header, expected := range tests {
+		assert.Equal(t, expected, compressor.negotiate(header), header)
+	}
+}
+
+func TestCompress_Encodings(t *testing.T) {
+	router := newCompressionTestAPI(t, 100)
+
+	for _, encoding := range []string{EncodingGzip, EncodingDeflate, EncodingZstd} {
+		t.Run(encoding, func(t *testing.T) {
+			recorder := getCompressed(router, "/items", encoding)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, encoding, recorder.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+			assert.Empty(t, recorder.Header().Get("Content-Length"))
+			body := decompress(t, encoding, recorder.Body.Bytes())
+			assert.True(t, strings.HasPrefix(body, `{"Items":["compressible item",`), body)
+		})
+	}
+}
+
+func TestCompress_Identity(t *testing.T) {
+	router := newCompressionTestAPI(t, 100)
+
+	recorder := getCompressed(router, "/items", "")
+
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+	assert.True(t, strings.HasPrefix(recorder.Body.String(), `{"Items":[`))
+}
+
+func TestCompress_ETag(t *testing.T) {
+	router := newCompressionTestAPI(t, 100)
+
+	identity := getCompressed(router, "/tagged", "")
+	require.Equal(t, http.StatusOK, identity.Code)
+	etag := identity.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	compressed := getCompressed(router, "/tagged", "gzip")
+	require.Equal(t, EncodingGzip, compressed.Header().Get("Content-Encoding"))
+	gzipETag := compressed.Header().Get("ETag")
+	assert.Equal(t, strings.TrimSuffix(etag, `"`)+`-gzip"`, gzipETag)
+
+	conditional := func(acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/tagged", nil)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	recorder := conditional("gzip", gzipETag)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Equal(t, gzipETag, recorder.Header().Get("ETag"))
+
+	recorder = conditional("", etag)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Equal(t, etag, recorder.Header().Get("ETag"))
+
+	// The compressed variant's ETag does not match the identity variant.
+	recorder = conditional("", gzipETag)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestCompress_Head(t *testing.T) {
+	router := newCompressionTestAPI(t, 100)
+
+	req := httptest.NewRequest(http.MethodHead, "/items", nil)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+}
+
+func TestCompress_HeadETag(t *testing.T) {
+	router := newCompressionTestAPI(t, 100)
+
+	head := func(acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodHead, "/tagged", nil)
+		req.Header.Set("Accept", "application/json")
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	// HEAD exposes the same validator as the matching GET.
+	compressed := head("gzip")
+	require.Equal(t, http.StatusOK, compressed.Code)
+	assert.Empty(t, compressed.Header().Get("Content-Encoding"))
+	assert.Equal(t, getCompressed(router, "/tagged", "gzip").Header().Get("ETag"), compressed.Header().Get("ETag"))
+
+	identity := head("")
+	require.Equal(t, http.StatusOK, identity.Code)
+	assert.Equal(t, getCompressed(router, "/tagged", "").Header().Get("ETag"), identity.Header().Get("ETag"))
+}
+
+func TestCompress_BelowMinSize(t *testing.T) {
+	router := newCompressionTestAPI(t, 2)
+
+	recorder := getCompressed(router, "/items", "gzip")
+
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+	assert.JSONEq(t, `{"Items":["compressible item","compressible item"]}`, recorder.Body.String())
+}
+
+func TestCompress_ExcludedContentType(t *testing.T) {
+	router := newCompressionTestAPI(t, 0)
+
+	recorder := getCompressed(router, "/logo", "gzip")
+
+	assert.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Empty(t, recorder.Header().Get("Vary"))
+	assert.Equal(t, 4096, recorder.Body.Len())
+}
+
+func TestCompress_StreamingFlush(t *testing.T) {
+	router := newCompressionTestAPI(t, 0)
+
+	recorder := getCompressed(router, "/stream", "gzip")
+
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, EncodingGzip, recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "first chunk\nsecond chunk\n", decompress(t, EncodingGzip, recorder.Body.Bytes()))
+}
+
+func TestCompress_FlushSendsCompressedData(t *testing.T) {
+	flushed := make(chan struct{})
+	handler := Compress(CompressionConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "hello\n")
+		_ = http.NewResponseController(w).Flush()
+		<-flushed
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	defer close(flushed)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	// The first chunk is readable before the handler returns.
+	reader, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	line := make([]byte, 6)
+	_, err = io.ReadFull(reader, line)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(line))
+}
+
+func TestCompress_NoBodyStatuses(t *testing.T) {
+	handler := Compress(CompressionConfig{MinSize: -1})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Empty(t, recorder.Body.Bytes())
+}
+
+func TestCompress_ExistingContentEncodingAndVary(t *testing.T) {
+	handler := Compress(CompressionConfig{MinSize: -1})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/encoded" {
+			w.Header().Set("Content-Encoding", "br")
+		}
+		w.Header().Set("Vary", "Accept, accept-encoding")
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "payload")
+	}))
+
+	for path, encoding := range map[string]string{"/encoded": "br", "/plain": "gzip"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, encoding, recorder.Header().Get("Content-Encoding"), path)
+		assert.Equal(t, []string{"Accept, accept-encoding"}, recorder.Header().Values("Vary"), path)
+		assert.Equal(t, "payload", decompress(t, recorder.Header().Get("Content-Encoding"), recorder.Body.Bytes()), path)
+	}
+}
+
+func TestCompress_UnsupportedEncoding(t *testing.T) {
+	assert.PanicsWithValue(t, "zorya.Compress: unsupported encoding br", func() {
+		Compress(CompressionConfig{Encodings: []string{"br"}})
+	})
+}
@@ -3,8 +3,11 @@ package zorya
 // Config represents a configuration for a new API. See `huma.DefaultConfig()`
 // as a starting point.
 type Config struct {
-	// OpenAPIPath is the exact path to the OpenAPI spec endpoint.
-	// The path is used as-is, with no automatic suffix or extension handling.
+	// OpenAPIPath is the path to the OpenAPI spec without extension (a trailing
+	// `.json` is ignored). With `/openapi` the spec is served at `/openapi.json`
+	// and `/openapi.yaml`, the OpenAPI 3.0.3 rendition at `/openapi-3.0.json`
+	// and `/openapi-3.0.yaml`, and `/openapi` and `/openapi-3.0` select JSON or
+	// YAML from the Accept header.
 	OpenAPIPath string
 
 	// DocsPath is the path to the API documentation. If set to `/docs` it will
@@ -23,10 +26,18 @@ type Config struct {
 	// chosen from the keys of `Formats`.
 	DefaultFormat string
 
-	// NoFormatFallback disables the fallback to application/json (if available)
-	// when the client requests an unknown content type. If set and no format is
-	// negotiated, then a 406 Not Acceptable response will be returned.
+	// NoFormatFallback disables the fallback to the default format when the
+	// client requests an unknown content type. If set and no format is
+	// negotiated, then a 406 Not Acceptable response will be returned. Error
+	// responses always fall back to JSON so they remain renderable.
 	NoFormatFallback bool
+
+	// ResponseValidation validates structured response bodies against the
+	// response schema documented in OpenAPI before they are written, reporting
+	// violations as configured by the mode. It is meant for development, tests
+	// and staging since every checked response is serialized twice. Disabled
+	// by default.
+	ResponseValidation ResponseValidationMode
 }
 
 // DefaultConfig returns a default configuration for a new API. It is a good
@@ -44,7 +55,7 @@ type Config struct {
 //	api := zorya.NewAPI(router, zorya.WithConfig(config))
 func DefaultConfig() *Config {
 	return &Config{
-		OpenAPIPath:      "/openapi.json",
+		OpenAPIPath:      "/openapi",
 		DocsPath:         "/docs",
 		SchemasPath:      "/schemas",
 		DefaultFormat:    "application/json",
@@ -0,0 +1,324 @@
+package zorya
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const routeDeprecationContextKey contextKey = "route_deprecation_context"
+
+// RouteDeprecation describes the deprecation of a route. See Deprecated.
+type RouteDeprecation struct {
+	// Since is when the route was deprecated. Zero if unknown.
+	Since time.Time
+
+	// Sunset is when the route will stop working. Zero if not planned.
+	Sunset time.Time
+
+	// Link points to documentation about the deprecation, such as a migration guide.
+	Link string
+
+	// GoneAfterSunset answers requests with 410 Gone once Sunset has passed.
+	GoneAfterSunset bool
+
+	// ClientKey returns the client calls are logged for, such as the client
+	// IP or the authenticated user ID. Defaults to the client IP taken from
+	// the connection.
+	ClientKey RateLimitKeyFunc
+}
+
+// DeprecationOption configures the deprecation of a route.
+type DeprecationOption func(*RouteDeprecation)
+
+// GoneAfterSunset answers requests with a 410 Gone problem document once the
+// sunset date has passed, instead of running the handler.
+func GoneAfterSunset() DeprecationOption {
+	return func(d *RouteDeprecation) {
+		d.GoneAfterSunset = true
+	}
+}
+
+// DeprecationClientKey sets the function returning the client calls are
+// logged for. It takes the key functions of rate limits, such as
+// RateLimitByIP with trusted proxies or a key per authenticated user.
+func DeprecationClientKey(key RateLimitKeyFunc) DeprecationOption {
+	return func(d *RouteDeprecation) {
+		d.ClientKey = key
+	}
+}
+
+// Deprecated marks a route as deprecated in OpenAPI and in its responses.
+//
+// Responses carry a `Deprecation` header (RFC 9745) with the since date, a
+// `Sunset` header (RFC 8594) if sunset is not zero and a
+// `Link: <link>; rel="deprecation"` header if link is not empty. Calls are
+// logged per client with their user agent (see DeprecationClientKey), and the
+// route's deprecation is stored in the request context for middlewares such
+// as the metrics adapter (see GetRouteDeprecationContext).
+//
+//	zorya.Get(api, "/users/{id}/avatar", getAvatar,
+//		zorya.Deprecated(since, sunset, "https://example.com/docs/migrate-avatars",
+//			zorya.GoneAfterSunset(),
+//		),
+//	)
+//
+// Routes whose Operation is marked deprecated without this option send the
+// `Deprecation` header only.
+func Deprecated(since, sunset time.Time, link string, opts ...DeprecationOption) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.Deprecation = &RouteDeprecation{Since: since, Sunset: sunset, Link: link}
+		for _, opt := range opts {
+			opt(r.Deprecation)
+		}
+
+		if r.Operation == nil {
+			r.Operation = &Operation{}
+		}
+		r.Operation.Deprecated = true
+	}
+}
+
+// RouteDeprecationContext identifies a call to a deprecated route. It's
+// stored in the request context before API middlewares run.
+type RouteDeprecationContext struct {
+	Method      string
+	Path        string
+	OperationID string
+	Deprecation RouteDeprecation
+}
+
+// GetRouteDeprecationContext retrieves the deprecation of the called route
+// from the request context. Returns nil if the route is not deprecated.
+func GetRouteDeprecationContext(r *http.Request) *RouteDeprecationContext {
+	dep, ok := r.Context().Value(routeDeprecationContextKey).(*RouteDeprecationContext)
+	if !ok {
+		return nil
+	}
+
+	return dep
+}
+
+// routeDeprecation returns the deprecation of a route, from the Deprecated
+// option or the Operation's deprecated flag, or nil.
+func routeDeprecation(route *BaseRoute) *RouteDeprecation {
+	if route.Deprecation != nil {
+		return route.Deprecation
+	}
+	if route.Operation != nil && route.Operation.Deprecated {
+		return &RouteDeprecation{}
+	}
+
+	return nil
+}
+
+// deprecationLogInterval is the minimum time between two logs of calls by the
+// same client to the same deprecated route.
+const deprecationLogInterval = time.Minute
+
+// newDeprecationMiddleware creates middleware sending the deprecation headers
+// of a deprecated route and storing its deprecation in context. Returns nil if
+// the route is not deprecated.
+func newDeprecationMiddleware(route *BaseRoute) Middleware {
+	deprecation := routeDeprecation(route)
+	if deprecation == nil {
+		return nil
+	}
+
+	depContext := &RouteDeprecationContext{
+		Method:      route.Method,
+		Path:        route.Path,
+		OperationID: route.Operation.OperationID,
+		Deprecation: *deprecation,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			setDeprecationHeaders(w.Header(), deprecation)
+
+			r = r.WithContext(context.WithValue(r.Context(), routeDeprecationContextKey, depContext))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// newDeprecationLogMiddleware creates middleware logging the calls to a
+// deprecated route. It runs after the API middlewares, so client keys can use
+// the authenticated user. Calls of a client are logged at most once per
+// deprecationLogInterval, with the number of calls of the client since its
+// previous log. Returns nil if the route is not deprecated.
+func newDeprecationLogMiddleware(api API, route *BaseRoute) Middleware {
+	deprecation := routeDeprecation(route)
+	if deprecation == nil {
+		return nil
+	}
+
+	clientKey := deprecation.ClientKey
+	if clientKey == nil {
+		clientKey = RateLimitByIP()
+	}
+	calls := &deprecationCalls{clients: make(map[string]*deprecationClientCalls)}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Sub-requests of generated PATCH operations are logged with the
+			// PATCH request.
+			if isAutoPatchSubRequest(r) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			client := clientKey(r)
+			if count := calls.record(client, time.Now()); count > 0 {
+				api.Logger().WarnContext(r.Context(), "deprecated operation called",
+					"method", r.Method,
+					"path", route.Path,
+					"operation_id", route.Operation.OperationID,
+					"client", client,
+					"user_agent", r.UserAgent(),
+					"calls", count,
+				)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// deprecationCalls counts the calls to a deprecated route per client.
+type deprecationCalls struct {
+	mu      sync.Mutex
+	clients map[string]*deprecationClientCalls
+	sweepAt time.Time
+}
+
+// deprecationClientCalls counts the calls of a client since its last log.
+type deprecationClientCalls struct {
+	calls     int64
+	nextLogAt time.Time
+}
+
+// record counts a call of client and returns the number of calls to log, or
+// 0 if the client was logged less than deprecationLogInterval ago.
+func (d *deprecationCalls) record(client string, now time.Time) int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Forget the clients without calls since their last log once their
+	// interval has passed.
+	if !now.Before(d.sweepAt) {
+		for key, c := range d.clients {
+			if c.calls == 0 && !now.Before(c.nextLogAt) {
+				delete(d.clients, key)
+			}
+		}
+		d.sweepAt = now.Add(deprecationLogInterval)
+	}
+
+	c := d.clients[client]
+	if c == nil {
+		c = &deprecationClientCalls{}
+		d.clients[client] = c
+	}
+	c.calls++
+	if now.Before(c.nextLogAt) {
+		return 0
+	}
+
+	count := c.calls
+	c.calls = 0
+	c.nextLogAt = now.Add(deprecationLogInterval)
+
+	return count
+}
+
+// newGoneAfterSunsetMiddleware creates middleware answering requests with a
+// 410 Gone problem once the sunset date of the route has passed. It runs
+// after the API middlewares, so they see and count these calls. Returns nil
+// unless the route was deprecated with GoneAfterSunset.
+func newGoneAfterSunsetMiddleware(api API, route *BaseRoute) Middleware {
+	deprecation := route.Deprecation
+	if deprecation == nil || !deprecation.GoneAfterSunset || deprecation.Sunset.IsZero() {
+		return nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !time.Now().Before(deprecation.Sunset) {
+				WriteErr(api, r, w, 0, "", Error410Gone(
+					"this operation was removed on "+deprecation.Sunset.UTC().Format(http.TimeFormat),
+				))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setDeprecationHeaders sets the Deprecation header (RFC 9745) and, when
+// known, the Sunset header (RFC 8594) and the deprecation Link. A zero since
+// date is sent as the time of the response.
+func setDeprecationHeaders(h http.Header, deprecation *RouteDeprecation) {
+	since := deprecation.Since
+	if since.IsZero() {
+		since = time.Now()
+	}
+	h.Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+	if !deprecation.Sunset.IsZero() {
+		h.Set("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecation.Link != "" {
+		h.Add("Link", "<"+deprecation.Link+`>; rel="deprecation"`)
+	}
+}
+
+// documentDeprecation documents the 410 response of deprecated routes that
+// are removed after their sunset date.
+func documentDeprecation(route *BaseRoute) {
+	if route.Deprecation != nil && route.Deprecation.GoneAfterSunset {
+		route.Errors = append(route.Errors, http.StatusGone)
+	}
+}
+
+// documentDeprecationHeaders documents the deprecation headers of deprecated
+// routes. It runs once the responses have been generated.
+func documentDeprecationHeaders(route *BaseRoute) {
+	deprecation := routeDeprecation(route)
+	if deprecation == nil {
+		return
+	}
+
+	headers := map[string]*Param{
+		"Deprecation": {
+			Description: "Date the operation was deprecated, as `@` followed by a Unix timestamp (RFC 9745).",
+			Schema:      &Schema{Type: TypeString},
+		},
+	}
+	if !deprecation.Sunset.IsZero() {
+		headers["Sunset"] = &Param{
+			Description: "Date the operation will stop working (RFC 8594).",
+			Schema:      &Schema{Type: TypeString},
+		}
+	}
+	if deprecation.Link != "" {
+		headers["Link"] = &Param{
+			Description: `Link to the deprecation documentation, with rel="deprecation".`,
+			Schema:      &Schema{Type: TypeString},
+		}
+	}
+
+	resp := getResponse(route.Operation, getDefaultStatus(route))
+	if resp.Headers == nil {
+		resp.Headers = make(map[string]*Param)
+	}
+	for name, header := range headers {
+		if _, ok := resp.Headers[name]; !ok {
+			resp.Headers[name] = header
+		}
+	}
+}
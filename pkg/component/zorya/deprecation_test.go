@@ -0,0 +1,164 @@
+package zorya
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecated_Headers(t *testing.T) {
+	var logs bytes.Buffer
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	var seen *RouteDeprecationContext
+	api.UseMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = GetRouteDeprecationContext(r)
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2099, 12, 31, 0, 0, 0, 0, time.UTC)
+	Get(api, "/greeting", greetingHandler, Deprecated(since, sunset, "https://example.com/migrate"))
+
+	recorder := getGreetingFrom(router, "/greeting", "192.0.2.1:1234")
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "@1767225600", recorder.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 31 Dec 2099 00:00:00 GMT", recorder.Header().Get("Sunset"))
+	assert.Contains(t, recorder.Header().Values("Link"), `<https://example.com/migrate>; rel="deprecation"`)
+	assert.Contains(t, logs.String(), "deprecated operation called")
+	assert.Contains(t, logs.String(), "client=ip:192.0.2.1")
+
+	require.NotNil(t, seen)
+	assert.Equal(t, http.MethodGet, seen.Method)
+	assert.Equal(t, "/greeting", seen.Path)
+	assert.Equal(t, sunset, seen.Deprecation.Sunset)
+}
+
+func TestDeprecated_LogSampling(t *testing.T) {
+	var logs bytes.Buffer
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	Get(api, "/greeting", greetingHandler, Deprecated(time.Time{}, time.Time{}, ""))
+
+	for range 3 {
+		require.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+	}
+
+	// Calls within deprecationLogInterval of the logged one are not logged.
+	assert.Equal(t, 1, strings.Count(logs.String(), "deprecated operation called"))
+	assert.Contains(t, logs.String(), "calls=1")
+}
+
+func TestDeprecated_LogPerClient(t *testing.T) {
+	var logs bytes.Buffer
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	Get(api, "/greeting", greetingHandler, Deprecated(time.Time{}, time.Time{}, ""))
+
+	for _, addr := range []string{"192.0.2.1:1234", "192.0.2.1:1234", "192.0.2.2:1234"} {
+		require.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", addr).Code)
+	}
+
+	// Each client is logged once with its own count.
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "client=ip:192.0.2.1")
+	assert.Contains(t, lines[1], "client=ip:192.0.2.2")
+	assert.Contains(t, lines[1], "calls=1")
+}
+
+func TestDeprecated_LogClientKey(t *testing.T) {
+	var logs bytes.Buffer
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	// API middlewares run before calls are logged, so keys can use what they store.
+	api.UseMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Set("X-Test-User", "alice")
+			next.ServeHTTP(w, r)
+		})
+	})
+	Get(api, "/greeting", greetingHandler, Deprecated(time.Time{}, time.Time{}, "",
+		DeprecationClientKey(func(r *http.Request) string { return "user:" + r.Header.Get("X-Test-User") }),
+	))
+	Get(api, "/farewell", greetingHandler, Deprecated(time.Time{}, time.Time{}, "",
+		DeprecationClientKey(RateLimitByIP(netip.MustParsePrefix("10.0.0.0/8"))),
+	))
+
+	require.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+	assert.Contains(t, logs.String(), "client=user:alice")
+
+	req := httptest.NewRequest(http.MethodGet, "/farewell", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, logs.String(), "client=ip:198.51.100.7")
+}
+
+func TestDeprecated_GoneAfterSunset(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	// API middlewares, such as the deprecation metrics, see calls after the sunset.
+	var seen []*RouteDeprecationContext
+	api.UseMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = append(seen, GetRouteDeprecationContext(r))
+			next.ServeHTTP(w, r)
+		})
+	})
+	sunset := time.Now().Add(-time.Hour)
+	Get(api, "/greeting", greetingHandler, Deprecated(time.Time{}, sunset, "", GoneAfterSunset()))
+	Get(api, "/farewell", greetingHandler, Deprecated(time.Time{}, sunset, ""))
+
+	recorder := getGreetingFrom(router, "/greeting", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusGone, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.NotEmpty(t, recorder.Header().Get("Sunset"))
+	require.Len(t, seen, 1)
+	require.NotNil(t, seen[0])
+	assert.Equal(t, "/greeting", seen[0].Path)
+
+	// Without GoneAfterSunset the operation keeps working.
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/farewell", "192.0.2.1:1234").Code)
+}
+
+func TestDeprecated_OperationMetadata(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Get(api, "/greeting", greetingHandler, func(r *BaseRoute) {
+		r.Operation = &Operation{Deprecated: true}
+	})
+
+	recorder := getGreetingFrom(router, "/greeting", "192.0.2.1:1234")
+	assert.NotEmpty(t, recorder.Header().Get("Deprecation"))
+	assert.Empty(t, recorder.Header().Get("Sunset"))
+}
+
+func TestDeprecated_OpenAPI(t *testing.T) {
+	api := NewAPI(&testChiAdapter{router: chi.NewMux()})
+	Get(api, "/greeting", greetingHandler,
+		Deprecated(time.Time{}, time.Now().Add(time.Hour), "https://example.com/migrate", GoneAfterSunset()),
+	)
+
+	op := api.OpenAPI().Paths["/greeting"].Get
+	assert.True(t, op.Deprecated)
+	assert.Contains(t, op.Responses, "410")
+	assert.Contains(t, op.Responses["200"].Headers, "Deprecation")
+	assert.Contains(t, op.Responses["200"].Headers, "Sunset")
+	assert.Contains(t, op.Responses["200"].Headers, "Link")
+}
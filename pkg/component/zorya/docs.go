@@ -7,26 +7,27 @@ import (
 )
 
 // registerDocsEndpoint registers the docs endpoint if configured.
-func registerDocsEndpoint(a *api) {
-	if a.config.DocsPath == "" {
+func registerDocsEndpoint(a API, adapter Adapter) {
+	config := a.Config()
+	if config.DocsPath == "" {
 		return
 	}
 
 	title := "API Documentation"
-	if a.openAPI != nil && a.openAPI.Info != nil && a.openAPI.Info.Title != "" {
-		title = a.openAPI.Info.Title
+	if openAPI := a.OpenAPI(); openAPI != nil && openAPI.Info != nil && openAPI.Info.Title != "" {
+		title = openAPI.Info.Title
 	}
 
-	openAPIPath := a.config.OpenAPIPath
+	openAPIPath := openAPIBasePath(config)
 	if openAPIPath == "" {
 		openAPIPath = "/openapi"
 	}
 
 	htmlContent := generateDocsHTML(openAPIPath+".json", title)
 
-	a.adapter.Handle(&BaseRoute{
+	adapter.Handle(&BaseRoute{
 		Method: http.MethodGet,
-		Path:   a.config.DocsPath,
+		Path:   config.DocsPath,
 	}, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
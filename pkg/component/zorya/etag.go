@@ -0,0 +1,220 @@
+package zorya
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ETagProvider allows output structs to provide the ETag of the resource they
+// represent. Conditional GET requests are then answered before the body is
+// serialized. The value may be quoted or not; it is sent as a strong ETag.
+type ETagProvider interface {
+	ETag() string
+}
+
+// LastModifiedProvider allows output structs to provide the last modification
+// time of the resource they represent, enabling If-Modified-Since handling
+// before the body is serialized.
+type LastModifiedProvider interface {
+	LastModified() time.Time
+}
+
+var (
+	etagProviderType         = reflect.TypeOf((*ETagProvider)(nil)).Elem()
+	lastModifiedProviderType = reflect.TypeOf((*LastModifiedProvider)(nil)).Elem()
+)
+
+// AutoETag enables automatic ETags for a GET or HEAD route. The negotiated,
+// serialized response body is hashed into a strong ETag, and requests whose
+// If-None-Match matches it get a 304 Not Modified without the body. Use
+// Group.UseRouteOptions to enable it for every route of a group.
+//
+//	zorya.Get(api, "/users/{id}", getUser, zorya.AutoETag())
+func AutoETag() func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.ETag = true
+	}
+}
+
+// isConditionalRead reports whether conditional GET handling applies to the request.
+func isConditionalRead(r *http.Request, status int) bool {
+	return status == http.StatusOK && (r.Method == http.MethodGet || r.Method == http.MethodHead)
+}
+
+// writeProviderValidators sets the ETag and Last-Modified headers from an output
+// implementing ETagProvider or LastModifiedProvider. It returns true if the
+// request was answered with 304 Not Modified.
+func writeProviderValidators(r *http.Request, w http.ResponseWriter, output any, status int) bool {
+	etagProvider, hasETag := output.(ETagProvider)
+	lastModifiedProvider, hasLastModified := output.(LastModifiedProvider)
+	if !hasETag && !hasLastModified {
+		return false
+	}
+
+	var etag string
+	if hasETag {
+		if etag = quoteETag(etagProvider.ETag()); etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+	}
+
+	var lastModified time.Time
+	if hasLastModified {
+		if lastModified = lastModifiedProvider.LastModified(); !lastModified.IsZero() {
+			w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+	}
+
+	if !isConditionalRead(r, status) || !notModified(r, etag, lastModified) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+
+	return true
+}
+
+// writeBodyWithETag serializes the body, sets a strong ETag computed from the
+// serialized bytes and answers matching If-None-Match requests with 304.
+func writeBodyWithETag(api API, r *http.Request, w http.ResponseWriter, status int, ct string, body any) {
+	var buf bytes.Buffer
+	api.Marshal(&buf, ct, body)
+
+	etag := computeETag(buf.Bytes())
+	w.Header().Set("ETag", etag)
+	// The ETag depends on the negotiated representation.
+	addVary(w.Header(), "Accept")
+
+	if notModified(r, etag, time.Time{}) {
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", ct)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// computeETag returns a strong ETag for the serialized representation.
+func computeETag(b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// quoteETag wraps an ETag value in quotes unless it is already quoted.
+func quoteETag(etag string) string {
+	if etag == "" || strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+
+	return `"` + etag + `"`
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when no
+// If-None-Match is sent, as described in RFC 9110 section 13.2.2.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etag != "" && etagListMatches(ifNoneMatch, etag)
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// etagListMatches reports whether an If-None-Match list matches the ETag using
+// the weak comparison function.
+func etagListMatches(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// documentConditionalGET adds the 304 response, the validator response headers
+// and the conditional request headers to GET and HEAD operations that use
+// AutoETag or whose output implements ETagProvider or LastModifiedProvider.
+func documentConditionalGET(route *BaseRoute, outputType reflect.Type) {
+	if route.Method != http.MethodGet && route.Method != http.MethodHead {
+		return
+	}
+
+	outputPtr := reflect.PointerTo(outputType)
+	hasETag := route.ETag || outputPtr.Implements(etagProviderType)
+	hasLastModified := outputPtr.Implements(lastModifiedProviderType)
+	if !hasETag && !hasLastModified {
+		return
+	}
+
+	op := route.Operation
+	success := getResponse(op, getDefaultStatus(route))
+	if success.Headers == nil {
+		success.Headers = make(map[string]*Param)
+	}
+	notModifiedResp := getResponse(op, http.StatusNotModified)
+	if notModifiedResp.Headers == nil {
+		notModifiedResp.Headers = make(map[string]*Param)
+	}
+
+	if hasETag {
+		etagHeader := &Param{
+			Description: "Strong entity tag of the returned representation.",
+			Schema:      &Schema{Type: TypeString},
+		}
+		success.Headers["ETag"] = etagHeader
+		notModifiedResp.Headers["ETag"] = etagHeader
+		addHeaderParam(op, "If-None-Match",
+			"Returns 304 Not Modified if the representation's ETag matches one of the listed ETags.")
+	}
+
+	if hasLastModified {
+		lastModifiedHeader := &Param{
+			Description: "Time the resource was last modified.",
+			Schema:      &Schema{Type: TypeString},
+		}
+		success.Headers["Last-Modified"] = lastModifiedHeader
+		notModifiedResp.Headers["Last-Modified"] = lastModifiedHeader
+		addHeaderParam(op, "If-Modified-Since",
+			"Returns 304 Not Modified if the resource has not been modified since the given HTTP date.")
+	}
+}
+
+// addHeaderParam adds a header parameter to the operation unless the input
+// struct already declares it (e.g. by embedding conditional.Params), and
+// returns the operation's parameter.
+func addHeaderParam(op *Operation, name, description string) *Param {
+	for _, p := range op.Parameters {
+		if p.In == "header" && strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+
+	param := &Param{
+		Name:        name,
+		In:          "header",
+		Description: description,
+		Schema:      &Schema{Type: TypeString},
+	}
+	op.Parameters = append(op.Parameters, param)
+
+	return param
+}
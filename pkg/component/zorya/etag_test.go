@@ -0,0 +1,189 @@
+package zorya
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type VersionedUserOutput struct {
+	Body struct {
+		Name string
+	} `body:"structured"`
+	version  string
+	modified time.Time
+}
+
+func (o *VersionedUserOutput) ETag() string {
+	return o.version
+}
+
+func (o *VersionedUserOutput) LastModified() time.Time {
+	return o.modified
+}
+
+func serveWithHeaders(router http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestAutoETag_ConditionalGET(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	calls := 0
+	Get(api, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*GetUserOutput, error) {
+		calls++
+		output := &GetUserOutput{}
+		output.Body.ID = input.ID
+		output.Body.Name = "John Doe"
+
+		return output, nil
+	}, AutoETag())
+
+	recorder := serveWithHeaders(router, http.MethodGet, "/users/1", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, computeETag(recorder.Body.Bytes()), etag)
+	assert.Equal(t, "Accept", recorder.Header().Get("Vary"))
+
+	// The same representation yields the same ETag.
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/1", nil)
+	assert.Equal(t, etag, recorder.Header().Get("ETag"))
+
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/1", map[string]string{"If-None-Match": `"other", ` + etag})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Equal(t, etag, recorder.Header().Get("ETag"))
+	assert.Empty(t, recorder.Body.String())
+	assert.Empty(t, recorder.Header().Get("Content-Type"))
+
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/1", map[string]string{"If-None-Match": "W/" + etag})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/2", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+
+	// Other representations have their own ETag.
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/1", map[string]string{"Accept": "application/cbor", "If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+
+	assert.Equal(t, 6, calls)
+}
+
+func TestAutoETag_VersionedMediaType(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithConfig(DefaultConfig()))
+	versions := NewVersionedGroup(api, VersionByMediaType("acme"))
+	Get(versions.Version("v1"), "/items/1", getItemV1, AutoETag())
+
+	// Both the version negotiation and the ETag vary on Accept.
+	recorder := serveWithHeaders(router, http.MethodGet, "/items/1", map[string]string{"Accept": "application/vnd.acme.v1+json"})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("ETag"))
+	assert.Equal(t, []string{"Accept"}, recorder.Header().Values("Vary"))
+}
+
+func TestAutoETag_Disabled(t *testing.T) {
+	_, router := newNegotiationTestAPI(t, false)
+
+	recorder := serveWithHeaders(router, http.MethodGet, "/users/1", nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("ETag"))
+}
+
+func TestAutoETag_GroupRouteOptions(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	grp := NewGroup(api, "/v1")
+	grp.UseRouteOptions(AutoETag())
+
+	Get(grp, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*GetUserOutput, error) {
+		return &GetUserOutput{}, nil
+	})
+
+	recorder := serveWithHeaders(router, http.MethodGet, "/v1/users/1", nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("ETag"))
+}
+
+func TestETagProvider_ShortCircuit(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	Get(api, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*VersionedUserOutput, error) {
+		output := &VersionedUserOutput{version: "v7", modified: modified}
+		output.Body.Name = "John Doe"
+
+		return output, nil
+	}, AutoETag())
+
+	recorder := serveWithHeaders(router, http.MethodGet, "/users/1", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `"v7"`, recorder.Header().Get("ETag"))
+	assert.Equal(t, "Fri, 02 Jan 2026 03:04:05 GMT", recorder.Header().Get("Last-Modified"))
+	assert.JSONEq(t, `{"Name":"John Doe"}`, recorder.Body.String())
+
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/1", map[string]string{"If-None-Match": `"v7"`})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/1", map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/1", map[string]string{"If-Modified-Since": "Thu, 01 Jan 2026 00:00:00 GMT"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// If-None-Match takes precedence over If-Modified-Since.
+	recorder = serveWithHeaders(router, http.MethodGet, "/users/1", map[string]string{
+		"If-None-Match":     `"v6"`,
+		"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT",
+	})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestAutoETag_OpenAPI(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*GetUserOutput, error) {
+		return &GetUserOutput{}, nil
+	}, AutoETag())
+	Get(api, "/versioned/{id}", func(ctx context.Context, input *GetUserInput) (*VersionedUserOutput, error) {
+		return &VersionedUserOutput{}, nil
+	})
+
+	op := api.OpenAPI().Paths["/users/{id}"].Get
+	require.Contains(t, op.Responses, "304")
+	assert.Contains(t, op.Responses["304"].Headers, "ETag")
+	assert.Contains(t, op.Responses["200"].Headers, "ETag")
+	assert.NotContains(t, op.Responses["200"].Headers, "Last-Modified")
+
+	paramNames := make([]string, 0, len(op.Parameters))
+	for _, p := range op.Parameters {
+		paramNames = append(paramNames, p.In+":"+p.Name)
+	}
+	assert.Equal(t, []string{"path:id", "header:If-None-Match"}, paramNames)
+
+	op = api.OpenAPI().Paths["/versioned/{id}"].Get
+	require.Contains(t, op.Responses, "304")
+	assert.Contains(t, op.Responses["200"].Headers, "Last-Modified")
+	assert.Len(t, op.Parameters, 3)
+}
@@ -0,0 +1,480 @@
+package zorya
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/talav/talav/pkg/component/tagparser"
+)
+
+// FilterOperator is a comparison operator of filter query parameters.
+type FilterOperator string
+
+// Filter operators.
+const (
+	FilterEq       FilterOperator = "eq"       // equal
+	FilterNe       FilterOperator = "ne"       // not equal
+	FilterGt       FilterOperator = "gt"       // greater than
+	FilterGte      FilterOperator = "gte"      // greater than or equal
+	FilterLt       FilterOperator = "lt"       // less than
+	FilterLte      FilterOperator = "lte"      // less than or equal
+	FilterIn       FilterOperator = "in"       // equal to one of a comma-separated list
+	FilterContains FilterOperator = "contains" // case-insensitive substring, string fields only
+	FilterPrefix   FilterOperator = "prefix"   // case-insensitive prefix, string fields only
+)
+
+// filterOperators lists the operators in documentation order.
+var filterOperators = []FilterOperator{
+	FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterIn, FilterContains, FilterPrefix,
+}
+
+// FilterInput is an input mixin for filtered and sorted list operations. The
+// fields of F whitelist what clients can filter and sort by with the filter
+// tag, whose name is the field name in queries, followed by options:
+//   - ops=eq|contains - Operators allowed in filter[name][op]=value
+//   - sort - The field can be used in sort=name or sort=-name (descending)
+//   - column=created_at - Database column of the field (default: the name)
+//
+// The field types give the value types, e.g. int, bool, time.Time (RFC 3339)
+// or any encoding.TextUnmarshaler.
+//
+//	type UserFilter struct {
+//		Email     string    `filter:"email,ops=eq|contains,sort"`
+//		CreatedAt time.Time `filter:"created_at,ops=gt|lt,sort"`
+//	}
+//
+//	type ListUsersInput struct {
+//		zorya.PageInput
+//		zorya.FilterInput[UserFilter]
+//	}
+//
+// Requests filtering or sorting by other fields or with other operators are
+// rejected with a 400 problem before the handler runs. Handlers read the
+// conditions from FilterSpec.
+type FilterInput[F any] struct {
+	// Filter holds the raw filter conditions.
+	Filter Filter[F] `schema:"filter,location=query,style=deepObject" openapi:"description=Filter conditions in the form filter[field][operator]."`
+
+	// Sort holds the raw sort fields.
+	Sort Sort[F] `schema:"sort,location=query,explode=false" openapi:"description=Fields to sort by separated by commas. A leading - sorts in descending order."`
+
+	spec FilterSpec
+}
+
+// FilterSpec returns the conditions and sort order of the request.
+func (in *FilterInput[F]) FilterSpec() FilterSpec {
+	return in.spec
+}
+
+// filterResolver is implemented by inputs embedding FilterInput.
+type filterResolver interface {
+	resolveFilter() error
+}
+
+func (in *FilterInput[F]) resolveFilter() error {
+	fields, err := filterFieldsFor(reflect.TypeFor[F]())
+	if err != nil {
+		return err
+	}
+
+	spec, details := fields.parse(in.Filter, in.Sort)
+	if len(details) > 0 {
+		return Error400BadRequest("invalid filter", details...)
+	}
+	in.spec = spec
+
+	return nil
+}
+
+// Filter is the filter query parameter of a FilterInput, documented with the
+// fields and operators allowed by F.
+type Filter[F any] map[string]any
+
+// Schema returns an object with a property per filterable field, holding a
+// property per allowed operator.
+func (Filter[F]) Schema(r Registry) *Schema {
+	fields := mustFilterFields[F]()
+
+	s := &Schema{Type: TypeObject, Properties: make(map[string]*Schema), AdditionalProperties: false}
+	for _, field := range fields.fields {
+		if len(field.operators) == 0 {
+			continue
+		}
+
+		names := make([]string, len(field.operators))
+		operators := &Schema{Type: TypeObject, Properties: make(map[string]*Schema), AdditionalProperties: false}
+		for i, op := range field.operators {
+			names[i] = string(op)
+			value := r.Schema(field.typ, true, "")
+			if op == FilterIn {
+				value = &Schema{Type: TypeArray, Items: value}
+			}
+			operators.Properties[string(op)] = value
+		}
+		operators.Description = "Operators: " + strings.Join(names, ", ") + "."
+
+		s.Properties[field.name] = operators
+	}
+
+	return s
+}
+
+// Sort is the sort query parameter of a FilterInput, documented with the
+// fields F allows sorting by.
+type Sort[F any] []string
+
+// Schema returns an array of the sortable fields, each optionally prefixed
+// with - for descending order.
+func (Sort[F]) Schema(r Registry) *Schema {
+	fields := mustFilterFields[F]()
+
+	var enum []any
+	for _, field := range fields.fields {
+		if field.sortable {
+			enum = append(enum, field.name, "-"+field.name)
+		}
+	}
+
+	return &Schema{Type: TypeArray, Items: &Schema{Type: TypeString, Enum: enum}}
+}
+
+// FilterSpec is the typed specification decoded from a FilterInput.
+type FilterSpec struct {
+	// Conditions are the filter conditions, all of which must match.
+	Conditions []FilterCondition
+
+	// Sort is the sort order, most significant field first.
+	Sort []SortField
+}
+
+// FilterCondition is a condition of a FilterSpec.
+type FilterCondition struct {
+	// Field is the field name used in the query.
+	Field string
+
+	// Column is the database column of the field.
+	Column string
+
+	// Operator is the comparison operator.
+	Operator FilterOperator
+
+	// Value is the value converted to the field type, or a slice of them for FilterIn.
+	Value any
+}
+
+// SortField is a field of the sort order of a FilterSpec.
+type SortField struct {
+	// Field is the field name used in the query.
+	Field string
+
+	// Column is the database column of the field.
+	Column string
+
+	// Desc sorts in descending order.
+	Desc bool
+}
+
+// filterField is a field whitelisted by a filter struct.
+type filterField struct {
+	name      string
+	column    string
+	typ       reflect.Type
+	operators []FilterOperator
+	sortable  bool
+}
+
+// filterFields are the whitelisted fields of a filter struct.
+type filterFields struct {
+	fields []*filterField
+	byName map[string]*filterField
+}
+
+var filterFieldsCache sync.Map
+
+// mustFilterFields returns the fields of filter struct F, panicking on invalid
+// tags since schemas are generated while registering routes.
+func mustFilterFields[F any]() *filterFields {
+	fields, err := filterFieldsFor(reflect.TypeFor[F]())
+	if err != nil {
+		panic(err)
+	}
+
+	return fields
+}
+
+// filterFieldsFor returns the whitelisted fields of a filter struct type.
+func filterFieldsFor(t reflect.Type) (*filterFields, error) {
+	if cached, ok := filterFieldsCache.Load(t); ok {
+		return cached.(*filterFields), nil
+	}
+
+	fields, err := parseFilterFields(t)
+	if err != nil {
+		return nil, fmt.Errorf("zorya: filter %s: %w", t, err)
+	}
+	filterFieldsCache.Store(t, fields)
+
+	return fields, nil
+}
+
+// parseFilterFields parses the filter tags of a struct type.
+func parseFilterFields(t reflect.Type) (*filterFields, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("filter type must be a struct")
+	}
+
+	result := &filterFields{byName: make(map[string]*filterField)}
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		tagValue, ok := structField.Tag.Lookup("filter")
+		if !ok || !structField.IsExported() {
+			continue
+		}
+
+		tag, err := tagparser.ParseWithName(tagValue)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: failed to parse filter tag: %w", structField.Name, err)
+		}
+
+		field := &filterField{name: tag.Name, column: tag.Options["column"], typ: deref(structField.Type)}
+		if field.name == "" {
+			field.name = structField.Name
+		}
+		if field.column == "" {
+			field.column = field.name
+		}
+		_, field.sortable = tag.Options["sort"]
+
+		if !isFilterValueType(field.typ) {
+			return nil, fmt.Errorf("field %s: unsupported filter type %s", structField.Name, field.typ)
+		}
+
+		if ops := tag.Options["ops"]; ops != "" {
+			for op := range strings.SplitSeq(ops, "|") {
+				operator := FilterOperator(strings.TrimSpace(op))
+				if !slices.Contains(filterOperators, operator) {
+					return nil, fmt.Errorf("field %s: unknown filter operator %q", structField.Name, op)
+				}
+				if (operator == FilterContains || operator == FilterPrefix) && field.typ.Kind() != reflect.String {
+					return nil, fmt.Errorf("field %s: operator %s requires a string field", structField.Name, operator)
+				}
+				field.operators = append(field.operators, operator)
+			}
+		}
+
+		if _, ok := result.byName[field.name]; ok {
+			return nil, fmt.Errorf("field %s: duplicate filter field %q", structField.Name, field.name)
+		}
+		result.fields = append(result.fields, field)
+		result.byName[field.name] = field
+	}
+
+	return result, nil
+}
+
+// parse converts raw filter and sort parameters into a FilterSpec, returning
+// the details of invalid fields, operators and values.
+func (f *filterFields) parse(filter map[string]any, sortFields []string) (FilterSpec, []error) {
+	var spec FilterSpec
+	var details []error
+
+	names := make([]string, 0, len(filter))
+	for name := range filter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		location := "query.filter[" + name + "]"
+		field, ok := f.byName[name]
+		if !ok || len(field.operators) == 0 {
+			details = append(details, &ErrorDetail{
+				Code:     "unknown_filter_field",
+				Message:  fmt.Sprintf("cannot filter by %q", name),
+				Location: location,
+			})
+
+			continue
+		}
+
+		operators, ok := filter[name].(map[string]any)
+		if !ok {
+			details = append(details, &ErrorDetail{
+				Code:     "invalid_filter",
+				Message:  fmt.Sprintf("filter on %q requires an operator, e.g. filter[%s][%s]", name, name, field.operators[0]),
+				Location: location,
+			})
+
+			continue
+		}
+
+		ops := make([]string, 0, len(operators))
+		for op := range operators {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+
+		for _, op := range ops {
+			operator := FilterOperator(op)
+			opLocation := location + "[" + op + "]"
+			if !slices.Contains(field.operators, operator) {
+				details = append(details, &ErrorDetail{
+					Code:     "unsupported_filter_operator",
+					Message:  fmt.Sprintf("operator %q is not supported on %q", op, name),
+					Location: opLocation,
+				})
+
+				continue
+			}
+
+			value, err := field.value(operator, operators[op])
+			if err != nil {
+				details = append(details, &ErrorDetail{
+					Code:     "invalid_filter_value",
+					Message:  err.Error(),
+					Location: opLocation,
+				})
+
+				continue
+			}
+
+			spec.Conditions = append(spec.Conditions, FilterCondition{
+				Field:    field.name,
+				Column:   field.column,
+				Operator: operator,
+				Value:    value,
+			})
+		}
+	}
+
+	for _, raw := range sortFields {
+		name := strings.TrimSpace(raw)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := f.byName[name]
+		if !ok || !field.sortable {
+			details = append(details, &ErrorDetail{
+				Code:     "unknown_sort_field",
+				Message:  fmt.Sprintf("cannot sort by %q", name),
+				Location: "query.sort",
+			})
+
+			continue
+		}
+
+		spec.Sort = append(spec.Sort, SortField{Field: field.name, Column: field.column, Desc: desc})
+	}
+
+	return spec, details
+}
+
+// value converts the raw value of a condition to the field type.
+func (f *filterField) value(operator FilterOperator, raw any) (any, error) {
+	if operator == FilterIn {
+		var items []string
+		switch v := raw.(type) {
+		case string:
+			items = strings.Split(v, ",")
+		case []any:
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+		}
+
+		values := make([]any, len(items))
+		for i, item := range items {
+			value, err := parseFilterValue(f.typ, item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for %q: %w", item, f.name, err)
+			}
+			values[i] = value
+		}
+
+		return values, nil
+	}
+
+	item, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("operator %q on %q takes a single value", operator, f.name)
+	}
+
+	value, err := parseFilterValue(f.typ, item)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for %q: %w", item, f.name, err)
+	}
+
+	return value, nil
+}
+
+// isFilterValueType reports whether filter values can be parsed into t.
+func isFilterValueType(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	//nolint:exhaustive // Other kinds are not supported
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseFilterValue parses a query value into a value of type t.
+func parseFilterValue(t reflect.Type, raw string) (any, error) {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return nil, err
+		}
+
+		return v.Elem().Interface(), nil
+	}
+
+	v := reflect.New(t).Elem()
+
+	//nolint:exhaustive // Unsupported kinds are rejected by isFilterValueType
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("must be a non-negative integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		v.SetFloat(n)
+	default:
+		return nil, fmt.Errorf("unsupported filter type %s", t)
+	}
+
+	return v.Interface(), nil
+}
@@ -0,0 +1,185 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type UserFilter struct {
+	Email     string    `filter:"email,ops=eq|contains,sort"`
+	Age       int       `filter:"age,ops=gte|lte|in"`
+	Active    bool      `filter:"active,ops=eq"`
+	CreatedAt time.Time `filter:"createdAt,ops=gt|lt,sort,column=created_at"`
+	Name      string    `filter:"name,sort"`
+	Internal  string
+}
+
+type ListFilteredUsersInput struct {
+	PageInput
+	FilterInput[UserFilter]
+}
+
+type ListFilteredUsersOutput struct {
+	Body struct {
+		Spec FilterSpec
+	} `body:"structured"`
+}
+
+func newFilterTestAPI(t *testing.T) (*chi.Mux, API) {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/users", func(ctx context.Context, input *ListFilteredUsersInput) (*ListFilteredUsersOutput, error) {
+		output := &ListFilteredUsersOutput{}
+		output.Body.Spec = input.FilterSpec()
+
+		return output, nil
+	})
+
+	return router, api
+}
+
+func TestFilterInput_Spec(t *testing.T) {
+	router, _ := newFilterTestAPI(t)
+
+	var spec FilterSpec
+	handler := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	recorder := handler("/users?filter[email][contains]=doe&filter[age][gte]=18&filter[age][in]=20,30" +
+		"&filter[active][eq]=true&filter[createdAt][gt]=2024-01-02T15:04:05Z&sort=-createdAt,email&limit=5")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var body struct{ Spec FilterSpec }
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	spec = body.Spec
+
+	assert.Equal(t, []FilterCondition{
+		{Field: "active", Column: "active", Operator: FilterEq, Value: true},
+		{Field: "age", Column: "age", Operator: FilterGte, Value: float64(18)},
+		{Field: "age", Column: "age", Operator: FilterIn, Value: []any{float64(20), float64(30)}},
+		{Field: "createdAt", Column: "created_at", Operator: FilterGt, Value: "2024-01-02T15:04:05Z"},
+		{Field: "email", Column: "email", Operator: FilterContains, Value: "doe"},
+	}, spec.Conditions)
+	assert.Equal(t, []SortField{
+		{Field: "createdAt", Column: "created_at", Desc: true},
+		{Field: "email", Column: "email"},
+	}, spec.Sort)
+
+	// Without parameters the spec is empty.
+	recorder = handler("/users?sort=name")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Empty(t, body.Spec.Conditions)
+	assert.Equal(t, []SortField{{Field: "name", Column: "name"}}, body.Spec.Sort)
+}
+
+func TestFilterInput_TypedValues(t *testing.T) {
+	fields, err := filterFieldsFor(reflect.TypeFor[UserFilter]())
+	require.NoError(t, err)
+
+	spec, details := fields.parse(map[string]any{
+		"age":       map[string]any{"in": []any{"1", "2"}},
+		"createdAt": map[string]any{"lt": "2024-01-02T15:04:05Z"},
+	}, nil)
+	require.Empty(t, details)
+
+	assert.Equal(t, []any{1, 2}, spec.Conditions[0].Value)
+	assert.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), spec.Conditions[1].Value)
+}
+
+func TestFilterInput_Errors(t *testing.T) {
+	router, _ := newFilterTestAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/users?filter[password][eq]=x&filter[email][gt]=a"+
+		"&filter[age][gte]=old&filter[active]=true&filter[name][eq]=x&sort=internal", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "invalid filter",
+		"errors": [
+			{"code": "invalid_filter", "message": "filter on \"active\" requires an operator, e.g. filter[active][eq]", "location": "query.filter[active]"},
+			{"code": "invalid_filter_value", "message": "invalid value \"old\" for \"age\": must be an integer", "location": "query.filter[age][gte]"},
+			{"code": "unsupported_filter_operator", "message": "operator \"gt\" is not supported on \"email\"", "location": "query.filter[email][gt]"},
+			{"code": "unknown_filter_field", "message": "cannot filter by \"name\"", "location": "query.filter[name]"},
+			{"code": "unknown_filter_field", "message": "cannot filter by \"password\"", "location": "query.filter[password]"},
+			{"code": "unknown_sort_field", "message": "cannot sort by \"internal\"", "location": "query.sort"}
+		]
+	}`, recorder.Body.String())
+}
+
+func TestFilterInput_OpenAPI(t *testing.T) {
+	_, api := newFilterTestAPI(t)
+
+	data, err := json.Marshal(api.OpenAPI().Paths["/users"].Get.Parameters)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `[
+		{"name": "cursor", "in": "query", "style": "form", "explode": true, "description": "Opaque cursor of the page to return from the Link header or the next_cursor and prev_cursor fields of a previous page.", "schema": {"type": "string"}},
+		{"name": "limit", "in": "query", "style": "form", "explode": true, "description": "Maximum number of items to return.", "schema": {"type": "integer", "format": "int64", "default": 20, "minimum": 1, "maximum": 100}},
+		{
+			"name": "filter", "in": "query", "style": "deepObject", "explode": true,
+			"description": "Filter conditions in the form filter[field][operator].",
+			"schema": {
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"email": {"type": "object", "additionalProperties": false, "description": "Operators: eq, contains.",
+						"properties": {"eq": {"type": "string"}, "contains": {"type": "string"}}},
+					"age": {"type": "object", "additionalProperties": false, "description": "Operators: gte, lte, in.",
+						"properties": {"gte": {"type": "integer", "format": "int64"}, "lte": {"type": "integer", "format": "int64"}, "in": {"type": "array", "items": {"type": "integer", "format": "int64"}}}},
+					"active": {"type": "object", "additionalProperties": false, "description": "Operators: eq.",
+						"properties": {"eq": {"type": "boolean"}}},
+					"createdAt": {"type": "object", "additionalProperties": false, "description": "Operators: gt, lt.",
+						"properties": {"gt": {"type": "string"}, "lt": {"type": "string"}}}
+				}
+			}
+		},
+		{
+			"name": "sort", "in": "query", "style": "form", "explode": false,
+			"description": "Fields to sort by separated by commas. A leading - sorts in descending order.",
+			"schema": {"type": "array", "items": {"type": "string", "enum": ["email", "-email", "createdAt", "-createdAt", "name", "-name"]}}
+		}
+	]`, string(data))
+}
+
+func TestFilterInput_InvalidFilterTypes(t *testing.T) {
+	type unknownOperator struct {
+		Email string `filter:"email,ops=like"`
+	}
+	type containsOnInt struct {
+		Age int `filter:"age,ops=contains"`
+	}
+	type unsupportedType struct {
+		Tags []string `filter:"tags,ops=eq"`
+	}
+
+	_, err := parseFilterFields(reflect.TypeFor[unknownOperator]())
+	require.EqualError(t, err, `field Email: unknown filter operator "like"`)
+
+	_, err = parseFilterFields(reflect.TypeFor[containsOnInt]())
+	require.EqualError(t, err, "field Age: operator contains requires a string field")
+
+	_, err = parseFilterFields(reflect.TypeFor[unsupportedType]())
+	require.EqualError(t, err, "field Tags: unsupported filter type []string")
+}
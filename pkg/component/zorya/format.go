@@ -3,13 +3,20 @@ package zorya
 import (
 	"encoding/json"
 	"io"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/fxamacker/cbor/v2"
+
+	"github.com/talav/talav/pkg/component/schema"
 )
 
-// Format defines a marshal function for a content type.
+// Format defines marshal and unmarshal functions for a content type.
+// Unmarshal is optional; formats without it are only used for responses.
 type Format struct {
-	Marshal func(w io.Writer, v any) error
+	Marshal   func(w io.Writer, v any) error
+	Unmarshal func(data []byte, v any) error
 }
 
 // JSONFormat returns a Format for application/json.
@@ -21,6 +28,7 @@ func JSONFormat() Format {
 
 			return enc.Encode(v)
 		},
+		Unmarshal: json.Unmarshal,
 	}
 }
 
@@ -30,10 +38,16 @@ func CBORFormat() Format {
 		Time: cbor.TimeRFC3339,
 	}.EncMode()
 
+	// Decode maps with string keys so request bodies match what JSON produces.
+	decMode, _ := cbor.DecOptions{
+		DefaultMapType: reflect.TypeFor[map[string]any](),
+	}.DecMode()
+
 	return Format{
 		Marshal: func(w io.Writer, v any) error {
 			return encMode.NewEncoder(w).Encode(v)
 		},
+		Unmarshal: decMode.Unmarshal,
 	}
 }
 
@@ -49,3 +63,29 @@ func DefaultFormats() map[string]Format {
 		"cbor":             cborFmt, // For +cbor suffix matching
 	}
 }
+
+// bodyUnmarshalers returns the request body unmarshalers of formats that can decode.
+func bodyUnmarshalers(formats map[string]Format) map[string]schema.BodyUnmarshaler {
+	unmarshalers := make(map[string]schema.BodyUnmarshaler, len(formats))
+	for contentType, format := range formats {
+		if format.Unmarshal != nil {
+			unmarshalers[contentType] = format.Unmarshal
+		}
+	}
+
+	return unmarshalers
+}
+
+// decodableContentTypes returns the sorted full media types of formats that can decode
+// request bodies, leaving out suffix-only keys such as "json".
+func decodableContentTypes(formats map[string]Format) []string {
+	contentTypes := make([]string, 0, len(formats))
+	for contentType, format := range formats {
+		if format.Unmarshal != nil && strings.Contains(contentType, "/") {
+			contentTypes = append(contentTypes, contentType)
+		}
+	}
+	slices.Sort(contentTypes)
+
+	return contentTypes
+}
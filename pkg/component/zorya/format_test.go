@@ -0,0 +1,131 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CreateNoteInput struct {
+	Body struct {
+		Title string
+		Tags  []string
+		Stars int
+	} `body:"structured"`
+}
+
+type CreateNoteOutput struct {
+	Body struct {
+		Title string
+		Tags  []string
+		Stars int
+	} `body:"structured"`
+}
+
+func newFormatTestAPI(t *testing.T, opts ...Option) (API, *chi.Mux) {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, opts...)
+
+	Post(api, "/notes", func(ctx context.Context, input *CreateNoteInput) (*CreateNoteOutput, error) {
+		output := &CreateNoteOutput{}
+		output.Body.Title = input.Body.Title
+		output.Body.Tags = input.Body.Tags
+		output.Body.Stars = input.Body.Stars
+
+		return output, nil
+	})
+
+	return api, router
+}
+
+func postNote(router http.Handler, contentType string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/notes", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestFormat_DecodeCBORBody(t *testing.T) {
+	_, router := newFormatTestAPI(t)
+
+	body, err := cbor.Marshal(map[string]any{"Title": "Groceries", "Tags": []string{"home"}, "Stars": 3})
+	require.NoError(t, err)
+
+	recorder := postNote(router, "application/cbor", body)
+
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"Title":"Groceries","Tags":["home"],"Stars":3}`, recorder.Body.String())
+}
+
+func TestFormat_DecodeSuffixBody(t *testing.T) {
+	_, router := newFormatTestAPI(t)
+
+	recorder := postNote(router, "application/vnd.notes+json", []byte(`{"Title":"Groceries","Stars":3}`))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"Title":"Groceries","Tags":null,"Stars":3}`, recorder.Body.String())
+
+	body, err := cbor.Marshal(map[string]any{"Title": "Groceries"})
+	require.NoError(t, err)
+
+	recorder = postNote(router, "application/vnd.notes+cbor", body)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestFormat_DecodeCustomFormatBody(t *testing.T) {
+	// A toy format where the body is the note title only.
+	titleFormat := Format{
+		Marshal: JSONFormat().Marshal,
+		Unmarshal: func(data []byte, v any) error {
+			return json.Unmarshal([]byte(`{"Title":"`+strings.TrimSpace(string(data))+`"}`), v)
+		},
+	}
+	api, router := newFormatTestAPI(t, WithFormat("text/x-title", titleFormat))
+
+	recorder := postNote(router, "text/x-title", []byte("Groceries\n"))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"Title":"Groceries","Tags":null,"Stars":0}`, recorder.Body.String())
+
+	content := api.OpenAPI().Paths["/notes"].Post.RequestBody.Content
+	assert.Contains(t, content, "application/json")
+	assert.Contains(t, content, "application/cbor")
+	assert.Contains(t, content, "text/x-title")
+}
+
+func TestFormat_UnsupportedMediaType(t *testing.T) {
+	_, router := newFormatTestAPI(t)
+
+	recorder := postNote(router, "text/plain", []byte(`{"Title":"Groceries"}`))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+	var problem ErrorModel
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusUnsupportedMediaType, problem.Status)
+	assert.Equal(t, `unsupported media type "text/plain"`, problem.Detail)
+}
+
+func TestFormat_ResponseOnlyFormat(t *testing.T) {
+	// Formats without Unmarshal are not accepted as request bodies.
+	_, router := newFormatTestAPI(t, WithFormat("application/cbor", Format{Marshal: CBORFormat().Marshal}))
+
+	body, err := cbor.Marshal(map[string]any{"Title": "Groceries"})
+	require.NoError(t, err)
+
+	recorder := postNote(router, "application/cbor", body)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+}
@@ -15,9 +15,16 @@ type Group struct {
 	modifiers    []func(o *BaseRoute, next func(*BaseRoute))
 	middlewares  Middlewares
 	transformers []Transformer
+	routeOptions []func(*BaseRoute)
 	security     *RouteSecurity
 }
 
+// routeOptionsApplier is implemented by APIs that apply route options to every
+// route registered through them, such as groups.
+type routeOptionsApplier interface {
+	applyRouteOptions(route *BaseRoute)
+}
+
 // groupAdapter is an Adapter wrapper that registers multiple operation handlers
 // with the underlying adapter based on the group's prefixes.
 type groupAdapter struct {
@@ -136,6 +143,24 @@ func (g *Group) UseSimpleModifier(modifier func(o *BaseRoute)) {
 	})
 }
 
+// UseRouteOptions adds route options, such as AutoETag, that are applied to
+// every route registered in the group. Unlike modifiers, they run before the
+// route's OpenAPI operation and handler are built, after the route's own options.
+func (g *Group) UseRouteOptions(options ...func(*BaseRoute)) {
+	g.routeOptions = append(g.routeOptions, options...)
+}
+
+// applyRouteOptions applies the route options of parent groups, then this group's.
+func (g *Group) applyRouteOptions(route *BaseRoute) {
+	if parent, ok := g.API.(routeOptionsApplier); ok {
+		parent.applyRouteOptions(route)
+	}
+
+	for _, option := range g.routeOptions {
+		option(route)
+	}
+}
+
 // UseMiddleware adds one or more standard Go middleware functions to the group.
 // Middleware functions take an http.Handler and return an http.Handler.
 func (g *Group) UseMiddleware(middlewares ...Middleware) {
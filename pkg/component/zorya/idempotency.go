@@ -0,0 +1,324 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// HeaderIdempotencyKey is the request header carrying the idempotency key.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed is set on responses replayed from an IdempotencyStore.
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+// DefaultIdempotencyTTL is how long responses are kept for replay by default.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyRecord is the stored state of a request sent with an idempotency key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request payload the key was first used with.
+	Fingerprint string
+
+	// Completed is false while the first request is still being processed.
+	Completed bool
+
+	// Status, Header and Body hold the recorded response once completed.
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyStore stores the responses of requests sent with an idempotency key.
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Reserve atomically records an in-flight request for key, unless the key
+	// is already stored. It returns true if the key was reserved; otherwise it
+	// returns the stored record.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error)
+
+	// Complete stores the response of a reserved request.
+	Complete(ctx context.Context, key string, record *IdempotencyRecord) error
+
+	// Release removes a reservation so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// RouteIdempotency configures Idempotency-Key handling for a route.
+type RouteIdempotency struct {
+	// Store holds reservations and recorded responses.
+	Store IdempotencyStore
+
+	// TTL is how long responses are kept. Defaults to DefaultIdempotencyTTL.
+	TTL time.Duration
+
+	// Required rejects requests without an Idempotency-Key header with 400.
+	Required bool
+
+	// Scope returns the caller keys are scoped to. Defaults to
+	// IdempotencyByCredentials.
+	Scope IdempotencyScopeFunc
+}
+
+// IdempotencyScopeFunc returns the caller sending a request, such as the
+// authenticated user ID. Keys are scoped to the caller, so callers sending the
+// same key do not share a record.
+type IdempotencyScopeFunc func(r *http.Request) string
+
+// IdempotencyOption configures Idempotency-Key handling for a route.
+type IdempotencyOption func(*RouteIdempotency)
+
+// IdempotencyTTL sets how long responses are kept for replay.
+func IdempotencyTTL(ttl time.Duration) IdempotencyOption {
+	return func(i *RouteIdempotency) {
+		i.TTL = ttl
+	}
+}
+
+// IdempotencyKeyRequired rejects requests without an Idempotency-Key header.
+func IdempotencyKeyRequired() IdempotencyOption {
+	return func(i *RouteIdempotency) {
+		i.Required = true
+	}
+}
+
+// IdempotencyScope sets the function returning the caller keys are scoped to.
+func IdempotencyScope(scope IdempotencyScopeFunc) IdempotencyOption {
+	return func(i *RouteIdempotency) {
+		i.Scope = scope
+	}
+}
+
+// IdempotencyByCredentials returns a scope function identifying callers by
+// the credentials they send: the Authorization and Cookie headers, and the
+// given headers, such as an API key header.
+//
+//	zorya.IdempotencyScope(zorya.IdempotencyByCredentials("X-API-Key"))
+func IdempotencyByCredentials(headers ...string) IdempotencyScopeFunc {
+	return credentialsScope(headers...)
+}
+
+// credentialsScope returns a function identifying callers by a hash of their
+// Authorization and Cookie headers and the given headers.
+func credentialsScope(headers ...string) func(r *http.Request) string {
+	headers = append([]string{"Authorization", "Cookie"}, headers...)
+
+	return func(r *http.Request) string {
+		h := sha256.New()
+		for _, name := range headers {
+			for _, value := range r.Header.Values(name) {
+				h.Write([]byte(value))
+				h.Write([]byte{0})
+			}
+			h.Write([]byte{1})
+		}
+
+		return "credentials:" + hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// IdempotencyKey makes a route honor the Idempotency-Key request header.
+//
+// The first request with a key is processed and its response is recorded in
+// the store. Retries with the same key and payload get the recorded response
+// with the Idempotent-Replayed header, without running the handler again.
+// Reusing a key with a different payload returns 422, and retrying while the
+// first request is still in flight returns 409. Server errors (5xx) are not
+// recorded, so such requests can be retried.
+//
+// Keys are scoped to the caller, identified by its credentials unless
+// IdempotencyScope sets another scope function, so a key used by one client
+// cannot replay its response to another. The payload fingerprint covers the
+// method, URL, Content-Type, Authorization header and body.
+//
+//	zorya.Post(api, "/media", createMedia,
+//		zorya.IdempotencyKey(store, zorya.IdempotencyTTL(time.Hour)),
+//	)
+func IdempotencyKey(store IdempotencyStore, opts ...IdempotencyOption) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.Idempotency = &RouteIdempotency{Store: store, TTL: DefaultIdempotencyTTL, Scope: IdempotencyByCredentials()}
+		for _, opt := range opts {
+			opt(r.Idempotency)
+		}
+	}
+}
+
+// newIdempotencyMiddleware returns the middleware replaying recorded responses
+// for the route, or nil if the route does not use IdempotencyKey.
+func newIdempotencyMiddleware(api API, route *BaseRoute) Middleware {
+	cfg := route.Idempotency
+	if cfg == nil {
+		return nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				if cfg.Required {
+					WriteErr(api, r, w, 0, "", Error400BadRequest("missing "+HeaderIdempotencyKey+" header"))
+
+					return
+				}
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			setupRequestLimits(r, w, *route)
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				WriteErr(api, r, w, 0, "", err)
+
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := route.Method + " " + route.Path + " " + cfg.Scope(r) + " " + key
+			fingerprint := idempotencyFingerprint(r, body)
+
+			record, reserved, err := cfg.Store.Reserve(r.Context(), storeKey, fingerprint, cfg.TTL)
+			if err != nil {
+				WriteErr(api, r, w, http.StatusInternalServerError, "failed to reserve idempotency key", err)
+
+				return
+			}
+
+			if !reserved {
+				replayIdempotentResponse(api, r, w, record, fingerprint)
+
+				return
+			}
+
+			// Headers set before the handler, such as RateLimit headers,
+			// describe this request and are not recorded.
+			outer := w.Header().Clone()
+			recorder := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			// Keep working when the client goes away, so the reservation is
+			// always completed or released.
+			ctx := context.WithoutCancel(r.Context())
+			defer func() {
+				if !completed {
+					_ = cfg.Store.Release(ctx, storeKey)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status >= http.StatusInternalServerError {
+				return
+			}
+
+			completed = cfg.Store.Complete(ctx, storeKey, &IdempotencyRecord{
+				Fingerprint: fingerprint,
+				Completed:   true,
+				Status:      recorder.status,
+				Header:      handlerHeader(outer, recorder.Header()),
+				Body:        recorder.body.Bytes(),
+			}) == nil
+		})
+	}
+}
+
+// replayIdempotentResponse answers a request whose key is already stored.
+func replayIdempotentResponse(api API, r *http.Request, w http.ResponseWriter, record *IdempotencyRecord, fingerprint string) {
+	switch {
+	case record.Fingerprint != fingerprint:
+		WriteErr(api, r, w, 0, "", Error422UnprocessableEntity(
+			HeaderIdempotencyKey+" has already been used with a different request payload",
+		))
+	case !record.Completed:
+		WriteErr(api, r, w, 0, "", Error409Conflict(
+			"a request with this "+HeaderIdempotencyKey+" is still being processed",
+		))
+	default:
+		maps.Copy(w.Header(), record.Header)
+		w.Header().Set(HeaderIdempotentReplayed, "true")
+		w.WriteHeader(record.Status)
+		_, _ = w.Write(record.Body)
+	}
+}
+
+// handlerHeader returns the headers of header that were added or changed
+// since outer was cloned from it.
+func handlerHeader(outer, header http.Header) http.Header {
+	handler := make(http.Header)
+	for name, values := range header {
+		if !slices.Equal(outer[name], values) {
+			handler[name] = slices.Clone(values)
+		}
+	}
+
+	return handler
+}
+
+// idempotencyFingerprint hashes the parts of a request that identify its payload.
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	for _, part := range []string{
+		r.Method,
+		r.URL.RequestURI(),
+		r.Header.Get("Content-Type"),
+		r.Header.Get("Authorization"),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyRecorder records the status and body of a response while writing it.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *idempotencyRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *idempotencyRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// documentIdempotency documents the Idempotency-Key header and the 409 and
+// 422 responses of a route using IdempotencyKey.
+func documentIdempotency(route *BaseRoute) {
+	if route.Idempotency == nil {
+		return
+	}
+
+	param := addHeaderParam(route.Operation, HeaderIdempotencyKey,
+		"Unique key making retries of this request safe. Retries with the same key and payload "+
+			"replay the first response; reusing the key with a different payload returns 422, and "+
+			"retrying while the first request is in progress returns 409.")
+	param.Required = route.Idempotency.Required
+
+	route.Errors = append(route.Errors, http.StatusConflict, http.StatusUnprocessableEntity)
+	if route.Idempotency.Required {
+		route.Errors = append(route.Errors, http.StatusBadRequest)
+	}
+}
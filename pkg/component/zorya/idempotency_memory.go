@@ -0,0 +1,96 @@
+package zorya
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore. Records are lost on
+// restart and not shared between instances, so it suits tests and single
+// instance deployments.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]*memoryIdempotencyRecord
+	lastSweep time.Time
+}
+
+type memoryIdempotencyRecord struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]*memoryIdempotencyRecord)}
+}
+
+// Reserve records an in-flight request for key unless an unexpired record exists.
+func (s *MemoryIdempotencyStore) Reserve(
+	_ context.Context,
+	key, fingerprint string,
+	ttl time.Duration,
+) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if existing, ok := s.records[key]; ok && now.Before(existing.expiresAt) {
+		return copyIdempotencyRecord(&existing.record), false, nil
+	}
+
+	s.records[key] = &memoryIdempotencyRecord{
+		record:    IdempotencyRecord{Fingerprint: fingerprint},
+		expiresAt: now.Add(ttl),
+	}
+
+	return nil, true, nil
+}
+
+// Complete stores the response of a reserved request.
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, record *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[key]; ok {
+		existing.record = *copyIdempotencyRecord(record)
+	}
+
+	return nil
+}
+
+// Release removes a reservation.
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+// sweep removes expired records, at most once a minute.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// copyIdempotencyRecord returns a deep copy of a record.
+func copyIdempotencyRecord(record *IdempotencyRecord) *IdempotencyRecord {
+	copied := *record
+	copied.Header = record.Header.Clone()
+	copied.Body = slices.Clone(record.Body)
+
+	return &copied
+}
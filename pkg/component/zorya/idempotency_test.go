@@ -0,0 +1,224 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyTestAPI(
+	t *testing.T,
+	handler func(ctx context.Context, input *CreateNoteInput) (*CreateNoteOutput, error),
+	opts ...IdempotencyOption,
+) (API, *chi.Mux) {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Post(api, "/notes", handler, IdempotencyKey(NewMemoryIdempotencyStore(), opts...))
+
+	return api, router
+}
+
+func postNoteWithKey(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/notes", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func countingNoteHandler(calls *atomic.Int32) func(context.Context, *CreateNoteInput) (*CreateNoteOutput, error) {
+	return func(ctx context.Context, input *CreateNoteInput) (*CreateNoteOutput, error) {
+		n := calls.Add(1)
+		output := &CreateNoteOutput{}
+		output.Body.Title = input.Body.Title
+		output.Body.Stars = int(n)
+
+		return output, nil
+	}
+}
+
+func TestIdempotencyKey_Replay(t *testing.T) {
+	var calls atomic.Int32
+	_, router := newIdempotencyTestAPI(t, countingNoteHandler(&calls))
+
+	first := postNoteWithKey(router, "key-1", `{"Title":"Groceries"}`)
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+
+	replay := postNoteWithKey(router, "key-1", `{"Title":"Groceries"}`)
+	assert.Equal(t, http.StatusOK, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, first.Header().Get("Content-Type"), replay.Header().Get("Content-Type"))
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Another key is a new request.
+	other := postNoteWithKey(router, "key-2", `{"Title":"Groceries"}`)
+	assert.JSONEq(t, `{"Title":"Groceries","Tags":null,"Stars":2}`, other.Body.String())
+}
+
+func TestIdempotencyKey_DifferentPayload(t *testing.T) {
+	var calls atomic.Int32
+	_, router := newIdempotencyTestAPI(t, countingNoteHandler(&calls))
+
+	postNoteWithKey(router, "key-1", `{"Title":"Groceries"}`)
+	recorder := postNoteWithKey(router, "key-1", `{"Title":"Chores"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotencyKey_InFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_, router := newIdempotencyTestAPI(t, func(ctx context.Context, input *CreateNoteInput) (*CreateNoteOutput, error) {
+		close(started)
+		<-release
+
+		return &CreateNoteOutput{}, nil
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postNoteWithKey(router, "key-1", `{"Title":"Groceries"}`)
+	}()
+	<-started
+
+	recorder := postNoteWithKey(router, "key-1", `{"Title":"Groceries"}`)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, (<-done).Code)
+}
+
+func TestIdempotencyKey_ServerErrorsAreNotRecorded(t *testing.T) {
+	var calls atomic.Int32
+	_, router := newIdempotencyTestAPI(t, func(ctx context.Context, input *CreateNoteInput) (*CreateNoteOutput, error) {
+		if calls.Add(1) == 1 {
+			return nil, Error503ServiceUnavailable("try again")
+		}
+
+		return &CreateNoteOutput{}, nil
+	})
+
+	assert.Equal(t, http.StatusServiceUnavailable, postNoteWithKey(router, "key-1", `{}`).Code)
+	assert.Equal(t, http.StatusOK, postNoteWithKey(router, "key-1", `{}`).Code)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyKey_ScopedToCaller(t *testing.T) {
+	var calls atomic.Int32
+	_, router := newIdempotencyTestAPI(t, countingNoteHandler(&calls))
+
+	post := func(cookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/notes", bytes.NewReader([]byte(`{"Title":"Groceries"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		req.Header.Set("Cookie", cookie)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	alice := post("session=alice")
+	bob := post("session=bob")
+	require.Equal(t, http.StatusOK, bob.Code, bob.Body.String())
+	assert.Empty(t, bob.Header().Get(HeaderIdempotentReplayed))
+	assert.NotEqual(t, alice.Body.String(), bob.Body.String())
+
+	replay := post("session=alice")
+	assert.Equal(t, "true", replay.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, alice.Body.String(), replay.Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyKey_ReplayRecordsHandlerHeaders(t *testing.T) {
+	var calls, requests atomic.Int32
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	api.UseMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Count", strconv.Itoa(int(requests.Add(1))))
+			next.ServeHTTP(w, r)
+		})
+	})
+	Post(api, "/notes", func(ctx context.Context, input *CreateNoteInput) (*CreateNoteOutput, error) {
+		return countingNoteHandler(&calls)(ctx, input)
+	}, IdempotencyKey(NewMemoryIdempotencyStore()))
+
+	postNoteWithKey(router, "key-1", `{"Title":"Groceries"}`)
+	replay := postNoteWithKey(router, "key-1", `{"Title":"Groceries"}`)
+
+	assert.Equal(t, "true", replay.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, "2", replay.Header().Get("X-Request-Count"))
+	assert.Equal(t, "application/json", replay.Header().Get("Content-Type"))
+}
+
+func TestIdempotencyKey_MissingKey(t *testing.T) {
+	var calls atomic.Int32
+	_, router := newIdempotencyTestAPI(t, countingNoteHandler(&calls))
+
+	postNoteWithKey(router, "", `{"Title":"Groceries"}`)
+	postNoteWithKey(router, "", `{"Title":"Groceries"}`)
+	assert.Equal(t, int32(2), calls.Load())
+
+	_, router = newIdempotencyTestAPI(t, countingNoteHandler(&calls), IdempotencyKeyRequired())
+	recorder := postNoteWithKey(router, "", `{"Title":"Groceries"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyKey_OpenAPI(t *testing.T) {
+	api, _ := newIdempotencyTestAPI(t, countingNoteHandler(new(atomic.Int32)), IdempotencyKeyRequired())
+
+	op := api.OpenAPI().Paths["/notes"].Post
+	var param *Param
+	for _, p := range op.Parameters {
+		if p.Name == HeaderIdempotencyKey {
+			param = p
+		}
+	}
+	require.NotNil(t, param)
+	assert.Equal(t, "header", param.In)
+	assert.True(t, param.Required)
+	assert.Contains(t, op.Responses, "400")
+	assert.Contains(t, op.Responses, "409")
+	assert.Contains(t, op.Responses, "422")
+}
+
+func TestMemoryIdempotencyStore_Expiry(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	ctx := context.Background()
+
+	_, reserved, err := store.Reserve(ctx, "key", "fp", time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	record, reserved, err := store.Reserve(ctx, "key", "fp", time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.False(t, record.Completed)
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, reserved, err = store.Reserve(ctx, "key", "other", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
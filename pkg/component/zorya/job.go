@@ -0,0 +1,466 @@
+package zorya
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job defaults.
+const (
+	// DefaultJobsPath is the default path of the job status endpoint.
+	DefaultJobsPath = "/operations"
+
+	// DefaultJobTTL is how long job statuses are kept by default.
+	DefaultJobTTL = 24 * time.Hour
+
+	// DefaultJobRetryAfter is the default polling interval suggested to clients.
+	DefaultJobRetryAfter = 2 * time.Second
+)
+
+// jobStatusOperationID is the operation ID of the job status endpoint.
+const jobStatusOperationID = "get-operation-status"
+
+const jobCallerContextKey contextKey = "job_caller_context"
+
+// JobState is the state of an asynchronous job.
+type JobState string
+
+// Job states.
+const (
+	JobPending   JobState = "pending"   // accepted, not started yet
+	JobRunning   JobState = "running"   // started
+	JobSucceeded JobState = "succeeded" // finished with a result
+	JobFailed    JobState = "failed"    // finished with an error
+)
+
+// Done reports whether the job has finished.
+func (s JobState) Done() bool {
+	return s == JobSucceeded || s == JobFailed
+}
+
+// JobStatus is the status of a job, as stored in a JobStore and returned by
+// the status endpoint.
+type JobStatus struct {
+	// ID identifies the job.
+	ID string `json:"id" schema:"id"`
+
+	// State is the state of the job.
+	State JobState `json:"state" schema:"state" validate:"oneof=pending running succeeded failed"`
+
+	// Progress is the completion percentage reported by the job.
+	Progress int `json:"progress" schema:"progress" validate:"min=0,max=100" openapi:"description=Completion percentage."`
+
+	// Result is the result of a succeeded job.
+	Result any `json:"result,omitempty" schema:"result"`
+
+	// Error is the problem of a failed job (RFC 9457).
+	Error *ErrorModel `json:"error,omitempty" schema:"error"`
+
+	// CreatedAt and UpdatedAt are when the job was accepted and last updated.
+	CreatedAt time.Time `json:"created_at" schema:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" schema:"updated_at"`
+}
+
+// JobStore stores job statuses. Stores shared between instances typically
+// serialize statuses as JSON, so results should be JSON values.
+// Implementations must be safe for concurrent use.
+type JobStore interface {
+	// Save creates or replaces the status of a job, kept for ttl.
+	Save(ctx context.Context, status *JobStatus, ttl time.Duration) error
+
+	// Get returns the status of a job, or nil if it does not exist or has expired.
+	Get(ctx context.Context, id string) (*JobStatus, error)
+}
+
+// JobFunc is the work of a job. It returns the result of the job or an
+// error; errors implementing StatusError are reported as is, others as a 500
+// problem.
+type JobFunc func(ctx context.Context, job *Job) (any, error)
+
+// Accepted is the output of operations that run as a job. It responds with
+// 202 Accepted, the job status and a Location header pointing at the status
+// endpoint. See Jobs.Start.
+type Accepted struct {
+	// Location is the URL of the job status.
+	Location string `schema:"Location,location=header"`
+
+	// RetryAfter is the number of seconds to wait before polling the status.
+	RetryAfter int `schema:"Retry-After,location=header"`
+
+	Body JobStatus `body:"structured"`
+}
+
+// Status returns 202 Accepted.
+func (Accepted) Status() int {
+	return http.StatusAccepted
+}
+
+// JobStatusInput is the input of the job status endpoint.
+type JobStatusInput struct {
+	ID string `schema:"id,location=path,required=true"`
+}
+
+// JobStatusOutput is the output of the job status endpoint.
+type JobStatusOutput struct {
+	// RetryAfter is the number of seconds to wait before polling again, set
+	// until the job is done.
+	RetryAfter *int `schema:"Retry-After,location=header"`
+
+	Body JobStatus `body:"structured"`
+}
+
+// Jobs runs asynchronous operations and serves their status. Operations that
+// take too long for a request return the output of Start, which runs the work
+// in the background and answers 202 Accepted:
+//
+//	jobs := zorya.NewJobs(api, zorya.NewMemoryJobStore())
+//
+//	zorya.Post(api, "/media", func(ctx context.Context, input *CreateMediaInput) (*zorya.Accepted, error) {
+//		return jobs.Start(ctx, func(ctx context.Context, job *zorya.Job) (any, error) {
+//			return generateThumbnails(ctx, job, input.Body)
+//		})
+//	})
+//
+// Clients poll the URL of the Location header, GET /operations/{id}, until
+// the state is succeeded or failed, waiting for the Retry-After header
+// between polls.
+type Jobs struct {
+	store        JobStore
+	logger       *slog.Logger
+	path         string
+	ttl          time.Duration
+	retryAfter   time.Duration
+	scope        func(r *http.Request) string
+	routeOptions []func(*BaseRoute)
+	running      sync.WaitGroup
+}
+
+// JobsOption configures Jobs.
+type JobsOption func(*Jobs)
+
+// JobsPath sets the path of the status endpoint. Defaults to DefaultJobsPath.
+func JobsPath(path string) JobsOption {
+	return func(j *Jobs) {
+		j.path = path
+	}
+}
+
+// JobsTTL sets how long job statuses are kept. Defaults to DefaultJobTTL.
+func JobsTTL(ttl time.Duration) JobsOption {
+	return func(j *Jobs) {
+		j.ttl = ttl
+	}
+}
+
+// JobsRetryAfter sets the polling interval suggested in Retry-After headers.
+// Defaults to DefaultJobRetryAfter.
+func JobsRetryAfter(retryAfter time.Duration) JobsOption {
+	return func(j *Jobs) {
+		j.retryAfter = retryAfter
+	}
+}
+
+// JobsScope sets the function returning the caller jobs are scoped to, such
+// as the authenticated user ID. Defaults to a hash of the Authorization and
+// Cookie headers; set a scope when credentials rotate during a job.
+func JobsScope(scope func(r *http.Request) string) JobsOption {
+	return func(j *Jobs) {
+		j.scope = scope
+	}
+}
+
+// JobsRouteOptions applies route options such as Secure to the status
+// endpoint. Status endpoints should be as protected as the operations that
+// start jobs, as results may hold private data.
+func JobsRouteOptions(options ...func(*BaseRoute)) JobsOption {
+	return func(j *Jobs) {
+		j.routeOptions = append(j.routeOptions, options...)
+	}
+}
+
+// NewJobs creates Jobs storing statuses in store and registers the GET
+// {path}/{id} status endpoint on api. Call it once per API.
+//
+// Statuses of jobs started by operations returning Accepted are scoped to the
+// caller, identified by its credentials unless JobsScope sets another scope
+// function, so other callers get 404 for them. Jobs started outside such an
+// operation are visible to every caller of the status endpoint.
+func NewJobs(api API, store JobStore, opts ...JobsOption) *Jobs {
+	j := &Jobs{
+		store:      store,
+		logger:     api.Logger(),
+		path:       DefaultJobsPath,
+		ttl:        DefaultJobTTL,
+		retryAfter: DefaultJobRetryAfter,
+		scope:      credentialsScope(),
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	op := &Operation{
+		OperationID: jobStatusOperationID,
+		Summary:     "Get the status of an asynchronous operation",
+		Description: "Returns the state and progress of an operation that answered 202 Accepted, " +
+			"then its result or its problem once done.",
+	}
+	options := append([]func(*BaseRoute){func(route *BaseRoute) {
+		route.Operation = op
+		route.Errors = append(route.Errors, http.StatusNotFound)
+		route.Middlewares = append(route.Middlewares, jobCallerMiddleware)
+	}}, j.routeOptions...)
+	Get(api, strings.TrimSuffix(j.path, "/")+"/{id}", j.getStatus, options...)
+
+	// Locations use the full path, including group prefixes.
+	for _, route := range Routes(api) {
+		if route.Operation == op {
+			j.path = strings.TrimSuffix(route.Path, "/{id}")
+
+			break
+		}
+	}
+
+	return j
+}
+
+// Start saves a pending job, runs fn in the background and returns the 202
+// Accepted output of the job. fn gets a context with the values of ctx, such
+// as the authenticated user, that is not canceled when the request ends.
+func (j *Jobs) Start(ctx context.Context, fn JobFunc) (*Accepted, error) {
+	now := time.Now().UTC()
+	id := rand.Text()
+	job := &Job{jobs: j, storeID: j.storeID(ctx, id), status: JobStatus{
+		ID:        id,
+		State:     JobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+	status := job.snapshot()
+	if err := job.save(ctx, status); err != nil {
+		return nil, err
+	}
+
+	j.running.Go(func() {
+		j.run(context.WithoutCancel(ctx), job, fn)
+	})
+
+	return &Accepted{
+		Location:   j.path + "/" + status.ID,
+		RetryAfter: j.retryAfterSeconds(),
+		Body:       status,
+	}, nil
+}
+
+// Wait blocks until the jobs started so far are done, e.g. on shutdown.
+func (j *Jobs) Wait() {
+	j.running.Wait()
+}
+
+// run executes a job and saves its outcome.
+func (j *Jobs) run(ctx context.Context, job *Job, fn JobFunc) {
+	job.update(ctx, func(s *JobStatus) {
+		s.State = JobRunning
+	})
+
+	result, err := j.callJob(ctx, job, fn)
+
+	job.update(ctx, func(s *JobStatus) {
+		if err != nil {
+			s.State = JobFailed
+			s.Error = jobProblem(err)
+
+			return
+		}
+		s.State = JobSucceeded
+		s.Progress = 100
+		s.Result = result
+	})
+}
+
+// callJob calls fn, turning panics into errors so a failing job does not
+// crash the process. Panics are logged with their stack and fail the job with
+// a generic 500 problem, whose instance is the job ID.
+func (j *Jobs) callJob(ctx context.Context, job *Job, fn JobFunc) (result any, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			j.logger.ErrorContext(ctx, "job panicked",
+				slog.String("id", job.ID()),
+				slog.Any("panic", v),
+				slog.String("stack", string(debug.Stack())),
+			)
+			err = &ErrorModel{
+				Status:   http.StatusInternalServerError,
+				Title:    http.StatusText(http.StatusInternalServerError),
+				Detail:   "internal server error",
+				Instance: job.ID(),
+			}
+		}
+	}()
+
+	return fn(ctx, job)
+}
+
+// jobProblem converts the error of a failed job into a problem.
+func jobProblem(err error) *ErrorModel {
+	statusErr, _ := processExistingError(err)
+
+	var model *ErrorModel
+	if errors.As(statusErr, &model) {
+		return model
+	}
+	// Problems of a catalog keep their type, without their extension members.
+	if p, ok := statusErr.(interface{ errorModel() *ErrorModel }); ok {
+		return p.errorModel()
+	}
+
+	return &ErrorModel{
+		Status: statusErr.GetStatus(),
+		Title:  http.StatusText(statusErr.GetStatus()),
+		Detail: statusErr.Error(),
+	}
+}
+
+// getStatus is the handler of the status endpoint. It looks up the status of
+// the caller's job, then the status of an unscoped job.
+func (j *Jobs) getStatus(ctx context.Context, input *JobStatusInput) (*JobStatusOutput, error) {
+	status, err := j.store.Get(ctx, j.storeID(ctx, input.ID))
+	if err == nil && status == nil {
+		status, err = j.store.Get(ctx, input.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, Error404NotFound("operation not found")
+	}
+	status.ID = input.ID
+
+	output := &JobStatusOutput{Body: *status}
+	if !status.State.Done() {
+		retryAfter := j.retryAfterSeconds()
+		output.RetryAfter = &retryAfter
+	}
+
+	return output, nil
+}
+
+// storeID returns the key of a job in the store: the job ID hashed with the
+// scope of the caller in ctx, or the job ID if ctx has no caller.
+func (j *Jobs) storeID(ctx context.Context, id string) string {
+	caller, ok := ctx.Value(jobCallerContextKey).(*http.Request)
+	if !ok {
+		return id
+	}
+
+	sum := sha256.Sum256([]byte(j.scope(caller) + "\x00" + id))
+
+	return "scoped:" + hex.EncodeToString(sum[:])
+}
+
+// jobCallerMiddleware stores the request in its context, so the jobs it
+// starts or looks up are scoped to its caller.
+func jobCallerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), jobCallerContextKey, r)))
+	})
+}
+
+// retryAfterSeconds returns the Retry-After value in whole seconds, at least 1.
+func (j *Jobs) retryAfterSeconds() int {
+	return max(1, int(math.Ceil(j.retryAfter.Seconds())))
+}
+
+// Job is a running job, passed to its JobFunc to report progress.
+type Job struct {
+	jobs    *Jobs
+	storeID string
+	mu      sync.Mutex
+	status  JobStatus
+}
+
+// ID returns the ID of the job.
+func (j *Job) ID() string {
+	return j.status.ID
+}
+
+// SetProgress records the completion percentage of the job, clamped to 0-100.
+func (j *Job) SetProgress(ctx context.Context, progress int) error {
+	return j.update(ctx, func(s *JobStatus) {
+		s.Progress = min(max(progress, 0), 100)
+	})
+}
+
+// update changes the status of the job and saves it. Save errors are also
+// logged, as the job outcome has no caller to return them to.
+func (j *Job) update(ctx context.Context, change func(*JobStatus)) error {
+	j.mu.Lock()
+	change(&j.status)
+	j.status.UpdatedAt = time.Now().UTC()
+	status := j.status
+	j.mu.Unlock()
+
+	if err := j.save(ctx, status); err != nil {
+		j.jobs.logger.ErrorContext(ctx, "failed to save job status", "id", status.ID, "state", status.State, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// save saves a status of the job under its store key.
+func (j *Job) save(ctx context.Context, status JobStatus) error {
+	status.ID = j.storeID
+	if err := j.jobs.store.Save(ctx, &status, j.jobs.ttl); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	return nil
+}
+
+// snapshot returns a copy of the status of the job.
+func (j *Job) snapshot() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.status
+}
+
+// documentAccepted makes 202 the documented success status of operations
+// returning Accepted.
+func documentAccepted(route *BaseRoute, outputType reflect.Type) {
+	if outputType != reflect.TypeFor[Accepted]() || route.DefaultStatus != 0 {
+		return
+	}
+	route.DefaultStatus = http.StatusAccepted
+}
+
+// documentAcceptedLinks links the 202 response of operations returning
+// Accepted to the job status endpoint.
+func documentAcceptedLinks(route *BaseRoute, outputType reflect.Type) {
+	if outputType != reflect.TypeFor[Accepted]() {
+		return
+	}
+
+	resp := getResponse(route.Operation, http.StatusAccepted)
+	if resp.Links == nil {
+		resp.Links = make(map[string]*Link)
+	}
+	resp.Links["status"] = &Link{
+		OperationID: jobStatusOperationID,
+		Parameters:  map[string]any{"id": "$response.body#/id"},
+		Description: "Status of the operation, to poll until it is done.",
+	}
+}
@@ -0,0 +1,78 @@
+package zorya
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryJobStore is an in-memory JobStore. Statuses are lost on restart and
+// not shared between instances, so it suits tests and single instance
+// deployments.
+type MemoryJobStore struct {
+	mu        sync.Mutex
+	statuses  map[string]*memoryJobStatus
+	lastSweep time.Time
+}
+
+type memoryJobStatus struct {
+	status    JobStatus
+	expiresAt time.Time
+}
+
+// NewMemoryJobStore creates an empty in-memory store.
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{statuses: make(map[string]*memoryJobStatus)}
+}
+
+// Save creates or replaces the status of a job.
+func (s *MemoryJobStore) Save(_ context.Context, status *JobStatus, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	s.statuses[status.ID] = &memoryJobStatus{status: *copyJobStatus(status), expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+// Get returns the status of a job, or nil if it does not exist or has expired.
+func (s *MemoryJobStore) Get(_ context.Context, id string) (*JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.statuses[id]
+	if !ok || !time.Now().Before(stored.expiresAt) {
+		return nil, nil
+	}
+
+	return copyJobStatus(&stored.status), nil
+}
+
+// sweep removes expired statuses, at most once a minute.
+func (s *MemoryJobStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for id, stored := range s.statuses {
+		if !now.Before(stored.expiresAt) {
+			delete(s.statuses, id)
+		}
+	}
+}
+
+// copyJobStatus returns a copy of a status. The result is shared, as jobs do
+// not change it once returned.
+func copyJobStatus(status *JobStatus) *JobStatus {
+	copied := *status
+	if status.Error != nil {
+		problem := *status.Error
+		copied.Error = &problem
+	}
+
+	return &copied
+}
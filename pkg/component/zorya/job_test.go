@@ -0,0 +1,270 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CreateThumbnailsInput struct {
+	Body struct {
+		Name string `json:"name" schema:"name"`
+	} `body:"structured"`
+}
+
+type thumbnailsResult struct {
+	Small string `json:"small"`
+}
+
+func serveJobs(router http.Handler, method, target string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if method == http.MethodPost {
+		req = httptest.NewRequest(method, target, strings.NewReader(`{"name": "sunset"}`))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func decodeJobStatus(t *testing.T, recorder *httptest.ResponseRecorder) JobStatus {
+	t.Helper()
+
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status), recorder.Body.String())
+
+	return status
+}
+
+func TestJobs_Lifecycle(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	jobs := NewJobs(api, NewMemoryJobStore(), JobsRetryAfter(1500*time.Millisecond))
+
+	progressed := make(chan struct{})
+	release := make(chan struct{})
+	Post(api, "/media", func(ctx context.Context, input *CreateThumbnailsInput) (*Accepted, error) {
+		name := input.Body.Name
+
+		return jobs.Start(ctx, func(ctx context.Context, job *Job) (any, error) {
+			assert.NoError(t, job.SetProgress(ctx, 140))
+			close(progressed)
+			<-release
+
+			return thumbnailsResult{Small: name + "-s.jpg"}, nil
+		})
+	})
+
+	recorder := serveJobs(router, http.MethodPost, "/media")
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	accepted := decodeJobStatus(t, recorder)
+	assert.NotEmpty(t, accepted.ID)
+	assert.Equal(t, JobPending, accepted.State)
+	assert.Equal(t, "/operations/"+accepted.ID, recorder.Header().Get("Location"))
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+
+	<-progressed
+	recorder = serveJobs(router, http.MethodGet, recorder.Header().Get("Location"))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	running := decodeJobStatus(t, recorder)
+	assert.Equal(t, JobRunning, running.State)
+	assert.Equal(t, 100, running.Progress)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+
+	close(release)
+	jobs.Wait()
+
+	recorder = serveJobs(router, http.MethodGet, "/operations/"+accepted.ID)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Empty(t, recorder.Header().Get("Retry-After"))
+	succeeded := decodeJobStatus(t, recorder)
+	assert.Equal(t, JobSucceeded, succeeded.State)
+	assert.Equal(t, map[string]any{"small": "sunset-s.jpg"}, succeeded.Result)
+	assert.Nil(t, succeeded.Error)
+	assert.Equal(t, accepted.CreatedAt, succeeded.CreatedAt)
+	assert.False(t, succeeded.UpdatedAt.Before(succeeded.CreatedAt))
+}
+
+func TestJobs_Failures(t *testing.T) {
+	var logs bytes.Buffer
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	jobs := NewJobs(api, NewMemoryJobStore())
+
+	failures := map[string]JobFunc{
+		"problem": func(ctx context.Context, job *Job) (any, error) {
+			return nil, Error422UnprocessableEntity("unsupported image format")
+		},
+		"error": func(ctx context.Context, job *Job) (any, error) {
+			return nil, errors.New("disk full")
+		},
+		"panic": func(ctx context.Context, job *Job) (any, error) {
+			panic("corrupt image")
+		},
+	}
+
+	ids := make(map[string]string, len(failures))
+	for name, fn := range failures {
+		accepted, err := jobs.Start(t.Context(), fn)
+		require.NoError(t, err)
+		ids[name] = accepted.Body.ID
+	}
+	jobs.Wait()
+
+	expected := map[string]string{
+		"problem": `{"title": "Unprocessable Entity", "status": 422, "detail": "unsupported image format"}`,
+		"error":   `{"title": "Internal Server Error", "status": 500, "detail": "disk full"}`,
+		// Panics are logged, their value is not exposed.
+		"panic": `{"title": "Internal Server Error", "status": 500, "detail": "internal server error", "instance": "` +
+			ids["panic"] + `"}`,
+	}
+	for name, problem := range expected {
+		recorder := serveJobs(router, http.MethodGet, "/operations/"+ids[name])
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		var body struct {
+			State  JobState        `json:"state"`
+			Result any             `json:"result"`
+			Error  json.RawMessage `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, JobFailed, body.State, name)
+		assert.Nil(t, body.Result, name)
+		assert.JSONEq(t, problem, string(body.Error), name)
+	}
+	assert.Contains(t, logs.String(), "job panicked")
+	assert.Contains(t, logs.String(), "id="+ids["panic"])
+	assert.Contains(t, logs.String(), "panic=\"corrupt image\"")
+	assert.Contains(t, logs.String(), "stack=")
+}
+
+func TestJobs_ScopedToCaller(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	jobs := NewJobs(api, NewMemoryJobStore())
+	Post(api, "/media", func(ctx context.Context, input *CreateThumbnailsInput) (*Accepted, error) {
+		return jobs.Start(ctx, func(ctx context.Context, job *Job) (any, error) {
+			return thumbnailsResult{Small: "private.jpg"}, nil
+		})
+	})
+
+	serve := func(method, target, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(`{"name": "sunset"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	recorder := serve(http.MethodPost, "/media", "Bearer alice")
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	location := recorder.Header().Get("Location")
+	jobs.Wait()
+
+	recorder = serve(http.MethodGet, location, "Bearer alice")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "/operations/"+decodeJobStatus(t, recorder).ID, location)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, location, "Bearer mallory").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, location, "").Code)
+}
+
+func TestJobs_NotFound(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	NewJobs(api, NewMemoryJobStore())
+
+	recorder := serveJobs(router, http.MethodGet, "/operations/unknown")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "operation not found")
+}
+
+func TestJobs_GroupPrefix(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	jobs := NewJobs(NewGroup(api, "/v1"), NewMemoryJobStore(), JobsPath("/jobs"))
+
+	accepted, err := jobs.Start(t.Context(), func(ctx context.Context, job *Job) (any, error) {
+		return "done", nil
+	})
+	require.NoError(t, err)
+	jobs.Wait()
+
+	assert.Equal(t, "/v1/jobs/"+accepted.Body.ID, accepted.Location)
+	recorder := serveJobs(router, http.MethodGet, accepted.Location)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "done", decodeJobStatus(t, recorder).Result)
+}
+
+func TestMemoryJobStore_Expiry(t *testing.T) {
+	store := NewMemoryJobStore()
+	ctx := t.Context()
+
+	require.NoError(t, store.Save(ctx, &JobStatus{ID: "kept", State: JobRunning}, time.Hour))
+	require.NoError(t, store.Save(ctx, &JobStatus{ID: "expired", State: JobRunning}, -time.Second))
+
+	status, err := store.Get(ctx, "kept")
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, JobRunning, status.State)
+
+	// Returned statuses are copies.
+	status.State = JobFailed
+	status, err = store.Get(ctx, "kept")
+	require.NoError(t, err)
+	assert.Equal(t, JobRunning, status.State)
+
+	status, err = store.Get(ctx, "expired")
+	require.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+func TestJobs_OpenAPI(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	jobs := NewJobs(api, NewMemoryJobStore())
+	Post(api, "/media", func(ctx context.Context, input *CreateThumbnailsInput) (*Accepted, error) {
+		return jobs.Start(ctx, func(ctx context.Context, job *Job) (any, error) { return nil, nil })
+	})
+
+	create := api.OpenAPI().Paths["/media"].Post
+	require.NotNil(t, create)
+	require.Contains(t, create.Responses, "202")
+	assert.NotContains(t, create.Responses, "200")
+	accepted := create.Responses["202"]
+	assert.Contains(t, accepted.Headers, "Location")
+	assert.Contains(t, accepted.Headers, "Retry-After")
+	require.Contains(t, accepted.Links, "status")
+	assert.Equal(t, "get-operation-status", accepted.Links["status"].OperationID)
+	assert.Equal(t, map[string]any{"id": "$response.body#/id"}, accepted.Links["status"].Parameters)
+
+	status := api.OpenAPI().Paths["/operations/{id}"].Get
+	require.NotNil(t, status)
+	assert.Equal(t, "get-operation-status", status.OperationID)
+	assert.Contains(t, status.Responses, "200")
+	assert.Contains(t, status.Responses, "404")
+	assert.Contains(t, status.Responses["200"].Headers, "Retry-After")
+
+	schema := api.OpenAPI().Components.Schemas["JobStatus"]
+	require.NotNil(t, schema)
+	assert.Equal(t, []any{"pending", "running", "succeeded", "failed"}, schema.Properties["state"].Enum)
+}
@@ -0,0 +1,277 @@
+package zorya
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/talav/talav/pkg/component/negotiation"
+)
+
+const localeContextKey contextKey = "locale_context"
+
+// Localizer renders problem details and validation messages in the language
+// negotiated from the Accept-Language request header. See WithLocalizer.
+type Localizer struct {
+	translator *ut.UniversalTranslator
+	locales    []string
+	negotiator *negotiation.Negotiator
+
+	// messages holds the messages added with AddMessage by locale.
+	messages map[string]map[string]struct{}
+}
+
+// Locale is the language negotiated for a request.
+type Locale struct {
+	// Tag is the supported locale the request was resolved to (e.g. "de", "pt-BR").
+	Tag string
+
+	// Translator renders messages in the locale.
+	Translator ut.Translator
+
+	messages map[string]struct{}
+}
+
+// NewLocalizer creates a Localizer for the supported locales, given as BCP 47
+// language tags in preference order. The first locale is used when the client
+// accepts none of them. Every locale needs a translator in translator, looked
+// up with the tag's hyphens replaced by underscores ("pt-BR" uses "pt_BR").
+//
+//	enLocale, deLocale := en.New(), de.New()
+//	translator := ut.New(enLocale, enLocale, deLocale)
+//	localizer, err := zorya.NewLocalizer(translator, "en", "de")
+func NewLocalizer(translator *ut.UniversalTranslator, locales ...string) (*Localizer, error) {
+	if translator == nil {
+		return nil, errors.New("zorya: localizer requires a translator")
+	}
+	if len(locales) == 0 {
+		return nil, errors.New("zorya: localizer requires at least one locale")
+	}
+
+	for _, locale := range locales {
+		if _, found := translator.GetTranslator(translatorLocale(locale)); !found {
+			return nil, fmt.Errorf("zorya: no translator for locale %q", locale)
+		}
+	}
+
+	return &Localizer{
+		translator: translator,
+		locales:    locales,
+		negotiator: negotiation.NewLanguageNegotiator(),
+		messages:   make(map[string]map[string]struct{}),
+	}, nil
+}
+
+// WithLocalizer enables localization of error responses. Every operation
+// resolves the request locale from Accept-Language, validation messages are
+// rendered with the locale's translator, and the Title, Detail and error
+// detail messages of ErrorModel responses are translated with the messages
+// added with Localizer.AddMessage, keeping the original text otherwise. Localized error
+// responses carry a Content-Language header.
+func WithLocalizer(localizer *Localizer) Option {
+	return func(a *api) {
+		a.localizer = localizer
+	}
+}
+
+// AddMessage adds the translation of an error message to a supported locale.
+// The message is the Title, Detail or error detail message of ErrorModel
+// responses, e.g. "Not Found", and the translation has no parameters. Add
+// messages before serving requests.
+func (l *Localizer) AddMessage(locale, message, translation string) error {
+	if !slices.Contains(l.locales, locale) {
+		return fmt.Errorf("zorya: unsupported locale %q", locale)
+	}
+	if strings.ContainsAny(translation, "{}") {
+		return fmt.Errorf("zorya: translation of %q has parameters", message)
+	}
+
+	translator, _ := l.translator.GetTranslator(translatorLocale(locale))
+	if err := translator.Add(message, translation, false); err != nil {
+		return err
+	}
+
+	if l.messages[locale] == nil {
+		l.messages[locale] = make(map[string]struct{})
+	}
+	l.messages[locale][message] = struct{}{}
+
+	return nil
+}
+
+// Locales returns the supported locales in preference order.
+func (l *Localizer) Locales() []string {
+	return l.locales
+}
+
+// Resolve returns the supported locale best matching an Accept-Language
+// header value, or the default locale if none matches. Languages refused with
+// q=0 are never selected.
+func (l *Localizer) Resolve(acceptLanguage string) *Locale {
+	tag := l.locales[0]
+	if acceptLanguage != "" {
+		if best := l.negotiate(acceptLanguage); best != "" {
+			tag = best
+		}
+	}
+
+	translator, _ := l.translator.GetTranslator(translatorLocale(tag))
+
+	return &Locale{Tag: tag, Translator: translator, messages: l.messages[tag]}
+}
+
+// negotiate returns the supported locale preferred by an Accept-Language
+// header value, or "" if none is acceptable.
+func (l *Localizer) negotiate(header string) string {
+	candidates := l.negotiator.Acceptable(header, l.locales)
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	best, err := l.negotiator.Negotiate(header, candidates, false)
+	if err != nil {
+		return ""
+	}
+
+	for _, locale := range candidates {
+		if strings.EqualFold(locale, best.Type) {
+			return locale
+		}
+	}
+
+	return ""
+}
+
+// translatorLocale converts a BCP 47 language tag to a translator locale.
+func translatorLocale(tag string) string {
+	return strings.ReplaceAll(tag, "-", "_")
+}
+
+// GetLocale returns the locale negotiated for the request, or nil if the API
+// has no Localizer. Handlers and validators can use it to localize their own
+// messages.
+func GetLocale(ctx context.Context) *Locale {
+	locale, ok := ctx.Value(localeContextKey).(*Locale)
+	if !ok {
+		return nil
+	}
+
+	return locale
+}
+
+// newLocaleMiddleware creates middleware storing the request locale in the
+// request context. Returns nil if the API has no Localizer.
+func newLocaleMiddleware(api API) Middleware {
+	localizer := api.Localizer()
+	if localizer == nil {
+		return nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale := localizer.Resolve(r.Header.Get("Accept-Language"))
+			ctx := context.WithValue(r.Context(), localeContextKey, locale)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestLocale returns the locale of a request, resolving it for requests
+// that did not go through an operation, or nil if the API has no Localizer.
+func requestLocale(api API, r *http.Request) *Locale {
+	if locale := GetLocale(r.Context()); locale != nil {
+		return locale
+	}
+
+	localizer := api.Localizer()
+	if localizer == nil {
+		return nil
+	}
+
+	return localizer.Resolve(r.Header.Get("Accept-Language"))
+}
+
+// localizeError returns a copy of an ErrorModel or a Problem with its title, detail and
+// error detail messages translated. Other errors are returned as they are.
+func localizeError(err StatusError, locale *Locale) StatusError {
+	if p, ok := err.(interface{ localize(*Locale) StatusError }); ok {
+		return p.localize(locale)
+	}
+
+	model, ok := err.(*ErrorModel)
+	if !ok {
+		return err
+	}
+
+	localized := *model
+	localized.Title = translate(locale, model.Title)
+	localized.Detail = translate(locale, model.Detail)
+	if model.Errors != nil {
+		localized.Errors = make([]*ErrorDetail, len(model.Errors))
+		for i, detail := range model.Errors {
+			if detail == nil {
+				continue
+			}
+			localizedDetail := *detail
+			localizedDetail.Message = translate(locale, detail.Message)
+			localized.Errors[i] = &localizedDetail
+		}
+	}
+
+	return &localized
+}
+
+// translate returns the translation of a message added with AddMessage, or
+// text itself. Other translations of the translator, such as validator
+// messages with placeholders, are never looked up.
+func translate(locale *Locale, text string) string {
+	if _, ok := locale.messages[text]; !ok {
+		return text
+	}
+
+	translated, err := locale.Translator.T(text)
+	if err != nil {
+		return text
+	}
+
+	return translated
+}
+
+// documentLocalization documents the Accept-Language request header and the
+// Content-Language header of error responses. It runs once the responses have
+// been generated.
+func documentLocalization(api API, route *BaseRoute) {
+	localizer := api.Localizer()
+	if localizer == nil {
+		return
+	}
+
+	locales := localizer.Locales()
+	addHeaderParam(route.Operation, "Accept-Language",
+		fmt.Sprintf("Preferred languages of error messages. Supported: %s (default %s).",
+			strings.Join(locales, ", "), locales[0]))
+
+	enum := make([]any, len(locales))
+	for i, locale := range locales {
+		enum[i] = locale
+	}
+
+	for status, resp := range route.Operation.Responses {
+		if status < "400" || resp == nil {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]*Param)
+		}
+		if _, ok := resp.Headers["Content-Language"]; !ok {
+			resp.Headers["Content-Language"] = &Param{
+				Description: "Language of the error messages.",
+				Schema:      &Schema{Type: TypeString, Enum: enum},
+			}
+		}
+	}
+}
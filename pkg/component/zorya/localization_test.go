@@ -0,0 +1,265 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	de_translations "github.com/go-playground/validator/v10/translations/de"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LocalizedUserInput struct {
+	Body struct {
+		Name string `validate:"required"`
+	} `body:"structured"`
+}
+
+type LocalizedUserOutput struct {
+	Body struct {
+		Name string
+	} `body:"structured"`
+}
+
+func newLocalizedTestAPI(t *testing.T) (*chi.Mux, API) {
+	t.Helper()
+
+	enLocale, deLocale := en.New(), de.New()
+	translator := ut.New(enLocale, enLocale, deLocale)
+
+	validate := validator.New()
+	enTranslator, _ := translator.GetTranslator("en")
+	require.NoError(t, en_translations.RegisterDefaultTranslations(validate, enTranslator))
+	deTranslator, _ := translator.GetTranslator("de")
+	require.NoError(t, de_translations.RegisterDefaultTranslations(validate, deTranslator))
+
+	localizer, err := NewLocalizer(translator, "en", "de")
+	require.NoError(t, err)
+	require.NoError(t, localizer.AddMessage("de", "Unprocessable Entity", "Unverarbeitbarer Inhalt"))
+	require.NoError(t, localizer.AddMessage("de", "Not Found", "Nicht gefunden"))
+	require.NoError(t, localizer.AddMessage("de", "validation failed", "Validierung fehlgeschlagen"))
+	require.NoError(t, localizer.AddMessage("de", "user not found", "Benutzer nicht gefunden"))
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router},
+		WithValidator(NewPlaygroundValidator(validate)),
+		WithLocalizer(localizer),
+	)
+
+	Post(api, "/users", func(ctx context.Context, input *LocalizedUserInput) (*LocalizedUserOutput, error) {
+		return &LocalizedUserOutput{}, nil
+	})
+	Get(api, "/users/missing", func(ctx context.Context, input *struct{}) (*LocalizedUserOutput, error) {
+		return nil, Error404NotFound("user not found")
+	}, func(route *BaseRoute) {
+		route.Errors = []int{http.StatusNotFound}
+	})
+	Get(api, "/users/required", func(ctx context.Context, input *struct{}) (*LocalizedUserOutput, error) {
+		// "required" is also the key of a validator translation with a placeholder.
+		return nil, Error400BadRequest("required")
+	})
+
+	return router, api
+}
+
+func TestLocalizer_Resolve(t *testing.T) {
+	translator := ut.New(en.New(), en.New(), de.New())
+	localizer, err := NewLocalizer(translator, "en", "de")
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"":                   "en",
+		"de":                 "de",
+		"de-DE":              "de",
+		"fr, de;q=0.8":       "de",
+		"en;q=0.5, de":       "de",
+		"de;q=0":             "en",
+		"de;q=0, en;q=0":     "en",
+		"fr":                 "en",
+		"*":                  "en",
+		"DE-at, en-US;q=0.9": "de",
+	}
+	for header, expected := range tests {
+		locale := localizer.Resolve(header)
+		assert.Equal(t, expected, locale.Tag, header)
+		assert.Equal(t, expected, locale.Translator.Locale(), header)
+	}
+}
+
+func TestNewLocalizer_Errors(t *testing.T) {
+	translator := ut.New(en.New(), en.New())
+
+	_, err := NewLocalizer(translator)
+	require.EqualError(t, err, "zorya: localizer requires at least one locale")
+
+	_, err = NewLocalizer(translator, "en", "de")
+	require.EqualError(t, err, `zorya: no translator for locale "de"`)
+
+	_, err = NewLocalizer(nil, "en")
+	require.EqualError(t, err, "zorya: localizer requires a translator")
+}
+
+func TestLocalization_ValidationErrors(t *testing.T) {
+	router, _ := newLocalizedTestAPI(t)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		language       string
+		expected       string
+	}{
+		{
+			name:     "default locale",
+			language: "en",
+			expected: `{
+				"title": "Unprocessable Entity",
+				"status": 422,
+				"detail": "validation failed",
+				"errors": [{"code": "required", "message": "Name is a required field", "location": "body.LocalizedUserInput.Body.Name"}]
+			}`,
+		},
+		{
+			name:           "negotiated locale",
+			acceptLanguage: "de-CH, en;q=0.5",
+			language:       "de",
+			expected: `{
+				"title": "Unverarbeitbarer Inhalt",
+				"status": 422,
+				"detail": "Validierung fehlgeschlagen",
+				"errors": [{"code": "required", "message": "Name ist ein Pflichtfeld", "location": "body.LocalizedUserInput.Body.Name"}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			assert.Equal(t, tt.language, recorder.Header().Get("Content-Language"))
+			assert.JSONEq(t, tt.expected, recorder.Body.String())
+		})
+	}
+}
+
+func TestLocalization_HandlerError(t *testing.T) {
+	router, _ := newLocalizedTestAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/missing", nil)
+	req.Header.Set("Accept-Language", "de")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "de", recorder.Header().Get("Content-Language"))
+	assert.JSONEq(t, `{"title": "Nicht gefunden", "status": 404, "detail": "Benutzer nicht gefunden"}`, recorder.Body.String())
+}
+
+func TestLocalization_TranslatorKeys(t *testing.T) {
+	router, _ := newLocalizedTestAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/required", nil)
+	req.Header.Set("Accept-Language", "de")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{"title": "Bad Request", "status": 400, "detail": "required"}`, recorder.Body.String())
+}
+
+func TestLocalizer_AddMessageErrors(t *testing.T) {
+	translator := ut.New(en.New(), en.New(), de.New())
+	localizer, err := NewLocalizer(translator, "en", "de")
+	require.NoError(t, err)
+
+	err = localizer.AddMessage("fr", "Not Found", "Introuvable")
+	require.EqualError(t, err, `zorya: unsupported locale "fr"`)
+
+	err = localizer.AddMessage("de", "user {0} not found", "Benutzer {0} nicht gefunden")
+	require.EqualError(t, err, `zorya: translation of "user {0} not found" has parameters`)
+
+	require.NoError(t, localizer.AddMessage("de", "Not Found", "Nicht gefunden"))
+	require.Error(t, localizer.AddMessage("de", "Not Found", "Fehlt"))
+}
+
+func TestLocalization_VaryAcceptLanguage(t *testing.T) {
+	router, _ := newLocalizedTestAPI(t)
+
+	for _, language := range []string{"de", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/users/missing", nil)
+		if language != "" {
+			req.Header.Set("Accept-Language", language)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, []string{"Accept-Language"}, recorder.Header().Values("Vary"), language)
+	}
+
+	recorder := httptest.NewRecorder()
+	WriteErr(NewAPI(&testChiAdapter{router: chi.NewMux()}), httptest.NewRequest(http.MethodGet, "/", nil), recorder,
+		http.StatusNotFound, "not found")
+	assert.Empty(t, recorder.Header().Values("Vary"))
+}
+
+func TestLocalization_SharedErrorNotModified(t *testing.T) {
+	_, api := newLocalizedTestAPI(t)
+	shared := Error404NotFound("user not found").(*ErrorModel)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de")
+	recorder := httptest.NewRecorder()
+	WriteErr(api, req, recorder, 0, "", shared)
+
+	assert.Contains(t, recorder.Body.String(), "Benutzer nicht gefunden")
+	assert.Equal(t, "user not found", shared.Detail)
+	assert.Equal(t, "Not Found", shared.Title)
+}
+
+func TestLocalization_OpenAPI(t *testing.T) {
+	_, api := newLocalizedTestAPI(t)
+
+	data, err := json.Marshal(api.OpenAPI().Paths["/users"].Post)
+	require.NoError(t, err)
+
+	var op struct {
+		Parameters []struct {
+			Name        string
+			In          string
+			Description string
+		}
+		Responses map[string]struct {
+			Headers map[string]struct {
+				Schema struct {
+					Enum []string
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &op))
+
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "Accept-Language", op.Parameters[0].Name)
+	assert.Equal(t, "header", op.Parameters[0].In)
+	assert.Equal(t, "Preferred languages of error messages. Supported: en, de (default en).", op.Parameters[0].Description)
+
+	assert.Equal(t, []string{"en", "de"}, op.Responses["422"].Headers["Content-Language"].Schema.Enum)
+	assert.NotContains(t, op.Responses["200"].Headers, "Content-Language")
+}
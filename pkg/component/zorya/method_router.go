@@ -0,0 +1,173 @@
+package zorya
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// dispatchedMethods are the methods routed to every registered path, so that
+// requests with a method the path does not support are answered by zorya
+// instead of the router.
+var dispatchedMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// methodRouter is an Adapter wrapper that tracks the handlers registered per
+// path. Each path is registered with the underlying adapter once per method
+// and requests are dispatched to the matching handler, so OPTIONS requests
+// and unsupported methods get consistent answers on every router:
+//   - OPTIONS returns 204 with the Allow header, plus Accept-Post and
+//     Accept-Patch when the POST and PATCH operations take a request body.
+//   - Unsupported methods return a 405 problem document with the Allow header.
+//
+// HEAD requests fall back to the GET handler when no HEAD handler exists.
+type methodRouter struct {
+	Adapter
+	api   API
+	mu    sync.RWMutex
+	paths map[string]*pathMethods
+}
+
+// pathMethods holds the handlers registered for a single path.
+type pathMethods struct {
+	handlers   map[string]http.HandlerFunc
+	operations map[string]*Operation
+	// routes holds the routes registered with Register, which know their
+	// input and output types.
+	routes map[string]*BaseRoute
+	// accept holds the request body content types per method.
+	accept     map[string][]string
+	dispatched map[string]bool
+}
+
+// newMethodRouter wraps the adapter with per-path method dispatching.
+func newMethodRouter(adapter Adapter, api API) *methodRouter {
+	return &methodRouter{
+		Adapter: adapter,
+		api:     api,
+		paths:   make(map[string]*pathMethods),
+	}
+}
+
+// Handle records the handler for the route's method and path, registering
+// the path's dispatchers with the underlying adapter on first use.
+func (m *methodRouter) Handle(route *BaseRoute, handler http.HandlerFunc) {
+	method := strings.ToUpper(route.Method)
+
+	m.mu.Lock()
+	p, ok := m.paths[route.Path]
+	if !ok {
+		p = &pathMethods{
+			handlers:   make(map[string]http.HandlerFunc),
+			operations: make(map[string]*Operation),
+			routes:     make(map[string]*BaseRoute),
+			accept:     make(map[string][]string),
+			dispatched: make(map[string]bool),
+		}
+		m.paths[route.Path] = p
+	}
+
+	p.handlers[method] = handler
+	p.operations[method] = route.Operation
+	if route.inputType != nil {
+		registered := *route
+		p.routes[method] = &registered
+	}
+	if route.Operation != nil && route.Operation.RequestBody != nil {
+		p.accept[method] = slices.Sorted(maps.Keys(route.Operation.RequestBody.Content))
+	}
+
+	var register []string
+	for _, candidate := range append(slices.Clone(dispatchedMethods), method) {
+		if !p.dispatched[candidate] {
+			p.dispatched[candidate] = true
+			register = append(register, candidate)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, candidate := range register {
+		dispatchRoute := *route
+		dispatchRoute.Method = candidate
+		m.Adapter.Handle(&dispatchRoute, m.dispatch(route.Path, candidate))
+	}
+}
+
+// dispatch returns the handler registered with the underlying adapter for a
+// path and method.
+func (m *methodRouter) dispatch(path, method string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		p := m.paths[path]
+		handler := p.handlers[method]
+		if handler == nil && method == http.MethodHead {
+			handler = p.handlers[http.MethodGet]
+		}
+		allow := p.allow()
+		acceptPost := p.accept[http.MethodPost]
+		acceptPatch := p.accept[http.MethodPatch]
+		m.mu.RUnlock()
+
+		switch {
+		case handler != nil:
+			handler(w, r)
+		case method == http.MethodOptions:
+			w.Header().Set("Allow", allow)
+			if len(acceptPost) > 0 {
+				w.Header().Set("Accept-Post", strings.Join(acceptPost, ", "))
+			}
+			if len(acceptPatch) > 0 {
+				w.Header().Set("Accept-Patch", strings.Join(acceptPatch, ", "))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", allow)
+			WriteErr(m.api, r, w, 0, "", Error405MethodNotAllowed(
+				fmt.Sprintf("method %s is not allowed, allowed methods: %s", r.Method, allow),
+			))
+		}
+	}
+}
+
+// allow returns the Allow header value for the path. HEAD is allowed
+// whenever GET is, and OPTIONS is always allowed.
+func (p *pathMethods) allow() string {
+	methods := []string{http.MethodOptions}
+	for method := range p.handlers {
+		if method != http.MethodOptions {
+			methods = append(methods, method)
+		}
+	}
+	if p.handlers[http.MethodGet] != nil && p.handlers[http.MethodHead] == nil {
+		methods = append(methods, http.MethodHead)
+	}
+	slices.Sort(methods)
+
+	return strings.Join(methods, ", ")
+}
+
+// rootMethodRouter returns the method router an API registers its routes with,
+// unwrapping groups.
+func rootMethodRouter(api API) (*methodRouter, bool) {
+	adapter := api.Adapter()
+	for {
+		switch a := adapter.(type) {
+		case *methodRouter:
+			return a, true
+		case *groupAdapter:
+			adapter = a.Adapter
+		default:
+			return nil, false
+		}
+	}
+}
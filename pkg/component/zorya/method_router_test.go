@@ -0,0 +1,107 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMethodRouterTestAPI(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/notes", func(ctx context.Context, input *struct{}) (*CreateNoteOutput, error) {
+		return &CreateNoteOutput{}, nil
+	})
+	Post(api, "/notes", func(ctx context.Context, input *CreateNoteInput) (*CreateNoteOutput, error) {
+		return &CreateNoteOutput{}, nil
+	})
+	grp := NewGroup(api, "/v1")
+	Patch(grp, "/notes", func(ctx context.Context, input *CreateNoteInput) (*CreateNoteOutput, error) {
+		return &CreateNoteOutput{}, nil
+	})
+
+	return router
+}
+
+func TestMethodRouter_Options(t *testing.T) {
+	router := newMethodRouterTestAPI(t)
+
+	recorder := serveWithHeaders(router, http.MethodOptions, "/notes", nil)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, POST", recorder.Header().Get("Allow"))
+	assert.Equal(t, "application/cbor, application/json", recorder.Header().Get("Accept-Post"))
+	assert.Empty(t, recorder.Header().Get("Accept-Patch"))
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = serveWithHeaders(router, http.MethodOptions, "/v1/notes", nil)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "OPTIONS, PATCH", recorder.Header().Get("Allow"))
+	assert.Equal(t, "application/cbor, application/json", recorder.Header().Get("Accept-Patch"))
+	assert.Empty(t, recorder.Header().Get("Accept-Post"))
+}
+
+func TestMethodRouter_MethodNotAllowed(t *testing.T) {
+	router := newMethodRouterTestAPI(t)
+
+	recorder := serveWithHeaders(router, http.MethodDelete, "/notes", nil)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, POST", recorder.Header().Get("Allow"))
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+	var problem ErrorModel
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusMethodNotAllowed, problem.Status)
+	assert.Equal(t, "method DELETE is not allowed, allowed methods: GET, HEAD, OPTIONS, POST", problem.Detail)
+}
+
+func TestMethodRouter_HeadFallsBackToGet(t *testing.T) {
+	router := newMethodRouterTestAPI(t)
+
+	recorder := serveWithHeaders(router, http.MethodHead, "/notes", nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestMethodRouter_CustomOptionsHandler(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/notes", func(ctx context.Context, input *struct{}) (*CreateNoteOutput, error) {
+		return &CreateNoteOutput{}, nil
+	})
+	err := Register(api, BaseRoute{Method: http.MethodOptions, Path: "/notes"},
+		func(ctx context.Context, input *struct{}) (*struct{}, error) {
+			return nil, nil
+		})
+	require.NoError(t, err)
+
+	// The registered OPTIONS operation replaces the automatic response.
+	recorder := serveWithHeaders(router, http.MethodOptions, "/notes", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Allow"))
+
+	recorder = serveWithHeaders(router, http.MethodPut, "/notes", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", recorder.Header().Get("Allow"))
+}
+
+func TestMethodRouter_UnknownPath(t *testing.T) {
+	router := newMethodRouterTestAPI(t)
+
+	recorder := serveWithHeaders(router, http.MethodGet, "/unknown", nil)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.False(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "application/problem"))
+}
@@ -0,0 +1,106 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newNegotiationTestAPI(t *testing.T, noFormatFallback bool, opts ...Option) (API, *chi.Mux) {
+	t.Helper()
+
+	router := chi.NewMux()
+	config := DefaultConfig()
+	config.NoFormatFallback = noFormatFallback
+	api := NewAPI(&testChiAdapter{router: router}, append([]Option{WithConfig(config)}, opts...)...)
+
+	Get(api, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*GetUserOutput, error) {
+		output := &GetUserOutput{}
+		output.Body.ID = input.ID
+		output.Body.Name = "John Doe"
+
+		return output, nil
+	})
+
+	return api, router
+}
+
+func TestNegotiate_FallbackToDefaultFormat(t *testing.T) {
+	_, router := newNegotiationTestAPI(t, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+}
+
+func TestNegotiate_NoFormatFallback(t *testing.T) {
+	_, router := newNegotiationTestAPI(t, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+	var problem ErrorModel
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusNotAcceptable, problem.Status)
+	assert.Equal(t, "Not Acceptable", problem.Title)
+	assert.Equal(t, "available media types: application/cbor, application/json", problem.Detail)
+
+	// Matching Accept headers are still served.
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Accept", "application/cbor")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/cbor", recorder.Header().Get("Content-Type"))
+}
+
+func TestNegotiate_NotAcceptableWithoutJSONFormat(t *testing.T) {
+	_, router := newNegotiationTestAPI(t, true,
+		WithFormatsReplace(map[string]Format{"application/cbor": CBORFormat()}),
+		WithDefaultFormat("application/cbor"),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Accept", "text/html")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// The 406 is rendered as JSON even though JSON is not a negotiable format.
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+	var problem ErrorModel
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "available media types: application/cbor", problem.Detail)
+}
+
+func TestNegotiate_OpenAPIMediaTypes(t *testing.T) {
+	api, _ := newNegotiationTestAPI(t, true)
+
+	responses := api.OpenAPI().Paths["/users/{id}"].Get.Responses
+	assert.Contains(t, responses["200"].Content, "application/json")
+	assert.Contains(t, responses["200"].Content, "application/cbor")
+
+	require.Contains(t, responses, "406")
+	assert.Contains(t, responses["406"].Content, "application/problem+json")
+	assert.Contains(t, responses["406"].Content, "application/problem+cbor")
+
+	lenient, _ := newNegotiationTestAPI(t, false)
+	assert.NotContains(t, lenient.OpenAPI().Paths["/users/{id}"].Get.Responses, "406")
+}
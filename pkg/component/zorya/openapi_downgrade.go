@@ -0,0 +1,320 @@
+package zorya
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPI30Version is the version reported by downgraded specs.
+const openAPI30Version = "3.0.3"
+
+// YAML returns the OpenAPI represented as YAML.
+func (o *OpenAPI) YAML() ([]byte, error) {
+	specJSON, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonToYAML(specJSON)
+}
+
+// Downgrade returns the OpenAPI 3.1 spec converted to OpenAPI 3.0.3 as JSON,
+// for tools and code generators that do not support 3.1 yet. The conversion
+// is lossy where 3.0 has no equivalent:
+//   - `type: [T, "null"]` becomes `type: T` with `nullable: true`
+//   - `examples` becomes `example` (using the first example)
+//   - numeric `exclusiveMinimum`/`exclusiveMaximum` become `minimum`/`maximum`
+//     with the boolean exclusive flag
+//   - `const` becomes a single-value `enum`
+//   - `contentEncoding: base64` becomes `format: byte`
+//   - keywords unknown to 3.0 (e.g. `dependentRequired`) are dropped
+func (o *OpenAPI) Downgrade() ([]byte, error) {
+	specJSON, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return downgradeSpec(specJSON)
+}
+
+// DowngradeYAML returns the OpenAPI 3.1 spec converted to OpenAPI 3.0.3 as YAML.
+// See Downgrade for details of the conversion.
+func (o *OpenAPI) DowngradeYAML() ([]byte, error) {
+	specJSON, err := o.Downgrade()
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonToYAML(specJSON)
+}
+
+// jsonToYAML converts a JSON document to YAML. Object keys are sorted, matching
+// the JSON output of the OpenAPI types.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var v any
+	if err := unmarshalNumbers(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	out, err := yaml.Marshal(numberValues(v))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	return out, nil
+}
+
+// downgradeSpec converts a marshaled OpenAPI 3.1 document into OpenAPI 3.0.3.
+func downgradeSpec(specJSON []byte) ([]byte, error) {
+	var spec map[string]any
+	if err := unmarshalNumbers(specJSON, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI spec: %w", err)
+	}
+
+	spec["openapi"] = openAPI30Version
+	delete(spec, "jsonSchemaDialect")
+	delete(spec, "webhooks")
+	if _, ok := spec["paths"]; !ok {
+		// Paths are required in 3.0.
+		spec["paths"] = map[string]any{}
+	}
+
+	if info, ok := spec["info"].(map[string]any); ok {
+		delete(info, "summary")
+		if license, ok := info["license"].(map[string]any); ok {
+			delete(license, "identifier")
+		}
+	}
+
+	if components, ok := spec["components"].(map[string]any); ok {
+		downgradeSchemaMap(components["schemas"])
+		for _, param := range asMap(components["parameters"]) {
+			downgradeParam(param)
+		}
+		for _, header := range asMap(components["headers"]) {
+			downgradeParam(header)
+		}
+		for _, resp := range asMap(components["responses"]) {
+			downgradeResponse(resp)
+		}
+		for _, body := range asMap(components["requestBodies"]) {
+			downgradeContent(asMap(body)["content"])
+		}
+		delete(components, "pathItems")
+	}
+
+	for _, pathItem := range asMap(spec["paths"]) {
+		downgradePathItem(pathItem)
+	}
+
+	return json.Marshal(spec)
+}
+
+// downgradePathItem converts all operations of a path item.
+func downgradePathItem(v any) {
+	pathItem := asMap(v)
+	for _, param := range asSlice(pathItem["parameters"]) {
+		downgradeParam(param)
+	}
+
+	for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+		op := asMap(pathItem[method])
+		if op == nil {
+			continue
+		}
+		for _, param := range asSlice(op["parameters"]) {
+			downgradeParam(param)
+		}
+		if body := asMap(op["requestBody"]); body != nil {
+			downgradeContent(body["content"])
+		}
+		for _, resp := range asMap(op["responses"]) {
+			downgradeResponse(resp)
+		}
+		for _, callback := range asMap(op["callbacks"]) {
+			for _, item := range asMap(callback) {
+				downgradePathItem(item)
+			}
+		}
+	}
+}
+
+// downgradeResponse converts the headers and content of a response.
+func downgradeResponse(v any) {
+	resp := asMap(v)
+	for _, header := range asMap(resp["headers"]) {
+		downgradeParam(header)
+	}
+	downgradeContent(resp["content"])
+}
+
+// downgradeParam converts the schema and content of a parameter or header.
+func downgradeParam(v any) {
+	param := asMap(v)
+	if s := asMap(param["schema"]); s != nil {
+		downgradeSchema(s)
+	}
+	downgradeContent(param["content"])
+}
+
+// downgradeContent converts the schemas of a content map.
+func downgradeContent(v any) {
+	for _, mt := range asMap(v) {
+		if s := asMap(asMap(mt)["schema"]); s != nil {
+			downgradeSchema(s)
+		}
+	}
+}
+
+// downgradeSchemaMap converts every schema in a name to schema map.
+func downgradeSchemaMap(v any) {
+	for _, s := range asMap(v) {
+		if sm := asMap(s); sm != nil {
+			downgradeSchema(sm)
+		}
+	}
+}
+
+// downgradeSchema converts a JSON Schema 2020-12 object into an OpenAPI 3.0 schema object in place.
+func downgradeSchema(s map[string]any) {
+	downgradeType(s)
+
+	if examples, ok := s["examples"].([]any); ok {
+		if len(examples) > 0 {
+			s["example"] = examples[0]
+		}
+		delete(s, "examples")
+	}
+
+	downgradeExclusiveBound(s, "exclusiveMinimum", "minimum")
+	downgradeExclusiveBound(s, "exclusiveMaximum", "maximum")
+
+	if c, ok := s["const"]; ok {
+		s["enum"] = []any{c}
+		delete(s, "const")
+	}
+
+	if s["contentEncoding"] == contentEncodingBase64 {
+		s["format"] = "byte"
+	}
+	for _, key := range []string{"contentEncoding", "contentMediaType", "dependentRequired", "$schema", "$id", "$defs"} {
+		delete(s, key)
+	}
+
+	// Recurse into subschemas.
+	downgradeSchemaMap(s["properties"])
+	if items := asMap(s["items"]); items != nil {
+		downgradeSchema(items)
+	}
+	if additional := asMap(s["additionalProperties"]); additional != nil {
+		downgradeSchema(additional)
+	}
+	if not := asMap(s["not"]); not != nil {
+		downgradeSchema(not)
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		for _, sub := range asSlice(s[key]) {
+			if sm := asMap(sub); sm != nil {
+				downgradeSchema(sm)
+			}
+		}
+	}
+}
+
+// downgradeType converts a type array into a single type with `nullable`.
+func downgradeType(s map[string]any) {
+	types, ok := s["type"].([]any)
+	if !ok {
+		return
+	}
+
+	nonNull := make([]any, 0, len(types))
+	for _, t := range types {
+		if t == "null" {
+			s["nullable"] = true
+		} else {
+			nonNull = append(nonNull, t)
+		}
+	}
+
+	switch len(nonNull) {
+	case 0:
+		delete(s, "type")
+	case 1:
+		s["type"] = nonNull[0]
+	default:
+		// 3.0 has no multi-type schemas, express them as alternatives.
+		delete(s, "type")
+		anyOf := make([]any, 0, len(nonNull))
+		for _, t := range nonNull {
+			anyOf = append(anyOf, map[string]any{"type": t})
+		}
+		s["anyOf"] = anyOf
+	}
+}
+
+// downgradeExclusiveBound converts a numeric exclusive bound into the 3.0
+// boolean form, e.g. `exclusiveMinimum: 5` becomes `minimum: 5, exclusiveMinimum: true`.
+func downgradeExclusiveBound(s map[string]any, exclusiveKey, boundKey string) {
+	bound, ok := s[exclusiveKey].(json.Number)
+	if !ok {
+		return
+	}
+
+	s[boundKey] = bound
+	s[exclusiveKey] = true
+}
+
+// unmarshalNumbers decodes JSON like json.Unmarshal, keeping numbers as
+// json.Number so integers beyond 2^53 survive a round trip.
+func unmarshalNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+// numberValues replaces the json.Number values of a decoded JSON value with
+// int64, uint64 beyond its range or float64 for non-integers, for encoders other than encoding/json.
+func numberValues(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u
+		}
+		f, _ := v.Float64()
+
+		return f
+	case map[string]any:
+		for key, value := range v {
+			v[key] = numberValues(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = numberValues(value)
+		}
+	}
+
+	return v
+}
+
+// asMap returns v as a JSON object, or nil if it is not one.
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+
+	return m
+}
+
+// asSlice returns v as a JSON array, or nil if it is not one.
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+
+	return s
+}
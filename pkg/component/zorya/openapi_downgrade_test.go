@@ -0,0 +1,162 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDowngrade_Schemas(t *testing.T) {
+	minimum := 0.0
+	openAPI := DefaultOpenAPI("API", "1.0.0")
+	openAPI.Components = &Components{
+		Schemas: map[string]*Schema{
+			"Item": {
+				Type: TypeObject,
+				Properties: map[string]*Schema{
+					"tags":     {Type: TypeArray, Nullable: boolPtr(true), Items: &Schema{Type: TypeString}},
+					"price":    {Type: TypeNumber, ExclusiveMinimum: &minimum, Examples: []any{9.99, 1.5}},
+					"kind":     {Type: TypeString, Const: "item"},
+					"data":     {Type: TypeString, ContentEncoding: contentEncodingBase64},
+					"children": {Type: TypeArray, Items: &Schema{Ref: "#/components/schemas/Item"}},
+				},
+				DependentRequired: map[string][]string{"price": {"kind"}},
+			},
+		},
+	}
+
+	b, err := openAPI.Downgrade()
+	require.NoError(t, err)
+
+	wantedJSON := `{
+  "components": {
+    "schemas": {
+      "Item": {
+        "properties": {
+          "children": {
+            "items": {
+              "$ref": "#/components/schemas/Item"
+            },
+            "type": "array"
+          },
+          "data": {
+            "format": "byte",
+            "type": "string"
+          },
+          "kind": {
+            "enum": ["item"],
+            "type": "string"
+          },
+          "price": {
+            "example": 9.99,
+            "exclusiveMinimum": true,
+            "minimum": 0,
+            "type": "number"
+          },
+          "tags": {
+            "items": {
+              "type": "string"
+            },
+            "nullable": true,
+            "type": "array"
+          }
+        },
+        "type": "object"
+      }
+    }
+  },
+  "info": {
+    "title": "API",
+    "version": "1.0.0"
+  },
+  "openapi": "3.0.3",
+  "paths": {}
+}`
+	assert.JSONEq(t, wantedJSON, string(b))
+}
+
+func TestDowngrade_LargeIntegers(t *testing.T) {
+	openAPI := DefaultOpenAPI("API", "1.0.0")
+	openAPI.Components = &Components{
+		Schemas: map[string]*Schema{
+			"Item": {
+				Type: TypeObject,
+				Properties: map[string]*Schema{
+					"id": {Type: TypeInteger, Format: "int64", Enum: []any{int64(9007199254740993)}, Default: int64(9007199254740995)},
+				},
+			},
+		},
+	}
+
+	b, err := openAPI.Downgrade()
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"enum":[9007199254740993]`)
+	assert.Contains(t, string(b), `"default":9007199254740995`)
+
+	b, err = openAPI.DowngradeYAML()
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "- 9007199254740993\n")
+	assert.Contains(t, string(b), "default: 9007199254740995\n")
+}
+
+func TestOpenAPIEndpoint_Variants(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/users/{id}", func(ctx context.Context, input *GetUserInput) (*GetUserOutput, error) {
+		return &GetUserOutput{}, nil
+	})
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code, path)
+
+		return recorder
+	}
+
+	var spec map[string]any
+
+	recorder := get("/openapi.yaml", "")
+	assert.Equal(t, "application/vnd.oai.openapi", recorder.Header().Get("Content-Type"))
+	require.NoError(t, yaml.Unmarshal(recorder.Body.Bytes(), &spec))
+	assert.Equal(t, "3.1.0", spec["openapi"])
+	assert.Contains(t, spec["paths"], "/users/{id}")
+
+	recorder = get("/openapi-3.0.json", "")
+	assert.Equal(t, "application/vnd.oai.openapi+json", recorder.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+
+	recorder = get("/openapi-3.0.yaml", "")
+	require.NoError(t, yaml.Unmarshal(recorder.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+
+	// The extension-less paths select the rendition by Accept header.
+	recorder = get("/openapi", "application/yaml")
+	assert.Equal(t, "application/vnd.oai.openapi", recorder.Header().Get("Content-Type"))
+
+	recorder = get("/openapi", "")
+	assert.Equal(t, "application/vnd.oai.openapi+json", recorder.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &spec))
+	assert.Equal(t, "3.1.0", spec["openapi"])
+
+	recorder = get("/openapi-3.0", "application/vnd.oai.openapi")
+	require.NoError(t, yaml.Unmarshal(recorder.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,141 @@
+package zorya
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/talav/talav/pkg/component/negotiation"
+)
+
+const (
+	contentTypeOpenAPIJSON = "application/vnd.oai.openapi+json"
+	contentTypeOpenAPIYAML = "application/vnd.oai.openapi"
+)
+
+// openAPISpecVariant is one rendition of the OpenAPI spec.
+type openAPISpecVariant int
+
+const (
+	specJSON openAPISpecVariant = iota
+	specYAML
+	spec30JSON
+	spec30YAML
+)
+
+// openAPISpecs lazily renders every variant of the spec from the same OpenAPI
+// value on first request, so the spec may be edited until the server starts.
+type openAPISpecs struct {
+	openAPI  *OpenAPI
+	once     sync.Once
+	variants [4][]byte
+	err      error
+}
+
+// get returns the rendered variant.
+func (s *openAPISpecs) get(variant openAPISpecVariant) ([]byte, error) {
+	s.once.Do(func() {
+		var b []byte
+		if b, s.err = s.openAPI.MarshalJSON(); s.err != nil {
+			return
+		}
+		s.variants[specJSON] = b
+
+		if s.variants[specYAML], s.err = jsonToYAML(b); s.err != nil {
+			return
+		}
+		if s.variants[spec30JSON], s.err = downgradeSpec(b); s.err != nil {
+			return
+		}
+		s.variants[spec30YAML], s.err = jsonToYAML(s.variants[spec30JSON])
+	})
+
+	return s.variants[variant], s.err
+}
+
+// openAPIBasePath returns the configured OpenAPI path without its `.json` extension.
+func openAPIBasePath(config *Config) string {
+	return strings.TrimSuffix(config.OpenAPIPath, ".json")
+}
+
+// registerOpenAPIEndpoint registers the OpenAPI spec endpoints if configured.
+// For a base path of `/openapi` it serves:
+//   - `/openapi.json` and `/openapi.yaml`: the OpenAPI 3.1 spec
+//   - `/openapi-3.0.json` and `/openapi-3.0.yaml`: the spec downgraded to OpenAPI 3.0.3
+//   - `/openapi` and `/openapi-3.0`: JSON or YAML, selected by the Accept header
+func registerOpenAPIEndpoint(a API, adapter Adapter) {
+	if a.Config().OpenAPIPath == "" {
+		return
+	}
+
+	base := openAPIBasePath(a.Config())
+	specs := &openAPISpecs{openAPI: a.OpenAPI()}
+	negotiator := negotiation.NewMediaNegotiator()
+
+	handle := func(path string, serve func(r *http.Request) openAPISpecVariant) {
+		adapter.Handle(&BaseRoute{
+			Method: http.MethodGet,
+			Path:   path,
+		}, func(w http.ResponseWriter, r *http.Request) {
+			variant := serve(r)
+			b, err := specs.get(variant)
+			if err != nil {
+				WriteErr(a, r, w, http.StatusInternalServerError, "failed to marshal OpenAPI spec", err)
+
+				return
+			}
+
+			ct := contentTypeOpenAPIJSON
+			if variant == specYAML || variant == spec30YAML {
+				ct = contentTypeOpenAPIYAML
+			}
+			w.Header().Set("Content-Type", ct)
+			w.Header().Add("Vary", "Accept")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(b)
+		})
+	}
+
+	fixed := func(variant openAPISpecVariant) func(*http.Request) openAPISpecVariant {
+		return func(*http.Request) openAPISpecVariant { return variant }
+	}
+	negotiated := func(jsonVariant, yamlVariant openAPISpecVariant) func(*http.Request) openAPISpecVariant {
+		return func(r *http.Request) openAPISpecVariant {
+			if wantsYAML(negotiator, r.Header.Get("Accept")) {
+				return yamlVariant
+			}
+
+			return jsonVariant
+		}
+	}
+
+	handle(base+".json", fixed(specJSON))
+	handle(base+".yaml", fixed(specYAML))
+	handle(base+"-3.0.json", fixed(spec30JSON))
+	handle(base+"-3.0.yaml", fixed(spec30YAML))
+	handle(base, negotiated(specJSON, specYAML))
+	handle(base+"-3.0", negotiated(spec30JSON, spec30YAML))
+}
+
+// wantsYAML reports whether the Accept header prefers a YAML rendition of the spec.
+// JSON is used when the header is missing or matches nothing.
+func wantsYAML(negotiator *negotiation.Negotiator, accept string) bool {
+	if accept == "" {
+		return false
+	}
+
+	priorities := []string{
+		contentTypeOpenAPIJSON,
+		contentTypeJSON,
+		contentTypeOpenAPIYAML,
+		"application/yaml",
+		"application/x-yaml",
+		"text/yaml",
+	}
+	header, err := negotiator.Negotiate(accept, priorities, false)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(header.Type, "yaml") || header.Type == contentTypeOpenAPIYAML
+}
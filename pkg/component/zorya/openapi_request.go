@@ -13,6 +13,8 @@ import (
 type requestSchemaExtractor struct {
 	registry Registry
 	metadata *schema.Metadata
+	// contentTypes lists the media types structured bodies can be decoded from.
+	contentTypes []string
 }
 
 // NewRequestSchemaExtractor creates a new request schema extractor.
@@ -45,7 +47,9 @@ func (e *requestSchemaExtractor) RequestFromType(inputType reflect.Type, op *Ope
 
 	// Process parameters (fields with "schema" tag, excluding body)
 	// Parameters can be in path, query, header, or cookie locations
-	e.extractParameters(structMeta, op, inputType)
+	if err := e.extractParameters(structMeta, op, inputType); err != nil {
+		return fmt.Errorf("failed to extract parameters: %w", err)
+	}
 
 	// Process request body (field with "body" tag)
 	// Body is handled separately as it's not a parameter
@@ -59,10 +63,23 @@ func (e *requestSchemaExtractor) RequestFromType(inputType reflect.Type, op *Ope
 // extractParameters extracts OpenAPI parameters from struct fields with "schema" tag.
 // Skips fields with "body" tag (handled separately).
 // Only processes valid parameter locations: path, query, header, cookie.
-func (e *requestSchemaExtractor) extractParameters(structMeta *schema.StructMetadata, op *Operation, inputType reflect.Type) {
+// Parameters of embedded structs are promoted, as when decoding requests.
+func (e *requestSchemaExtractor) extractParameters(structMeta *schema.StructMetadata, op *Operation, inputType reflect.Type) error {
 	for i := range structMeta.Fields {
 		field := &structMeta.Fields[i]
 
+		if field.Embedded && field.Type.Kind() == reflect.Struct && !field.HasTag("body") {
+			embeddedMeta, err := e.metadata.GetStructMetadata(field.Type)
+			if err != nil {
+				return fmt.Errorf("failed to get struct metadata for type %s: %w", field.Type, err)
+			}
+			if err := e.extractParameters(embeddedMeta, op, field.Type); err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		// Get schema metadata (must have "schema" tag)
 		schemaMeta, ok := schema.GetTagMetadata[*schema.SchemaMetadata](field, "schema")
 		if !ok {
@@ -93,6 +110,8 @@ func (e *requestSchemaExtractor) extractParameters(structMeta *schema.StructMeta
 			Explode:     &schemaMeta.Explode,
 		})
 	}
+
+	return nil
 }
 
 // extractRequestBody extracts OpenAPI request body from struct field with "body" tag.
@@ -120,12 +139,14 @@ func (e *requestSchemaExtractor) extractRequestBody(structMeta *schema.StructMet
 		op.RequestBody.Required = true
 	}
 
-	// Determine content type based on BodyType
-	contentType := getContentType(bodyMeta.BodyType)
+	// Determine content types based on BodyType
+	contentTypes := e.determineContentTypes(bodyMeta.BodyType)
 
-	// Initialize content map entry if needed
-	if op.RequestBody.Content[contentType] == nil {
-		op.RequestBody.Content[contentType] = &MediaType{}
+	// Initialize content map entries if needed
+	for _, contentType := range contentTypes {
+		if op.RequestBody.Content[contentType] == nil {
+			op.RequestBody.Content[contentType] = &MediaType{}
+		}
 	}
 
 	// Generate schema for body type
@@ -144,14 +165,27 @@ func (e *requestSchemaExtractor) extractRequestBody(structMeta *schema.StructMet
 		if bodyMeta.BodyType == schema.BodyTypeMultipart {
 			bodySchema = transformSchemaForMultipart(bodySchema)
 			// Add encoding object for binary fields
-			op.RequestBody.Content[contentType].Encoding = extractMultipartEncoding(bodySchema)
+			op.RequestBody.Content[contentTypeMultipart].Encoding = extractMultipartEncoding(bodySchema)
+		}
+		for _, contentType := range contentTypes {
+			op.RequestBody.Content[contentType].Schema = bodySchema
 		}
-		op.RequestBody.Content[contentType].Schema = bodySchema
 	}
 
 	return nil
 }
 
+// determineContentTypes determines the request content types for a body type.
+// Structured bodies are documented with every media type they can be decoded from.
+func (e *requestSchemaExtractor) determineContentTypes(bodyType schema.BodyType) []string {
+	ct := getContentType(bodyType)
+	if ct != contentTypeJSON || len(e.contentTypes) == 0 {
+		return []string{ct}
+	}
+
+	return e.contentTypes
+}
+
 // initRequestBody initializes the RequestBody on the operation if it's nil.
 // Creates an empty Content map ready for media type entries.
 func initRequestBody(op *Operation) {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 
 	"github.com/talav/talav/pkg/component/schema"
@@ -23,6 +25,13 @@ type ResponseSchemaExtractor struct {
 	registry Registry
 	builder  SchemaBuilder
 	metadata *schema.Metadata
+
+	// contentTypes lists the media types structured bodies can be negotiated to.
+	// If empty, only application/json is documented.
+	contentTypes []string
+
+	// strictNegotiation documents a 406 response on every operation.
+	strictNegotiation bool
 }
 
 // NewResponseSchemaExtractor creates a new response schema extractor.
@@ -41,7 +50,7 @@ func NewResponseSchemaExtractor(registry Registry, builder SchemaBuilder, metada
 // - Success response: Generated from Body field (one response, default status).
 // - Error responses: Generated from route.Errors list.
 // - Headers: Generated from fields with "header" tag.
-// - Automatic error additions: 422 (if inputs exist) and 500 (always).
+// - Automatic error additions: 406 (strict negotiation), 422 (if inputs exist) and 500 (always).
 func (e *ResponseSchemaExtractor) ResponseFromType(outputType reflect.Type, route *BaseRoute) error {
 	structMeta, err := e.metadata.GetStructMetadata(outputType)
 	if err != nil {
@@ -64,7 +73,9 @@ func (e *ResponseSchemaExtractor) ResponseFromType(outputType reflect.Type, rout
 	}
 
 	// Extract body schema and add to response
-	if err := e.extractBodySchema(bodyField, resp, structMeta.Type, route.Operation); err != nil {
+	if bodyField.Type.Implements(eventStreamBodyType) {
+		e.extractEventStreamSchema(bodyField, resp, structMeta.Type, route)
+	} else if err := e.extractBodySchema(bodyField, resp, structMeta.Type, route.Operation); err != nil {
 		return err
 	}
 
@@ -111,12 +122,14 @@ func (e *ResponseSchemaExtractor) extractBodySchema(
 		return fmt.Errorf("body field missing body metadata")
 	}
 
-	// Determine content type
-	ct := determineContentType(bodyField, bodyMeta)
+	// Determine content types
+	contentTypes := e.determineContentTypes(bodyField, bodyMeta)
 
-	// Initialize media type if needed (only if Content is empty)
+	// Initialize media types if needed (only if Content is empty)
 	if len(resp.Content) == 0 {
-		resp.Content[ct] = &MediaType{}
+		for _, ct := range contentTypes {
+			resp.Content[ct] = &MediaType{}
+		}
 	}
 
 	// Generate schema and set it if successful
@@ -127,28 +140,103 @@ func (e *ResponseSchemaExtractor) extractBodySchema(
 		if bodyMeta.BodyType == schema.BodyTypeFile {
 			bodySchema = transformSchemaForFileResponse(bodySchema)
 		}
-		if resp.Content[ct] != nil && resp.Content[ct].Schema == nil {
-			resp.Content[ct].Schema = bodySchema
+		for _, ct := range contentTypes {
+			if resp.Content[ct] != nil && resp.Content[ct].Schema == nil {
+				resp.Content[ct].Schema = bodySchema
+			}
 		}
 	}
 
 	return nil
 }
 
-// determineContentType determines the content type for a body field.
-func determineContentType(bodyField *schema.FieldMetadata, bodyMeta *schema.BodyMetadata) string {
+// extractEventStreamSchema documents an EventStream body as a text/event-stream response.
+// The schema is an array of events, with one oneOf entry per event type so that
+// each event's data schema comes from the registry.
+func (e *ResponseSchemaExtractor) extractEventStreamSchema(
+	bodyField *schema.FieldMetadata,
+	resp *Response,
+	structType reflect.Type,
+	route *BaseRoute,
+) {
+	if _, ok := resp.Content[contentTypeEventStream]; ok {
+		return
+	}
+
+	events := route.Events
+	if len(events) == 0 {
+		dataType := reflect.New(bodyField.Type).Elem().Interface().(eventStreamBody).eventDataType()
+		events = map[string]reflect.Type{defaultEventName: dataType}
+	}
+
+	names := make([]string, 0, len(events))
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	oneOf := make([]*Schema, 0, len(names))
+	for _, name := range names {
+		hint := getResponseHint(structType, bodyField.StructFieldName, route.Operation.OperationID) + name
+		oneOf = append(oneOf, &Schema{
+			Type:  TypeObject,
+			Title: "Event " + name,
+			Properties: map[string]*Schema{
+				"id":    {Type: TypeString, Description: "Event ID, sent back by the client in Last-Event-ID on reconnect"},
+				"event": {Type: TypeString, Const: name, Description: "Event type"},
+				"data":  e.registry.Schema(events[name], true, hint),
+				"retry": {Type: TypeInteger, Description: "Reconnection delay in milliseconds"},
+			},
+			Required: []string{"data"},
+		})
+	}
+
+	resp.Content[contentTypeEventStream] = &MediaType{
+		Schema: &Schema{
+			Type:        TypeArray,
+			Description: "Server-Sent Events stream",
+			Items:       &Schema{OneOf: oneOf},
+		},
+	}
+}
+
+// determineContentTypes determines the response content types for a body field.
+// Structured bodies are documented with every negotiable media type.
+func (e *ResponseSchemaExtractor) determineContentTypes(bodyField *schema.FieldMetadata, bodyMeta *schema.BodyMetadata) []string {
 	// Determine content type based on BodyType (same logic as requests)
 	ct := getContentType(bodyMeta.BodyType)
+	if ct != contentTypeJSON {
+		return []string{ct}
+	}
 
 	// Fallback to ContentTypeProvider interface if needed
-	if ct == contentTypeJSON && reflect.PointerTo(bodyField.Type).Implements(reflect.TypeOf((*ContentTypeProvider)(nil)).Elem()) {
-		instance, ok := reflect.New(bodyField.Type).Interface().(ContentTypeProvider)
-		if ok {
-			ct = instance.ContentType(ct)
+	var provider ContentTypeProvider
+	if reflect.PointerTo(bodyField.Type).Implements(reflect.TypeOf((*ContentTypeProvider)(nil)).Elem()) {
+		provider, _ = reflect.New(bodyField.Type).Interface().(ContentTypeProvider)
+	}
+
+	return e.negotiableContentTypes(provider)
+}
+
+// negotiableContentTypes returns the media types a structured body can be served as,
+// adjusted by the body's ContentTypeProvider if it has one.
+func (e *ResponseSchemaExtractor) negotiableContentTypes(provider ContentTypeProvider) []string {
+	contentTypes := e.contentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = []string{contentTypeJSON}
+	}
+
+	result := make([]string, 0, len(contentTypes))
+	for _, ct := range contentTypes {
+		if provider != nil {
+			ct = provider.ContentType(ct)
+		}
+		if !slices.Contains(result, ct) {
+			result = append(result, ct)
 		}
 	}
 
-	return ct
+	return result
 }
 
 // extractHeaderSchemas extracts header schemas from fields with "schema" tag and location=header
@@ -212,17 +300,20 @@ func (e *ResponseSchemaExtractor) defineErrorResponses(
 	errorType := reflect.TypeOf((*ErrorModel)(nil)).Elem()
 	errorSchema := e.registry.Schema(errorType, true, "Error")
 
-	// Determine error content type
+	// Determine error content types
 	exampleErr := NewError(0, "")
-	errContentType := contentTypeJSON
-	if ctf, ok := exampleErr.(ContentTypeProvider); ok {
-		errContentType = ctf.ContentType(errContentType)
-	}
+	ctf, _ := exampleErr.(ContentTypeProvider)
+	errContentTypes := e.negotiableContentTypes(ctf)
 
 	// Process user-specified errors
 	errorsToAdd := make([]int, 0, len(route.Errors)+2)
 	errorsToAdd = append(errorsToAdd, route.Errors...)
 
+	// Automatically add 406 if unmatched Accept headers are rejected
+	if e.strictNegotiation {
+		errorsToAdd = append(errorsToAdd, http.StatusNotAcceptable)
+	}
+
 	// Automatically add 422 if there are input parameters or body
 	if hasInputParams || hasInputBody {
 		errorsToAdd = append(errorsToAdd, http.StatusUnprocessableEntity)
@@ -235,13 +326,73 @@ func (e *ResponseSchemaExtractor) defineErrorResponses(
 	for _, code := range errorsToAdd {
 		response := getResponse(op, code)
 		if response.Content == nil {
-			response.Content = map[string]*MediaType{
-				errContentType: {
-					Schema: errorSchema,
-				},
+			response.Content = make(map[string]*MediaType, len(errContentTypes))
+			for _, ct := range errContentTypes {
+				response.Content[ct] = &MediaType{Schema: errorSchema}
 			}
 		}
 	}
+
+	// Document declared problem types as a oneOf under their status codes
+	for code, problems := range groupProblems(route.Problems) {
+		response := getResponse(op, code)
+		for _, ct := range errContentTypes {
+			response.Content[ct] = &MediaType{Schema: e.problemsSchema(errorSchema, problems)}
+		}
+	}
+}
+
+// groupProblems groups problem types by status, without duplicates.
+func groupProblems(problems []ProblemInfo) map[int][]ProblemInfo {
+	grouped := make(map[int][]ProblemInfo)
+	for _, problem := range problems {
+		if !slices.ContainsFunc(grouped[problem.Status], func(p ProblemInfo) bool { return p.Type == problem.Type }) {
+			grouped[problem.Status] = append(grouped[problem.Status], problem)
+		}
+	}
+
+	return grouped
+}
+
+// problemsSchema returns the schema of the problems of a status: one of the
+// declared problem types, or a generic error of another type.
+func (e *ResponseSchemaExtractor) problemsSchema(errorSchema *Schema, problems []ProblemInfo) *Schema {
+	oneOf := make([]*Schema, 0, len(problems)+1)
+	types := make([]any, 0, len(problems))
+	for _, problem := range problems {
+		oneOf = append(oneOf, e.problemSchema(errorSchema, problem))
+		types = append(types, problem.Type)
+	}
+
+	return &Schema{OneOf: append(oneOf, &Schema{
+		Description: "Problem of another type.",
+		AllOf:       []*Schema{errorSchema},
+		Not: &Schema{
+			Type:       TypeObject,
+			Properties: map[string]*Schema{"type": {Type: TypeString, Enum: types}},
+			Required:   []string{"type"},
+		},
+	})}
+}
+
+// problemSchema returns the schema of a problem type: the error schema, the
+// extension members and the type URI.
+func (e *ResponseSchemaExtractor) problemSchema(errorSchema *Schema, problem ProblemInfo) *Schema {
+	allOf := []*Schema{errorSchema}
+	if problem.Extensions != nil {
+		allOf = append(allOf, e.registry.Schema(problem.Extensions, true, problem.Extensions.Name()))
+	}
+	allOf = append(allOf, &Schema{
+		Type:       TypeObject,
+		Properties: map[string]*Schema{"type": {Type: TypeString, Enum: []any{problem.Type}}},
+		Required:   []string{"type"},
+	})
+
+	return &Schema{
+		Title:       problem.Title,
+		Description: problem.Description,
+		AllOf:       allOf,
+	}
 }
 
 // getResponse ensures a response exists for the given status code.
@@ -1529,17 +1529,6 @@ func DefaultOpenAPI(title, version string) *OpenAPI {
 	}
 }
 
-// YAML returns the OpenAPI represented as YAML without needing to include a
-// library to serialize YAML.
-// func (o *OpenAPI) YAML() ([]byte, error) {
-// 	specJSON, err := json.Marshal(o)
-// 	buf := bytes.NewBuffer([]byte{})
-// 	if err == nil {
-// 		err = yaml.Convert(buf, bytes.NewReader(specJSON))
-// 	}
-// 	return buf.Bytes(), err
-// }
-
 // Schema represents a JSON Schema compatible with OpenAPI 3.1. It is extensible
 // with your own custom properties. It supports a subset of the full JSON Schema
 // spec, designed specifically for use with Go structs and to enable fast zero
@@ -0,0 +1,345 @@
+package zorya
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// patternCache holds compiled schema patterns, keyed by pattern string.
+var patternCache sync.Map
+
+// schemaValidator validates JSON-decoded values (nil, bool, float64, string,
+// []any and map[string]any) against OpenAPI schemas. References are resolved
+// through the registry.
+type schemaValidator struct {
+	registry Registry
+}
+
+// newSchemaValidator creates a validator resolving references through the registry.
+func newSchemaValidator(registry Registry) *schemaValidator {
+	return &schemaValidator{registry: registry}
+}
+
+// Validate validates value against s and returns every violation found.
+// Locations start at location and use the `body.items[3].tags` notation.
+func (v *schemaValidator) Validate(s *Schema, location string, value any) []*ErrorDetail {
+	var errs []*ErrorDetail
+	v.validate(s, location, value, &errs)
+
+	return errs
+}
+
+func (v *schemaValidator) validate(s *Schema, location string, value any, errs *[]*ErrorDetail) {
+	if s == nil {
+		return
+	}
+
+	if s.Ref != "" {
+		if resolved := v.registry.SchemaFromRef(s.Ref); resolved != nil {
+			v.validate(resolved, location, value, errs)
+		}
+
+		return
+	}
+
+	addErr := func(code, format string, args ...any) {
+		*errs = append(*errs, &ErrorDetail{Code: code, Message: fmt.Sprintf(format, args...), Location: location})
+	}
+
+	if value == nil {
+		if s.Type != "" && (s.Nullable == nil || !*s.Nullable) {
+			addErr("type", "expected %s, got null", s.Type)
+		}
+
+		return
+	}
+
+	if s.Type != "" && !matchesType(s.Type, value) {
+		addErr("type", "expected %s, got %s", s.Type, jsonTypeOf(value))
+
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return jsonEqual(e, value) }) {
+		addErr("enum", "value must be one of %v", s.Enum)
+	}
+	if s.Const != nil && !jsonEqual(s.Const, value) {
+		addErr("const", "value must be %v", s.Const)
+	}
+
+	switch val := value.(type) {
+	case float64:
+		v.validateNumber(s, val, addErr)
+	case string:
+		v.validateString(s, val, addErr)
+	case []any:
+		v.validateArray(s, location, val, errs, addErr)
+	case map[string]any:
+		v.validateObject(s, location, val, errs, addErr)
+	}
+
+	v.validateComposition(s, location, value, errs, addErr)
+}
+
+func (v *schemaValidator) validateNumber(s *Schema, num float64, addErr func(code, format string, args ...any)) {
+	if s.Minimum != nil && num < *s.Minimum {
+		addErr("minimum", "expected number >= %v", *s.Minimum)
+	}
+	if s.ExclusiveMinimum != nil && num <= *s.ExclusiveMinimum {
+		addErr("exclusiveMinimum", "expected number > %v", *s.ExclusiveMinimum)
+	}
+	if s.Maximum != nil && num > *s.Maximum {
+		addErr("maximum", "expected number <= %v", *s.Maximum)
+	}
+	if s.ExclusiveMaximum != nil && num >= *s.ExclusiveMaximum {
+		addErr("exclusiveMaximum", "expected number < %v", *s.ExclusiveMaximum)
+	}
+	if s.MultipleOf != nil && *s.MultipleOf != 0 {
+		if q := num / *s.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			addErr("multipleOf", "expected number to be a multiple of %v", *s.MultipleOf)
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(s *Schema, str string, addErr func(code, format string, args ...any)) {
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		addErr("minLength", "expected length >= %d", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		addErr("maxLength", "expected length <= %d", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err == nil && !re.MatchString(str) {
+			msg := "expected string to match pattern " + s.Pattern
+			if s.PatternDescription != "" {
+				msg = "expected string to be " + s.PatternDescription
+			}
+			addErr("pattern", "%s", msg)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(
+	s *Schema,
+	location string,
+	arr []any,
+	errs *[]*ErrorDetail,
+	addErr func(code, format string, args ...any),
+) {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		addErr("minItems", "expected array length >= %d", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		addErr("maxItems", "expected array length <= %d", *s.MaxItems)
+	}
+	if s.UniqueItems != nil && *s.UniqueItems {
+		for i := range arr {
+			if slices.ContainsFunc(arr[:i], func(e any) bool { return jsonEqual(e, arr[i]) }) {
+				addErr("uniqueItems", "expected array items to be unique")
+
+				break
+			}
+		}
+	}
+
+	for i, item := range arr {
+		v.validate(s.Items, fmt.Sprintf("%s[%d]", location, i), item, errs)
+	}
+}
+
+func (v *schemaValidator) validateObject(
+	s *Schema,
+	location string,
+	obj map[string]any,
+	errs *[]*ErrorDetail,
+	addErr func(code, format string, args ...any),
+) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, &ErrorDetail{
+				Code:     "required",
+				Message:  "expected required property " + name + " to be present",
+				Location: location + "." + name,
+			})
+		}
+	}
+
+	if s.MinProperties != nil && len(obj) < *s.MinProperties {
+		addErr("minProperties", "expected object with at least %d properties", *s.MinProperties)
+	}
+	if s.MaxProperties != nil && len(obj) > *s.MaxProperties {
+		addErr("maxProperties", "expected object with at most %d properties", *s.MaxProperties)
+	}
+
+	for name, dependents := range s.DependentRequired {
+		if _, ok := obj[name]; !ok {
+			continue
+		}
+		for _, dependent := range dependents {
+			if _, ok := obj[dependent]; !ok {
+				addErr("dependentRequired", "expected property %s to be present when %s is present", dependent, name)
+			}
+		}
+	}
+
+	// Iterate in a stable order so violations are reported deterministically.
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propLocation := location + "." + name
+		if prop, ok := s.Properties[name]; ok {
+			v.validate(prop, propLocation, obj[name], errs)
+
+			continue
+		}
+
+		switch additional := s.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, &ErrorDetail{
+					Code:     "additionalProperties",
+					Message:  "unexpected property " + name,
+					Location: propLocation,
+				})
+			}
+		case *Schema:
+			v.validate(additional, propLocation, obj[name], errs)
+		}
+	}
+}
+
+func (v *schemaValidator) validateComposition(
+	s *Schema,
+	location string,
+	value any,
+	errs *[]*ErrorDetail,
+	addErr func(code, format string, args ...any),
+) {
+	for _, sub := range s.AllOf {
+		v.validate(sub, location, value, errs)
+	}
+
+	if len(s.AnyOf) > 0 && v.countMatches(s.AnyOf, location, value) == 0 {
+		addErr("anyOf", "expected value to match at least one schema")
+	}
+
+	if len(s.OneOf) > 0 {
+		if matches := v.countMatches(s.OneOf, location, value); matches != 1 {
+			addErr("oneOf", "expected value to match exactly one schema but matched %d", matches)
+		}
+	}
+
+	if s.Not != nil && len(v.Validate(s.Not, location, value)) == 0 {
+		addErr("not", "expected value to not match schema")
+	}
+}
+
+// countMatches returns how many of the schemas validate the value.
+func (v *schemaValidator) countMatches(schemas []*Schema, location string, value any) int {
+	matches := 0
+	for _, sub := range schemas {
+		if len(v.Validate(sub, location, value)) == 0 {
+			matches++
+		}
+	}
+
+	return matches
+}
+
+// compilePattern returns the compiled regular expression for a pattern, caching it.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		//nolint:forcetypeassert // Only *regexp.Regexp values are stored
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+
+	return re, nil
+}
+
+// matchesType reports whether a JSON-decoded value has the given schema type.
+func matchesType(typ string, value any) bool {
+	switch typ {
+	case TypeObject:
+		_, ok := value.(map[string]any)
+
+		return ok
+	case TypeArray:
+		_, ok := value.([]any)
+
+		return ok
+	case TypeString:
+		_, ok := value.(string)
+
+		return ok
+	case TypeNumber:
+		_, ok := value.(float64)
+
+		return ok
+	case TypeInteger:
+		num, ok := value.(float64)
+
+		return ok && num == math.Trunc(num)
+	case TypeBoolean:
+		_, ok := value.(bool)
+
+		return ok
+	default:
+		return true
+	}
+}
+
+// jsonTypeOf returns the JSON type name of a JSON-decoded value.
+func jsonTypeOf(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return TypeObject
+	case []any:
+		return TypeArray
+	case string:
+		return TypeString
+	case float64:
+		return TypeNumber
+	case bool:
+		return TypeBoolean
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// jsonEqual compares a schema value (e.g. an enum entry built from Go values)
+// with a JSON-decoded value. Numbers are compared by value regardless of Go type.
+func jsonEqual(schemaValue, value any) bool {
+	if num, ok := value.(float64); ok {
+		rv := reflect.ValueOf(schemaValue)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(rv.Int()) == num
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(rv.Uint()) == num
+		case reflect.Float32, reflect.Float64:
+			return rv.Float() == num
+		default:
+			return false
+		}
+	}
+
+	return reflect.DeepEqual(schemaValue, value)
+}
@@ -0,0 +1,517 @@
+// Package openapidiff compares two OpenAPI documents and classifies the
+// changes between them as breaking or non-breaking for API consumers.
+//
+// A change is breaking when a client written against the base document can
+// fail against the revision: an operation or property it uses is removed, a
+// request it sends is rejected because a field became required or a type,
+// enum or constraint was narrowed, or a response it parses has a new type,
+// enum value or status code.
+//
+//	report, err := openapidiff.Compare(baseline, current)
+//	if err != nil {
+//		return err
+//	}
+//	if report.HasBreaking() {
+//		_ = report.WriteText(os.Stderr)
+//	}
+package openapidiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change kinds.
+const (
+	KindOperationRemoved            = "operation-removed"
+	KindOperationAdded              = "operation-added"
+	KindParameterRemoved            = "parameter-removed"
+	KindParameterAdded              = "parameter-added"
+	KindParameterRequired           = "parameter-required"
+	KindParameterOptional           = "parameter-optional"
+	KindRequestBodyRemoved          = "request-body-removed"
+	KindRequestBodyAdded            = "request-body-added"
+	KindRequestBodyRequired         = "request-body-required"
+	KindMediaTypeRemoved            = "media-type-removed"
+	KindMediaTypeAdded              = "media-type-added"
+	KindPropertyRemoved             = "property-removed"
+	KindPropertyAdded               = "property-added"
+	KindPropertyRequired            = "property-required"
+	KindPropertyOptional            = "property-optional"
+	KindTypeChanged                 = "type-changed"
+	KindEnumChanged                 = "enum-changed"
+	KindConstraintChanged           = "constraint-changed"
+	KindVariantRemoved              = "variant-removed"
+	KindVariantAdded                = "variant-added"
+	KindAdditionalPropertiesChanged = "additional-properties-changed"
+	KindStatusRemoved               = "status-removed"
+	KindStatusAdded                 = "status-added"
+	KindSecurityChanged             = "security-changed"
+)
+
+// Change is a difference between two OpenAPI documents.
+type Change struct {
+	// Breaking reports whether clients of the base document can fail
+	// against the revision.
+	Breaking bool `json:"breaking"`
+
+	// Kind is one of the Kind constants.
+	Kind string `json:"kind"`
+
+	// Operation is the method and path of the changed operation, such as
+	// "GET /users/{id}".
+	Operation string `json:"operation"`
+
+	// Location is the changed part of the operation, such as
+	// "request body.email" or "response 200 body.items[].id". It is empty for
+	// changes of the operation itself.
+	Location string `json:"location,omitempty"`
+
+	// Message describes the change.
+	Message string `json:"message"`
+}
+
+// String formats the change as a single line.
+func (c Change) String() string {
+	severity := "non-breaking"
+	if c.Breaking {
+		severity = "breaking"
+	}
+	if c.Location == "" {
+		return fmt.Sprintf("[%s] %s: %s", severity, c.Operation, c.Message)
+	}
+
+	return fmt.Sprintf("[%s] %s %s: %s", severity, c.Operation, c.Location, c.Message)
+}
+
+// Report lists the changes between two OpenAPI documents.
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// HasBreaking reports whether the report contains a breaking change.
+func (r *Report) HasBreaking() bool {
+	return slices.ContainsFunc(r.Changes, func(c Change) bool { return c.Breaking })
+}
+
+// Breaking returns the breaking changes.
+func (r *Report) Breaking() []Change {
+	var breaking []Change
+	for _, c := range r.Changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+
+	return breaking
+}
+
+// WriteText writes one line per change, followed by a summary line.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, c := range r.Changes {
+		if _, err := fmt.Fprintln(w, c.String()); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d changes, %d breaking\n", len(r.Changes), len(r.Breaking()))
+
+	return err
+}
+
+// WriteJSON writes the report as an indented JSON object.
+func (r *Report) WriteJSON(w io.Writer) error {
+	changes := r.Changes
+	if changes == nil {
+		changes = []Change{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(struct {
+		Breaking int      `json:"breaking"`
+		Changes  []Change `json:"changes"`
+	}{len(r.Breaking()), changes})
+}
+
+// Compare compares a revision of an OpenAPI document with its base. Both
+// documents may be JSON or YAML.
+func Compare(base, revision []byte) (*Report, error) {
+	baseDoc, err := parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base document: %w", err)
+	}
+	revDoc, err := parse(revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse revision document: %w", err)
+	}
+
+	c := &comparer{base: baseDoc, rev: revDoc, report: &Report{}}
+	c.compare()
+
+	return c.report, nil
+}
+
+// parse decodes a JSON or YAML document into maps keyed by strings.
+func parse(data []byte) (map[string]any, error) {
+	var value any
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	doc, ok := normalize(value).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("document is not an object")
+	}
+
+	return doc, nil
+}
+
+// normalize converts YAML mappings with non-string keys, such as unquoted
+// status codes, into maps keyed by strings.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalize(item)
+		}
+
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+// methods are the operation fields of a path item, in report order.
+var methods = []string{"get", "head", "post", "put", "patch", "delete", "options", "trace"}
+
+// comparer walks the base and revision documents side by side.
+type comparer struct {
+	base   map[string]any
+	rev    map[string]any
+	report *Report
+
+	// visited holds the pairs of schema references being compared, so that
+	// recursive schemas are compared once.
+	visited map[[2]string]bool
+}
+
+// add records a change.
+func (c *comparer) add(breaking bool, kind, operation, location, format string, args ...any) {
+	c.report.Changes = append(c.report.Changes, Change{
+		Breaking:  breaking,
+		Kind:      kind,
+		Operation: operation,
+		Location:  location,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// compare compares the operations of the documents.
+func (c *comparer) compare() {
+	basePaths := object(c.base["paths"])
+	revPaths := object(c.rev["paths"])
+
+	for _, path := range sortedKeys(basePaths, revPaths) {
+		baseItem := c.resolve(c.base, object(basePaths[path]))
+		revItem := c.resolve(c.rev, object(revPaths[path]))
+
+		for _, method := range methods {
+			name := strings.ToUpper(method) + " " + path
+			baseOp := object(baseItem[method])
+			revOp := object(revItem[method])
+
+			switch {
+			case baseOp == nil && revOp == nil:
+			case revOp == nil:
+				c.add(true, KindOperationRemoved, name, "", "operation removed")
+			case baseOp == nil:
+				c.add(false, KindOperationAdded, name, "", "operation added")
+			default:
+				c.operation(name, baseItem, baseOp, revItem, revOp)
+			}
+		}
+	}
+}
+
+// operation compares an operation present in both documents.
+func (c *comparer) operation(name string, baseItem, baseOp, revItem, revOp map[string]any) {
+	c.parameters(name, c.operationParameters(c.base, baseItem, baseOp), c.operationParameters(c.rev, revItem, revOp))
+	c.requestBody(name, c.resolve(c.base, object(baseOp["requestBody"])), c.resolve(c.rev, object(revOp["requestBody"])))
+	c.responses(name, object(baseOp["responses"]), object(revOp["responses"]))
+	c.security(name, c.operationSecurity(c.base, baseOp), c.operationSecurity(c.rev, revOp))
+}
+
+// operationParameters returns the parameters of an operation, including
+// those of its path item, keyed by location and name.
+func (c *comparer) operationParameters(doc, item, op map[string]any) map[string]map[string]any {
+	params := make(map[string]map[string]any)
+	for _, list := range []any{item["parameters"], op["parameters"]} {
+		for _, p := range array(list) {
+			param := c.resolve(doc, object(p))
+			if param == nil {
+				continue
+			}
+			params[fmt.Sprint(param["in"])+"."+fmt.Sprint(param["name"])] = param
+		}
+	}
+
+	return params
+}
+
+// parameters compares the parameters of an operation.
+func (c *comparer) parameters(name string, base, rev map[string]map[string]any) {
+	for _, key := range sortedKeys(base, rev) {
+		baseParam, revParam := base[key], rev[key]
+		baseRequired, revRequired := boolean(baseParam["required"]), boolean(revParam["required"])
+
+		switch {
+		case revParam == nil:
+			c.add(false, KindParameterRemoved, name, key, "parameter removed")
+		case baseParam == nil:
+			if revRequired {
+				c.add(true, KindParameterAdded, name, key, "required parameter added")
+			} else {
+				c.add(false, KindParameterAdded, name, key, "optional parameter added")
+			}
+		default:
+			if !baseRequired && revRequired {
+				c.add(true, KindParameterRequired, name, key, "parameter became required")
+			}
+			if baseRequired && !revRequired {
+				c.add(false, KindParameterOptional, name, key, "parameter became optional")
+			}
+			c.schema(name, key, request, object(baseParam["schema"]), object(revParam["schema"]))
+		}
+	}
+}
+
+// requestBody compares the request bodies of an operation.
+func (c *comparer) requestBody(name string, base, rev map[string]any) {
+	const location = "request body"
+
+	switch {
+	case base == nil && rev == nil:
+		return
+	case rev == nil:
+		c.add(false, KindRequestBodyRemoved, name, location, "request body removed")
+
+		return
+	case base == nil:
+		if boolean(rev["required"]) {
+			c.add(true, KindRequestBodyAdded, name, location, "required request body added")
+		} else {
+			c.add(false, KindRequestBodyAdded, name, location, "optional request body added")
+		}
+
+		return
+	}
+
+	if !boolean(base["required"]) && boolean(rev["required"]) {
+		c.add(true, KindRequestBodyRequired, name, location, "request body became required")
+	}
+	c.content(name, location, request, object(base["content"]), object(rev["content"]))
+}
+
+// responses compares the responses of an operation. Removing a success
+// status and adding one are breaking, as clients handle the documented
+// success responses only.
+func (c *comparer) responses(name string, base, rev map[string]any) {
+	for _, status := range sortedKeys(base, rev) {
+		location := "response " + status
+		success := strings.HasPrefix(status, "2") || strings.HasPrefix(status, "3")
+
+		switch {
+		case rev[status] == nil:
+			c.add(success, KindStatusRemoved, name, location, "status %s removed", status)
+		case base[status] == nil:
+			c.add(success, KindStatusAdded, name, location, "status %s added", status)
+		default:
+			baseResponse := c.resolve(c.base, object(base[status]))
+			revResponse := c.resolve(c.rev, object(rev[status]))
+			c.content(name, location+" body", response, object(baseResponse["content"]), object(revResponse["content"]))
+		}
+	}
+}
+
+// content compares the media types of a request body or response.
+func (c *comparer) content(name, location string, dir direction, base, rev map[string]any) {
+	for _, mediaType := range sortedKeys(base, rev) {
+		switch {
+		case rev[mediaType] == nil:
+			c.add(true, KindMediaTypeRemoved, name, location, "media type %s removed", mediaType)
+		case base[mediaType] == nil:
+			c.add(false, KindMediaTypeAdded, name, location, "media type %s added", mediaType)
+		case len(base) == 1 && len(rev) == 1:
+			c.schema(name, location, dir, object(object(base[mediaType])["schema"]), object(object(rev[mediaType])["schema"]))
+		default:
+			c.schema(name, location+" ("+mediaType+")", dir, object(object(base[mediaType])["schema"]), object(object(rev[mediaType])["schema"]))
+		}
+	}
+}
+
+// operationSecurity returns the security requirements of an operation,
+// falling back to the document's. Nil means the operation is public.
+func (c *comparer) operationSecurity(doc, op map[string]any) []map[string][]string {
+	requirements, ok := op["security"]
+	if !ok {
+		requirements = doc["security"]
+	}
+
+	var security []map[string][]string
+	for _, item := range array(requirements) {
+		requirement := make(map[string][]string)
+		for scheme, scopes := range object(item) {
+			requirement[scheme] = []string{}
+			for _, scope := range array(scopes) {
+				requirement[scheme] = append(requirement[scheme], fmt.Sprint(scope))
+			}
+		}
+		if len(requirement) == 0 {
+			// An empty requirement makes security optional.
+			return nil
+		}
+		security = append(security, requirement)
+	}
+
+	return security
+}
+
+// security compares the security requirements of an operation. The change
+// is breaking when a requirement clients of the base document meet is no
+// longer accepted: no requirement of the revision asks for a subset of its
+// schemes and scopes.
+func (c *comparer) security(name string, base, rev []map[string][]string) {
+	if equalJSON(base, rev) {
+		return
+	}
+	if len(base) == 0 {
+		c.add(true, KindSecurityChanged, name, "security", "operation now requires authorization")
+
+		return
+	}
+	if len(rev) == 0 {
+		c.add(false, KindSecurityChanged, name, "security", "operation no longer requires authorization")
+
+		return
+	}
+
+	for _, requirement := range base {
+		if !slices.ContainsFunc(rev, func(candidate map[string][]string) bool { return satisfiedBy(candidate, requirement) }) {
+			c.add(true, KindSecurityChanged, name, "security", "security requirements tightened")
+
+			return
+		}
+	}
+	c.add(false, KindSecurityChanged, name, "security", "security requirements relaxed")
+}
+
+// satisfiedBy reports whether clients meeting requirement also meet candidate.
+func satisfiedBy(candidate, requirement map[string][]string) bool {
+	for scheme, scopes := range candidate {
+		granted, ok := requirement[scheme]
+		if !ok {
+			return false
+		}
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// resolve follows a local $ref of a document object.
+func (c *comparer) resolve(doc, value map[string]any) map[string]any {
+	for range 32 {
+		ref, ok := value["$ref"].(string)
+		if !ok {
+			return value
+		}
+		value = pointer(doc, ref)
+	}
+
+	return value
+}
+
+// pointer returns the object at a local reference such as
+// "#/components/schemas/User", or nil if it does not exist.
+func pointer(doc map[string]any, ref string) map[string]any {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+
+	current := doc
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		current = object(current[token])
+		if current == nil {
+			return nil
+		}
+	}
+
+	return current
+}
+
+// object returns value as an object, or nil.
+func object(value any) map[string]any {
+	m, _ := value.(map[string]any)
+
+	return m
+}
+
+// array returns value as an array, or nil.
+func array(value any) []any {
+	a, _ := value.([]any)
+
+	return a
+}
+
+// boolean returns value as a bool, or false.
+func boolean(value any) bool {
+	b, _ := value.(bool)
+
+	return b
+}
+
+// equalJSON reports whether two values have the same JSON encoding.
+func equalJSON(a, b any) bool {
+	aj, aErr := json.Marshal(a)
+	bj, bErr := json.Marshal(b)
+
+	return aErr == nil && bErr == nil && string(aj) == string(bj)
+}
+
+// sortedKeys returns the keys of both maps, sorted.
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys
+}
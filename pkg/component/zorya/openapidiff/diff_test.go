@@ -0,0 +1,312 @@
+package openapidiff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/talav/talav/pkg/component/zorya"
+	"github.com/talav/talav/pkg/component/zorya/adapters"
+)
+
+const baseDocument = `
+openapi: 3.1.0
+info: {title: Users, version: "1"}
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, maximum: 100}}
+        - {name: cursor, in: query, schema: {type: string}}
+      responses:
+        200:
+          description: OK
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: "#/components/schemas/User"}}
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/CreateUser"}
+      responses:
+        201: {description: Created}
+        422: {description: Invalid}
+  /users/{id}:
+    delete:
+      responses:
+        204: {description: Deleted}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: string}
+        name: {type: string}
+        status: {type: string, enum: [active, inactive]}
+        manager: {$ref: "#/components/schemas/User"}
+    CreateUser:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, maxLength: 100}
+        email: {type: string}
+        role: {type: string, enum: [admin, member, guest]}
+        age: {type: number}
+`
+
+const revisionDocument = `
+openapi: 3.1.0
+info: {title: Users, version: "2"}
+paths:
+  /users:
+    get:
+      security: [{bearerAuth: []}]
+      parameters:
+        - {name: limit, in: query, required: true, schema: {type: integer, maximum: 50}}
+        - {name: sort, in: query, schema: {type: string}}
+      responses:
+        200:
+          description: OK
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: "#/components/schemas/User"}}
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/CreateUser"}
+      responses:
+        200: {description: OK}
+        422: {description: Invalid}
+        409: {description: Conflict}
+  /users/{id}/avatar:
+    get:
+      responses:
+        200: {description: OK}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+        name: {type: string}
+        status: {type: string, enum: [active, inactive, banned]}
+        manager: {$ref: "#/components/schemas/User"}
+        createdAt: {type: string}
+    CreateUser:
+      type: object
+      required: [name, email]
+      properties:
+        name: {type: string, maxLength: 50}
+        email: {type: string}
+        role: {type: string, enum: [admin, member]}
+        age: {type: integer}
+`
+
+func TestCompare(t *testing.T) {
+	report, err := Compare([]byte(baseDocument), []byte(revisionDocument))
+	require.NoError(t, err)
+
+	var lines []string
+	for _, change := range report.Changes {
+		lines = append(lines, change.String())
+	}
+
+	assert.Equal(t, []string{
+		"[non-breaking] GET /users query.cursor: parameter removed",
+		"[breaking] GET /users query.limit: parameter became required",
+		"[breaking] GET /users query.limit: maximum changed from 100 to 50",
+		"[non-breaking] GET /users query.sort: optional parameter added",
+		"[non-breaking] GET /users response 200 body[].createdAt: property added",
+		"[breaking] GET /users response 200 body[].name: property became optional",
+		"[breaking] GET /users response 200 body[].status: enum values added: banned",
+		"[breaking] GET /users security: operation now requires authorization",
+		"[breaking] POST /users request body.age: type changed from number to integer",
+		"[breaking] POST /users request body.email: property became required",
+		"[breaking] POST /users request body.name: maxLength changed from 100 to 50",
+		"[breaking] POST /users request body.role: enum values removed: guest",
+		"[breaking] POST /users response 200: status 200 added",
+		"[breaking] POST /users response 201: status 201 removed",
+		"[non-breaking] POST /users response 409: status 409 added",
+		"[breaking] DELETE /users/{id}: operation removed",
+		"[non-breaking] GET /users/{id}/avatar: operation added",
+	}, lines)
+	assert.True(t, report.HasBreaking())
+}
+
+func TestCompare_ResponseWidening(t *testing.T) {
+	base := `{"paths": {"/items": {"get": {"responses": {"200": {"content": {"application/json": {"schema": {"type": "object", "properties": {"count": {"type": "integer"}}}}}}}}}}}`
+	revision := `{"paths": {"/items": {"get": {"responses": {"200": {"content": {"application/json": {"schema": {"type": "object", "properties": {"count": {"type": "number"}}}}}}}}}}}`
+
+	report, err := Compare([]byte(base), []byte(revision))
+	require.NoError(t, err)
+	require.Len(t, report.Changes, 1)
+	assert.Equal(t, Change{
+		Breaking:  true,
+		Kind:      KindTypeChanged,
+		Operation: "GET /items",
+		Location:  "response 200 body.count",
+		Message:   "type changed from integer to number",
+	}, report.Changes[0])
+
+	report, err = Compare([]byte(revision), []byte(base))
+	require.NoError(t, err)
+	require.Len(t, report.Changes, 1)
+	assert.False(t, report.Changes[0].Breaking)
+}
+
+func TestCompare_Security(t *testing.T) {
+	document := func(security string) []byte {
+		return []byte(`{"paths": {"/items": {"get": {"security": ` + security + `, "responses": {"200": {}}}}}}`)
+	}
+
+	tests := []struct {
+		name     string
+		base     string
+		revision string
+		breaking bool
+		message  string
+	}{
+		{"scope added", `[{"bearerAuth": ["read"]}]`, `[{"bearerAuth": ["read", "admin"]}]`, true, "security requirements tightened"},
+		{"scope removed", `[{"bearerAuth": ["read", "admin"]}]`, `[{"bearerAuth": ["read"]}]`, false, "security requirements relaxed"},
+		{"alternative added", `[{"bearerAuth": []}]`, `[{"bearerAuth": []}, {"apiKey": []}]`, false, "security requirements relaxed"},
+		{"made public", `[{"bearerAuth": []}]`, `[]`, false, "operation no longer requires authorization"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Compare(document(tt.base), document(tt.revision))
+			require.NoError(t, err)
+			require.Len(t, report.Changes, 1)
+			assert.Equal(t, tt.breaking, report.Changes[0].Breaking)
+			assert.Equal(t, tt.message, report.Changes[0].Message)
+		})
+	}
+}
+
+func TestCompare_Composition(t *testing.T) {
+	components := `"components": {"schemas": {"Card": {"type": "object"}, "Transfer": {"type": "object"}}}`
+	document := func(requestSchema, responseSchema string) []byte {
+		return []byte(`{"paths": {"/payments": {"post": {` +
+			`"requestBody": {"content": {"application/json": {"schema": ` + requestSchema + `}}}, ` +
+			`"responses": {"200": {"content": {"application/json": {"schema": ` + responseSchema + `}}}}}}}, ` +
+			components + `}`)
+	}
+	const (
+		cardOrTransfer = `{"oneOf": [{"$ref": "#/components/schemas/Card"}, {"$ref": "#/components/schemas/Transfer"}]}`
+		transferOrCard = `{"oneOf": [{"$ref": "#/components/schemas/Transfer"}, {"$ref": "#/components/schemas/Card"}]}`
+		card           = `{"oneOf": [{"$ref": "#/components/schemas/Card"}]}`
+		plain          = `{"type": "object"}`
+	)
+
+	tests := []struct {
+		name     string
+		base     []byte
+		revision []byte
+		want     []string
+	}{
+		{"reordered branches", document(cardOrTransfer, plain), document(transferOrCard, plain), nil},
+		{
+			"request branch removed",
+			document(cardOrTransfer, plain), document(card, plain),
+			[]string{"[breaking] POST /payments request body.oneOf[1]: oneOf branch removed"},
+		},
+		{
+			"response branch added",
+			document(plain, card), document(plain, transferOrCard),
+			[]string{"[breaking] POST /payments response 200 body.oneOf[0]: oneOf branch added"},
+		},
+		{
+			"inline branch narrowed",
+			document(`{"anyOf": [{"type": "number"}, {"type": "string"}]}`, plain),
+			document(`{"anyOf": [{"type": "integer"}, {"type": "string"}]}`, plain),
+			[]string{"[breaking] POST /payments request body.anyOf[0]: type changed from number to integer"},
+		},
+		{
+			"additional properties disallowed",
+			document(plain, plain), document(`{"type": "object", "additionalProperties": false}`, plain),
+			[]string{"[breaking] POST /payments request body: additional properties no longer allowed"},
+		},
+		{
+			"additional properties narrowed",
+			document(`{"type": "object", "additionalProperties": {"type": "number"}}`, plain),
+			document(`{"type": "object", "additionalProperties": {"type": "integer"}}`, plain),
+			[]string{"[breaking] POST /payments request body.*: type changed from number to integer"},
+		},
+		{
+			"response additional properties disallowed",
+			document(plain, plain), document(plain, `{"type": "object", "additionalProperties": false}`),
+			[]string{"[non-breaking] POST /payments response 200 body: additional properties no longer allowed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Compare(tt.base, tt.revision)
+			require.NoError(t, err)
+
+			var lines []string
+			for _, change := range report.Changes {
+				lines = append(lines, change.String())
+			}
+			assert.Equal(t, tt.want, lines)
+		})
+	}
+}
+
+type ItemInput struct {
+	ID string `schema:"id,location=path"`
+}
+
+type ItemOutput struct {
+	Body struct {
+		Name string `validate:"required"`
+	} `body:"structured"`
+}
+
+func TestCompare_ZoryaAPI(t *testing.T) {
+	api := zorya.NewAPI(adapters.NewChi(chi.NewMux()))
+	zorya.Get(api, "/items/{id}", func(ctx context.Context, input *ItemInput) (*ItemOutput, error) {
+		return &ItemOutput{}, nil
+	})
+
+	document, err := json.Marshal(api.OpenAPI())
+	require.NoError(t, err)
+
+	report, err := Compare(document, document)
+	require.NoError(t, err)
+	assert.Empty(t, report.Changes)
+	assert.False(t, report.HasBreaking())
+}
+
+func TestReport_Write(t *testing.T) {
+	report := &Report{Changes: []Change{
+		{Breaking: true, Kind: KindOperationRemoved, Operation: "DELETE /items/{id}", Message: "operation removed"},
+		{Kind: KindPropertyAdded, Operation: "GET /items", Location: "response 200 body.tags", Message: "property added"},
+	}}
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Equal(t, "[breaking] DELETE /items/{id}: operation removed\n"+
+		"[non-breaking] GET /items response 200 body.tags: property added\n"+
+		"2 changes, 1 breaking\n", text.String())
+
+	var output bytes.Buffer
+	require.NoError(t, report.WriteJSON(&output))
+	assert.JSONEq(t, `{
+		"breaking": 1,
+		"changes": [
+			{"breaking": true, "kind": "operation-removed", "operation": "DELETE /items/{id}", "message": "operation removed"},
+			{"breaking": false, "kind": "property-added", "operation": "GET /items", "location": "response 200 body.tags", "message": "property added"}
+		]
+	}`, output.String())
+}
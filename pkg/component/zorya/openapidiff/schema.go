@@ -0,0 +1,359 @@
+package openapidiff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// direction tells whether a schema describes data clients send or receive.
+// Narrowing what the server accepts breaks clients sending requests, and
+// widening what it returns breaks clients parsing responses.
+type direction int
+
+const (
+	request direction = iota
+	response
+)
+
+// lowerBounds and upperBounds are the constraint keywords narrowing a schema
+// when raised and lowered respectively.
+var (
+	lowerBounds = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	upperBounds = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+)
+
+// schema compares two schemas at location.
+func (c *comparer) schema(name, location string, dir direction, base, rev map[string]any) {
+	if base == nil || rev == nil {
+		return
+	}
+
+	baseRef, _ := base["$ref"].(string)
+	revRef, _ := rev["$ref"].(string)
+	if baseRef != "" || revRef != "" {
+		key := [2]string{baseRef, revRef}
+		if c.visited[key] {
+			return
+		}
+		if c.visited == nil {
+			c.visited = make(map[[2]string]bool)
+		}
+		c.visited[key] = true
+		defer delete(c.visited, key)
+
+		base, rev = c.resolve(c.base, base), c.resolve(c.rev, rev)
+		if base == nil || rev == nil {
+			return
+		}
+	}
+
+	c.schemaType(name, location, dir, base, rev)
+	c.schemaEnum(name, location, dir, base, rev)
+	if dir == request {
+		c.schemaConstraints(name, location, base, rev)
+	}
+	c.schemaProperties(name, location, dir, base, rev)
+	c.schemaAdditionalProperties(name, location, dir, base, rev)
+
+	if items := object(base["items"]); items != nil {
+		c.schema(name, location+"[]", dir, items, object(rev["items"]))
+	}
+
+	baseAllOf, revAllOf := array(base["allOf"]), array(rev["allOf"])
+	if len(baseAllOf) == len(revAllOf) {
+		for i := range baseAllOf {
+			c.schema(name, location, dir, object(baseAllOf[i]), object(revAllOf[i]))
+		}
+	}
+
+	c.schemaVariants(name, location, dir, "oneOf", base, rev)
+	c.schemaVariants(name, location, dir, "anyOf", base, rev)
+}
+
+// schemaType compares the types of two schemas.
+func (c *comparer) schemaType(name, location string, dir direction, base, rev map[string]any) {
+	baseTypes, revTypes := types(base), types(rev)
+	if equalJSON(baseTypes, revTypes) {
+		return
+	}
+
+	var breaking bool
+	if dir == request {
+		breaking = !accepts(revTypes, baseTypes)
+	} else {
+		breaking = !accepts(baseTypes, revTypes)
+	}
+	c.add(breaking, KindTypeChanged, name, location, "type changed from %s to %s", typeString(baseTypes), typeString(revTypes))
+}
+
+// types returns the types of a schema, including "null" for OpenAPI 3.0
+// nullable schemas. Nil means any type.
+func types(s map[string]any) []string {
+	var result []string
+	switch t := s["type"].(type) {
+	case string:
+		result = []string{t}
+	case []any:
+		for _, item := range t {
+			result = append(result, fmt.Sprint(item))
+		}
+	default:
+		return nil
+	}
+	if boolean(s["nullable"]) && !slices.Contains(result, "null") {
+		result = append(result, "null")
+	}
+	slices.Sort(result)
+
+	return result
+}
+
+// accepts reports whether a schema with types accepting accepts every value
+// of a schema with types values.
+func accepts(accepting, values []string) bool {
+	if accepting == nil {
+		return true
+	}
+	if values == nil {
+		return false
+	}
+
+	for _, t := range values {
+		if !slices.Contains(accepting, t) && (t != "integer" || !slices.Contains(accepting, "number")) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// typeString formats types for messages.
+func typeString(types []string) string {
+	if types == nil {
+		return "any"
+	}
+
+	return strings.Join(types, "|")
+}
+
+// schemaEnum compares the enums of two schemas.
+func (c *comparer) schemaEnum(name, location string, dir direction, base, rev map[string]any) {
+	baseEnum, baseOK := base["enum"].([]any)
+	revEnum, revOK := rev["enum"].([]any)
+	if !baseOK && !revOK {
+		return
+	}
+
+	removed := missing(baseEnum, revEnum)
+	added := missing(revEnum, baseEnum)
+
+	switch {
+	case !revOK:
+		c.add(dir == response, KindEnumChanged, name, location, "enum removed")
+	case !baseOK:
+		c.add(dir == request, KindEnumChanged, name, location, "enum added: %s", enumString(revEnum))
+	default:
+		if len(removed) > 0 {
+			c.add(dir == request, KindEnumChanged, name, location, "enum values removed: %s", enumString(removed))
+		}
+		if len(added) > 0 {
+			c.add(dir == response, KindEnumChanged, name, location, "enum values added: %s", enumString(added))
+		}
+	}
+}
+
+// missing returns the values of a that b does not contain.
+func missing(a, b []any) []any {
+	var result []any
+	for _, value := range a {
+		if !slices.ContainsFunc(b, func(other any) bool { return equalJSON(value, other) }) {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// enumString formats enum values for messages.
+func enumString(values []any) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprint(value)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// schemaConstraints compares the validation constraints of two request
+// schemas. Raised lower bounds, lowered upper bounds and changed patterns
+// reject requests the base schema accepts.
+func (c *comparer) schemaConstraints(name, location string, base, rev map[string]any) {
+	for _, keyword := range lowerBounds {
+		c.bound(name, location, keyword, base, rev, func(b, r float64) bool { return r > b })
+	}
+	for _, keyword := range upperBounds {
+		c.bound(name, location, keyword, base, rev, func(b, r float64) bool { return r < b })
+	}
+
+	basePattern, _ := base["pattern"].(string)
+	revPattern, _ := rev["pattern"].(string)
+	switch {
+	case basePattern == revPattern:
+	case revPattern == "":
+		c.add(false, KindConstraintChanged, name, location, "pattern removed")
+	default:
+		c.add(true, KindConstraintChanged, name, location, "pattern changed to %s", revPattern)
+	}
+}
+
+// bound compares a numeric constraint keyword of two schemas.
+func (c *comparer) bound(name, location, keyword string, base, rev map[string]any, narrows func(b, r float64) bool) {
+	baseValue, baseOK := number(base[keyword])
+	revValue, revOK := number(rev[keyword])
+
+	switch {
+	case !baseOK && !revOK:
+	case !revOK:
+		c.add(false, KindConstraintChanged, name, location, "%s removed", keyword)
+	case !baseOK:
+		c.add(true, KindConstraintChanged, name, location, "%s %v added", keyword, revValue)
+	case narrows(baseValue, revValue):
+		c.add(true, KindConstraintChanged, name, location, "%s changed from %v to %v", keyword, baseValue, revValue)
+	case baseValue != revValue:
+		c.add(false, KindConstraintChanged, name, location, "%s changed from %v to %v", keyword, baseValue, revValue)
+	}
+}
+
+// number returns a numeric keyword value.
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// schemaProperties compares the properties of two object schemas.
+//
+// Removed properties are breaking in both directions: clients may still send
+// them or read them. Properties becoming required are breaking in requests,
+// and properties becoming optional are breaking in responses.
+func (c *comparer) schemaProperties(name, location string, dir direction, base, rev map[string]any) {
+	baseProps, revProps := object(base["properties"]), object(rev["properties"])
+	baseRequired, revRequired := stringSet(base["required"]), stringSet(rev["required"])
+
+	for _, property := range sortedKeys(baseProps, revProps) {
+		propLocation := location + "." + property
+
+		switch {
+		case revProps[property] == nil:
+			c.add(true, KindPropertyRemoved, name, propLocation, "property removed")
+		case baseProps[property] == nil:
+			if dir == request && revRequired[property] {
+				c.add(true, KindPropertyAdded, name, propLocation, "required property added")
+			} else {
+				c.add(false, KindPropertyAdded, name, propLocation, "property added")
+			}
+		default:
+			switch {
+			case !baseRequired[property] && revRequired[property]:
+				c.add(dir == request, KindPropertyRequired, name, propLocation, "property became required")
+			case baseRequired[property] && !revRequired[property]:
+				c.add(dir == response, KindPropertyOptional, name, propLocation, "property became optional")
+			}
+			c.schema(name, propLocation, dir, object(baseProps[property]), object(revProps[property]))
+		}
+	}
+}
+
+// stringSet returns the strings of an array as a set.
+func stringSet(value any) map[string]bool {
+	set := make(map[string]bool)
+	for _, item := range array(value) {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+
+	return set
+}
+
+// schemaAdditionalProperties compares the additionalProperties keywords of
+// two object schemas. A missing keyword allows any additional property.
+func (c *comparer) schemaAdditionalProperties(name, location string, dir direction, base, rev map[string]any) {
+	baseValue, revValue := base["additionalProperties"], rev["additionalProperties"]
+	baseSchema, revSchema := object(baseValue), object(revValue)
+	baseAllowed, revAllowed := baseValue != false, revValue != false
+
+	switch {
+	case baseSchema != nil && revSchema != nil:
+		c.schema(name, location+".*", dir, baseSchema, revSchema)
+	case baseAllowed && !revAllowed:
+		c.add(dir == request, KindAdditionalPropertiesChanged, name, location, "additional properties no longer allowed")
+	case !baseAllowed && revAllowed:
+		c.add(dir == response, KindAdditionalPropertiesChanged, name, location, "additional properties allowed")
+	case revSchema != nil:
+		c.add(dir == request, KindAdditionalPropertiesChanged, name, location, "additional properties restricted")
+	case baseSchema != nil:
+		c.add(dir == response, KindAdditionalPropertiesChanged, name, location, "additional properties unrestricted")
+	}
+}
+
+// schemaVariants compares the oneOf or anyOf branches of two schemas.
+// Branches referencing the same schema are paired first, the remaining
+// inline branches are paired by index. Removed branches are breaking in
+// requests and added branches in responses.
+func (c *comparer) schemaVariants(name, location string, dir direction, keyword string, base, rev map[string]any) {
+	baseBranches, revBranches := array(base[keyword]), array(rev[keyword])
+	if baseBranches == nil || revBranches == nil {
+		return
+	}
+
+	pairs := make(map[int]int)
+	paired := make(map[int]bool)
+	for i, branch := range baseBranches {
+		ref, _ := object(branch)["$ref"].(string)
+		if ref == "" {
+			continue
+		}
+		for j, other := range revBranches {
+			if otherRef, _ := object(other)["$ref"].(string); !paired[j] && otherRef == ref {
+				pairs[i], paired[j] = j, true
+
+				break
+			}
+		}
+	}
+	for i, branch := range baseBranches {
+		if _, ok := object(branch)["$ref"]; ok || i >= len(revBranches) || paired[i] {
+			continue
+		}
+		if _, ok := object(revBranches[i])["$ref"]; !ok {
+			pairs[i], paired[i] = i, true
+		}
+	}
+
+	for i, branch := range baseBranches {
+		branchLocation := fmt.Sprintf("%s.%s[%d]", location, keyword, i)
+		j, ok := pairs[i]
+		if !ok {
+			c.add(dir == request, KindVariantRemoved, name, branchLocation, "%s branch removed", keyword)
+
+			continue
+		}
+		c.schema(name, branchLocation, dir, object(branch), object(revBranches[j]))
+	}
+	for j := range revBranches {
+		if !paired[j] {
+			c.add(dir == response, KindVariantAdded, name, fmt.Sprintf("%s.%s[%d]", location, keyword, j), "%s branch added", keyword)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package zorya
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Default page sizes of PageInput. Inputs implementing PageLimiter use their
+// own.
+const (
+	// DefaultPageLimit is the page size used when the client sends no limit.
+	DefaultPageLimit = 20
+
+	// MaxPageLimit is the largest page size a client can request.
+	MaxPageLimit = 100
+)
+
+// pageCursorParam and pageLimitParam are the query parameters of PageInput.
+const (
+	pageCursorParam = "cursor"
+	pageLimitParam  = "limit"
+)
+
+// PageInput is an input mixin for cursor paginated list operations. Embed it
+// in the input struct:
+//
+//	type ListUsersInput struct {
+//		zorya.PageInput
+//		Email string `schema:"email,location=query"`
+//	}
+//
+// The limit parameter defaults to DefaultPageLimit and is at most
+// MaxPageLimit, unless the input implements PageLimiter. Limits out of range
+// are rejected with a 422 problem before the handler runs.
+type PageInput struct {
+	// Cursor is the opaque cursor of the requested page, empty for the first page.
+	Cursor string `schema:"cursor,location=query" openapi:"description=Opaque cursor of the page to return from the Link header or the next_cursor and prev_cursor fields of a previous page."`
+
+	// Limit is the requested page size.
+	Limit int `schema:"limit,location=query" openapi:"description=Maximum number of items to return."`
+
+	defaultLimit, maxLimit int
+}
+
+// PageLimiter is implemented by inputs embedding PageInput to set their
+// default and maximum page sizes:
+//
+//	func (*ListEventsInput) PageLimits() (defaultLimit, maxLimit int) {
+//		return 50, 500
+//	}
+type PageLimiter interface {
+	PageLimits() (defaultLimit, maxLimit int)
+}
+
+var pageResolverType = reflect.TypeFor[pageResolver]()
+
+// PageLimit returns the requested page size, the default page size if unset
+// and at most the maximum page size.
+func (p *PageInput) PageLimit() int {
+	defaultLimit, maxLimit := p.limits()
+
+	switch {
+	case p.Limit <= 0:
+		return defaultLimit
+	case p.Limit > maxLimit:
+		return maxLimit
+	default:
+		return p.Limit
+	}
+}
+
+// limits returns the default and maximum page sizes of the input.
+func (p *PageInput) limits() (defaultLimit, maxLimit int) {
+	if p.maxLimit > 0 {
+		return p.defaultLimit, p.maxLimit
+	}
+
+	return DefaultPageLimit, MaxPageLimit
+}
+
+// pageResolver is implemented by inputs embedding PageInput.
+type pageResolver interface {
+	resolvePage(r *http.Request, defaultLimit, maxLimit int) error
+}
+
+func (p *PageInput) resolvePage(r *http.Request, defaultLimit, maxLimit int) error {
+	p.defaultLimit, p.maxLimit = defaultLimit, maxLimit
+
+	if !r.URL.Query().Has(pageLimitParam) {
+		p.Limit = defaultLimit
+
+		return nil
+	}
+	if p.Limit < 1 || p.Limit > maxLimit {
+		return NewError(http.StatusUnprocessableEntity, "validation failed", &ErrorDetail{
+			Code:     "invalid_limit",
+			Message:  fmt.Sprintf("limit must be between 1 and %d", maxLimit),
+			Location: "query." + pageLimitParam,
+		})
+	}
+
+	return nil
+}
+
+// pageLimits returns the default and maximum page sizes of an input.
+func pageLimits(input any) (defaultLimit, maxLimit int) {
+	limiter, ok := input.(PageLimiter)
+	if !ok {
+		return DefaultPageLimit, MaxPageLimit
+	}
+	defaultLimit, maxLimit = limiter.PageLimits()
+	if maxLimit < 1 || defaultLimit < 1 || defaultLimit > maxLimit {
+		panic(fmt.Sprintf("zorya: %T.PageLimits() must return 0 < default <= max, got %d and %d", input, defaultLimit, maxLimit))
+	}
+
+	return defaultLimit, maxLimit
+}
+
+// documentPageLimits documents the default and maximum of the limit
+// parameter of inputs embedding PageInput.
+func documentPageLimits(route *BaseRoute, inputType reflect.Type) {
+	if inputType.Kind() != reflect.Struct || !reflect.PointerTo(inputType).Implements(pageResolverType) {
+		return
+	}
+
+	defaultLimit, maxLimit := pageLimits(reflect.New(inputType).Interface())
+	for _, param := range route.Operation.Parameters {
+		if param.In != "query" || param.Name != pageLimitParam || param.Schema == nil {
+			continue
+		}
+		minimum, maximum := 1.0, float64(maxLimit)
+		param.Schema.Default = defaultLimit
+		param.Schema.Minimum = &minimum
+		param.Schema.Maximum = &maximum
+	}
+}
+
+// Page is the output of cursor paginated list operations. Zorya sends the
+// cursors as RFC 8288 `Link` headers with the next and prev relations, built
+// from the request URL with its cursor query parameter replaced.
+//
+//	func listUsers(ctx context.Context, input *ListUsersInput) (*zorya.Page[UserView], error) {
+//		page := &zorya.Page[UserView]{}
+//		page.Body.Items = users
+//		page.Body.NextCursor, _ = codec.Encode(lastID)
+//		return page, nil
+//	}
+type Page[T any] struct {
+	Body PageBody[T] `body:"structured"`
+}
+
+// PageBody is the body of a Page. Its schema is registered once per item type.
+type PageBody[T any] struct {
+	// Items are the items of the page.
+	Items []T `json:"items" schema:"items"`
+
+	// NextCursor is the cursor of the next page, empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty" schema:"next_cursor"`
+
+	// PrevCursor is the cursor of the previous page, empty on the first page.
+	PrevCursor string `json:"prev_cursor,omitempty" schema:"prev_cursor"`
+}
+
+// pageLinker is implemented by Page outputs.
+type pageLinker interface {
+	pageCursors() (next, prev string)
+}
+
+var pageLinkerType = reflect.TypeFor[pageLinker]()
+
+func (p *Page[T]) pageCursors() (next, prev string) {
+	return p.Body.NextCursor, p.Body.PrevCursor
+}
+
+// writePageLinks adds the Link headers of a Page output.
+func writePageLinks(r *http.Request, w http.ResponseWriter, output any) {
+	linker, ok := output.(pageLinker)
+	if !ok {
+		return
+	}
+
+	next, prev := linker.pageCursors()
+	if next != "" {
+		w.Header().Add("Link", pageLink(r, next, "next"))
+	}
+	if prev != "" {
+		w.Header().Add("Link", pageLink(r, prev, "prev"))
+	}
+}
+
+// pageLink returns a Link header value pointing at the request URL with the
+// given cursor.
+func pageLink(r *http.Request, cursor, rel string) string {
+	query := r.URL.Query()
+	query.Set(pageCursorParam, cursor)
+
+	target := *r.URL
+	target.RawQuery = query.Encode()
+	target.Fragment = ""
+
+	return "<" + target.String() + `>; rel="` + rel + `"`
+}
+
+// documentPagination documents the Link header of operations returning a Page.
+func documentPagination(route *BaseRoute, outputType reflect.Type) {
+	if !reflect.PointerTo(outputType).Implements(pageLinkerType) {
+		return
+	}
+
+	resp := getResponse(route.Operation, getDefaultStatus(route))
+	if resp.Headers == nil {
+		resp.Headers = make(map[string]*Param)
+	}
+	if _, ok := resp.Headers["Link"]; !ok {
+		resp.Headers["Link"] = &Param{
+			Description: `Links to the adjacent pages, with rel="next" and rel="prev" (RFC 8288).`,
+			Schema:      &Schema{Type: TypeString},
+		}
+	}
+}
+
+// CursorCodec encodes page positions into opaque cursors and decodes them
+// back. Cursors are base64url encoded JSON signed with HMAC-SHA256, so clients
+// cannot forge or alter them. Instances sharing a secret accept each other's
+// cursors.
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec creates a CursorCodec signing cursors with secret.
+// Panics if secret is empty.
+func NewCursorCodec(secret []byte) *CursorCodec {
+	if len(secret) == 0 {
+		panic("zorya.NewCursorCodec: empty secret")
+	}
+
+	return &CursorCodec{secret: secret}
+}
+
+// Encode returns the cursor of a page position, which may be any value
+// encodable as JSON.
+func (c *CursorCodec) Encode(position any) (string, error) {
+	payload, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode decodes a cursor into position. It returns a 400 StatusError if the
+// cursor is malformed or its signature does not match.
+func (c *CursorCodec) Decode(cursor string, position any) error {
+	encodedPayload, encodedSignature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return errInvalidCursor()
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return errInvalidCursor()
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return errInvalidCursor()
+	}
+
+	if err := json.Unmarshal(payload, position); err != nil {
+		return errInvalidCursor()
+	}
+
+	return nil
+}
+
+// sign returns the signature of a cursor payload.
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+// errInvalidCursor returns the error of cursors that cannot be decoded.
+func errInvalidCursor() error {
+	return Error400BadRequest("invalid cursor", &ErrorDetail{
+		Code:     "invalid_cursor",
+		Message:  "cursor is malformed or has been modified",
+		Location: "query." + pageCursorParam,
+	})
+}
@@ -0,0 +1,330 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PageItem struct {
+	ID   int    `json:"id" schema:"id"`
+	Name string `json:"name" schema:"name"`
+}
+
+type ListPageItemsInput struct {
+	PageInput
+	Name string `schema:"name,location=query"`
+}
+
+type ListLargePageItemsInput struct {
+	PageInput
+}
+
+func (*ListLargePageItemsInput) PageLimits() (defaultLimit, maxLimit int) {
+	return 50, 500
+}
+
+type pagePosition struct {
+	ID int `json:"id"`
+}
+
+func newPaginationTestAPI(t *testing.T, codec *CursorCodec) (*chi.Mux, API) {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithValidator(NewPlaygroundValidator(validator.New())))
+
+	items := make([]PageItem, 50)
+	for i := range items {
+		items[i] = PageItem{ID: i + 1, Name: "item"}
+	}
+
+	Get(api, "/items", func(ctx context.Context, input *ListPageItemsInput) (*Page[PageItem], error) {
+		start := 0
+		if input.Cursor != "" {
+			var position pagePosition
+			if err := codec.Decode(input.Cursor, &position); err != nil {
+				return nil, err
+			}
+			start = position.ID
+		}
+
+		end := min(start+input.PageLimit(), len(items))
+		page := &Page[PageItem]{}
+		page.Body.Items = items[start:end]
+
+		var err error
+		if end < len(items) {
+			if page.Body.NextCursor, err = codec.Encode(pagePosition{ID: end}); err != nil {
+				return nil, err
+			}
+		}
+		if start > 0 {
+			if page.Body.PrevCursor, err = codec.Encode(pagePosition{ID: max(start-input.PageLimit(), 0)}); err != nil {
+				return nil, err
+			}
+		}
+
+		return page, nil
+	})
+
+	return router, api
+}
+
+// pageLinks returns the Link header values of a response pointing at pages.
+func pageLinks(recorder *httptest.ResponseRecorder) []string {
+	var links []string
+	for _, link := range recorder.Header().Values("Link") {
+		if !strings.Contains(link, `rel="describedby"`) {
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+func TestCursorCodec_RoundTrip(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+
+	cursor, err := codec.Encode(pagePosition{ID: 42})
+	require.NoError(t, err)
+
+	var position pagePosition
+	require.NoError(t, codec.Decode(cursor, &position))
+	assert.Equal(t, 42, position.ID)
+
+	// Codecs sharing the secret accept each other's cursors.
+	require.NoError(t, NewCursorCodec([]byte("secret")).Decode(cursor, &position))
+}
+
+func TestCursorCodec_InvalidCursors(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	cursor, err := codec.Encode(pagePosition{ID: 42})
+	require.NoError(t, err)
+	forged, err := NewCursorCodec([]byte("other")).Encode(pagePosition{ID: 42})
+	require.NoError(t, err)
+	tampered, err := NewCursorCodec([]byte("secret")).Encode(pagePosition{ID: 43})
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"no signature":     "eyJpZCI6NDJ9",
+		"bad payload":      "!!!." + cursor[len("eyJpZCI6NDJ9")+1:],
+		"bad signature":    "eyJpZCI6NDJ9.!!!",
+		"other secret":     forged,
+		"swapped payload":  tampered[:len("eyJpZCI6NDN9")] + cursor[len("eyJpZCI6NDJ9"):],
+		"empty":            "",
+		"not json payload": "bm90IGpzb24." + cursor[len("eyJpZCI6NDJ9")+1:],
+	}
+	for name, cursor := range tests {
+		var position pagePosition
+		err := codec.Decode(cursor, &position)
+
+		var model *ErrorModel
+		require.True(t, errors.As(err, &model), name)
+		assert.Equal(t, http.StatusBadRequest, model.Status, name)
+		require.Len(t, model.Errors, 1, name)
+		assert.Equal(t, "invalid_cursor", model.Errors[0].Code, name)
+		assert.Equal(t, "query.cursor", model.Errors[0].Location, name)
+	}
+}
+
+func TestNewCursorCodec_EmptySecret(t *testing.T) {
+	assert.PanicsWithValue(t, "zorya.NewCursorCodec: empty secret", func() {
+		NewCursorCodec(nil)
+	})
+}
+
+func TestPageInput_PageLimit(t *testing.T) {
+	tests := map[int]int{
+		0:   DefaultPageLimit,
+		-5:  DefaultPageLimit,
+		10:  10,
+		100: 100,
+		500: MaxPageLimit,
+	}
+	for limit, expected := range tests {
+		input := PageInput{Limit: limit}
+		assert.Equal(t, expected, input.PageLimit(), limit)
+	}
+}
+
+func TestPagination_LinkHeaders(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	router, _ := newPaginationTestAPI(t, codec)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	// First page: only a next link, keeping the other query parameters.
+	first := get("/items?limit=20&name=item")
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+
+	var body PageBody[PageItem]
+	require.NoError(t, json.Unmarshal(first.Body.Bytes(), &body))
+	assert.Len(t, body.Items, 20)
+	assert.Equal(t, 1, body.Items[0].ID)
+	assert.NotEmpty(t, body.NextCursor)
+	assert.Empty(t, body.PrevCursor)
+	assert.NotContains(t, first.Body.String(), "prev_cursor")
+
+	links := pageLinks(first)
+	require.Len(t, links, 1)
+	next := (&url.URL{Path: "/items", RawQuery: url.Values{
+		"cursor": {body.NextCursor},
+		"limit":  {"20"},
+		"name":   {"item"},
+	}.Encode()}).String()
+	assert.Equal(t, "<"+next+`>; rel="next"`, links[0])
+
+	// Second page: next and prev links.
+	second := get(next)
+	require.Equal(t, http.StatusOK, second.Code, second.Body.String())
+	require.NoError(t, json.Unmarshal(second.Body.Bytes(), &body))
+	assert.Equal(t, 21, body.Items[0].ID)
+	links = pageLinks(second)
+	require.Len(t, links, 2)
+	assert.Contains(t, links[0], `rel="next"`)
+	assert.Contains(t, links[1], `rel="prev"`)
+
+	// Last page: only a prev link.
+	last := get("/items?limit=20&cursor=" + url.QueryEscape(body.NextCursor))
+	require.Equal(t, http.StatusOK, last.Code, last.Body.String())
+	require.NoError(t, json.Unmarshal(last.Body.Bytes(), &body))
+	assert.Len(t, body.Items, 10)
+	links = pageLinks(last)
+	require.Len(t, links, 1)
+	assert.Contains(t, links[0], `rel="prev"`)
+}
+
+func TestPagination_InvalidRequests(t *testing.T) {
+	router, _ := newPaginationTestAPI(t, NewCursorCodec([]byte("secret")))
+
+	tests := map[string]int{
+		"/items?cursor=forged":  http.StatusBadRequest,
+		"/items?limit=101":      http.StatusUnprocessableEntity,
+		"/items?limit=0":        http.StatusUnprocessableEntity,
+		"/items?limit=100":      http.StatusOK,
+		"/items?name=unrelated": http.StatusOK,
+	}
+	for target, status := range tests {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, status, recorder.Code, target)
+	}
+}
+
+func TestPagination_OpenAPI(t *testing.T) {
+	_, api := newPaginationTestAPI(t, NewCursorCodec([]byte("secret")))
+
+	op := api.OpenAPI().Paths["/items"].Get
+	names := make([]string, 0, len(op.Parameters))
+	for _, param := range op.Parameters {
+		names = append(names, param.Name)
+	}
+	assert.ElementsMatch(t, []string{"cursor", "limit", "name"}, names)
+
+	resp := op.Responses["200"]
+	require.Contains(t, resp.Headers, "Link")
+	assert.Equal(t, TypeString, resp.Headers["Link"].Schema.Type)
+
+	ref := resp.Content["application/json"].Schema.Ref
+	assert.Equal(t, "#/components/schemas/PageBodyPageItem", ref)
+
+	schema := api.OpenAPI().Components.Schemas["PageBodyPageItem"]
+	require.NotNil(t, schema)
+	assert.Contains(t, schema.Properties, "items")
+	assert.Contains(t, schema.Properties, "next_cursor")
+	assert.Contains(t, schema.Properties, "prev_cursor")
+}
+
+func TestPagination_PageLimiter(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/items", func(ctx context.Context, input *ListLargePageItemsInput) (*Page[PageItem], error) {
+		page := &Page[PageItem]{}
+		page.Body.Items = make([]PageItem, input.PageLimit())
+
+		return page, nil
+	})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	var body PageBody[PageItem]
+	recorder := get("/items")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Len(t, body.Items, 50)
+
+	recorder = get("/items?limit=500")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Len(t, body.Items, 500)
+
+	recorder = get("/items?limit=501")
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.JSONEq(t, `{
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "validation failed",
+		"errors": [
+			{"code": "invalid_limit", "message": "limit must be between 1 and 500", "location": "query.limit"}
+		]
+	}`, recorder.Body.String())
+
+	var limit *Param
+	for _, param := range api.OpenAPI().Paths["/items"].Get.Parameters {
+		if param.Name == "limit" {
+			limit = param
+		}
+	}
+	require.NotNil(t, limit)
+	assert.Equal(t, 50, limit.Schema.Default)
+	require.NotNil(t, limit.Schema.Minimum)
+	require.NotNil(t, limit.Schema.Maximum)
+	assert.InDelta(t, 1, *limit.Schema.Minimum, 0)
+	assert.InDelta(t, 500, *limit.Schema.Maximum, 0)
+}
+
+func TestPagination_InvalidPageLimits(t *testing.T) {
+	type ListInvalidPageItemsInput struct {
+		PageInput
+		invalidPageLimits
+	}
+
+	api := NewAPI(&testChiAdapter{router: chi.NewMux()})
+	assert.Panics(t, func() {
+		Get(api, "/items", func(ctx context.Context, input *ListInvalidPageItemsInput) (*Page[PageItem], error) {
+			return &Page[PageItem]{}, nil
+		})
+	})
+}
+
+type invalidPageLimits struct{}
+
+func (invalidPageLimits) PageLimits() (defaultLimit, maxLimit int) {
+	return 200, 100
+}
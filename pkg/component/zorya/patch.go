@@ -0,0 +1,338 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+)
+
+const autoPatchContextKey contextKey = "auto_patch_context"
+
+// Patch media types accepted by AutoPatch operations.
+const (
+	// MediaTypeMergePatch is the JSON Merge Patch (RFC 7396) media type.
+	MediaTypeMergePatch = "application/merge-patch+json"
+	// MediaTypeJSONPatch is the JSON Patch (RFC 6902) media type.
+	MediaTypeJSONPatch = "application/json-patch+json"
+)
+
+// AutoPatch registers a PATCH operation for every path of the API that has
+// GET and PUT operations but no PATCH operation. Call it after all routes are
+// registered.
+//
+// The generated operation accepts JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents. It fetches the current resource through the GET
+// operation, applies the patch and stores the result through the PUT
+// operation, so the PUT operation's decoding, validation and authorization
+// apply to the patched resource. An If-Match header is checked against the
+// ETag returned by GET, and that ETag is sent to PUT as If-Match so PUT
+// handlers checking preconditions reject concurrent updates.
+//
+//	zorya.Get(api, "/items/{id}", getItem)
+//	zorya.Put(api, "/items/{id}", replaceItem)
+//	zorya.AutoPatch(api)
+func AutoPatch(api API) {
+	router, ok := rootMethodRouter(api)
+	if !ok {
+		return
+	}
+
+	router.mu.RLock()
+	var paths []string
+	for path, p := range router.paths {
+		if p.handlers[http.MethodGet] != nil && p.handlers[http.MethodPut] != nil && p.handlers[http.MethodPatch] == nil {
+			paths = append(paths, path)
+		}
+	}
+	router.mu.RUnlock()
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		registerAutoPatch(router, path)
+	}
+}
+
+// registerAutoPatch documents and registers the PATCH operation for a path.
+// The operation goes through the middleware chain of the API or group the PUT
+// operation was registered with, and takes the security, rate limits,
+// idempotency, timeout and body limits of the PUT operation.
+func registerAutoPatch(router *methodRouter, path string) {
+	router.mu.RLock()
+	put := router.paths[path].operations[http.MethodPut]
+	putRoute := router.paths[path].routes[http.MethodPut]
+	router.mu.RUnlock()
+
+	api := router.api
+	route := &BaseRoute{
+		Method:    http.MethodPatch,
+		Path:      path,
+		Operation: newPatchOperation(put),
+	}
+	if putRoute != nil {
+		if putRoute.api != nil {
+			api = putRoute.api
+		}
+		route.Middlewares = putRoute.Middlewares
+		route.BodyReadTimeout = putRoute.BodyReadTimeout
+		route.MaxBodyBytes = putRoute.MaxBodyBytes
+		route.Timeout = putRoute.Timeout
+		route.Security = putRoute.Security
+		route.Idempotency = putRoute.Idempotency
+		route.RateLimits = putRoute.RateLimits
+	}
+
+	// Document the operation next to its PUT operation.
+	for _, pathItem := range router.api.OpenAPI().Paths {
+		if put != nil && pathItem.Put == put && pathItem.Patch == nil {
+			pathItem.Patch = route.Operation
+		}
+	}
+
+	router.Handle(route, routeHandler(api, route, newAutoPatchHandler(router, route)).ServeHTTP)
+}
+
+// newPatchOperation creates the OpenAPI operation of a generated PATCH
+// operation from its PUT operation.
+func newPatchOperation(put *Operation) *Operation {
+	op := &Operation{
+		Description: "Partially updates the resource with a JSON Merge Patch (RFC 7396) or " +
+			"JSON Patch (RFC 6902) document.",
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				MediaTypeMergePatch: {Schema: &Schema{
+					Type:                 TypeObject,
+					Description:          "Fields to change. Fields set to null are removed.",
+					AdditionalProperties: true,
+				}},
+				MediaTypeJSONPatch: {Schema: jsonPatchSchema()},
+			},
+		},
+		Responses: make(map[string]*Response),
+	}
+
+	if put != nil {
+		if put.OperationID != "" {
+			op.OperationID = "patch-" + strings.TrimPrefix(put.OperationID, "put-")
+		}
+		op.Tags = put.Tags
+		op.Security = put.Security
+		op.Parameters = slices.Clone(put.Parameters)
+		maps.Copy(op.Responses, put.Responses)
+	}
+
+	addHeaderParam(op, "If-Match",
+		"Applies the patch only if the resource's current ETag matches one of the listed ETags.")
+
+	var errContent map[string]*MediaType
+	if resp := op.Responses["500"]; resp != nil {
+		errContent = resp.Content
+	}
+	for _, code := range []int{
+		http.StatusBadRequest,
+		http.StatusPreconditionFailed,
+		http.StatusUnsupportedMediaType,
+		http.StatusUnprocessableEntity,
+	} {
+		if resp := getResponse(op, code); resp.Content == nil {
+			resp.Content = errContent
+		}
+	}
+
+	return op
+}
+
+// jsonPatchSchema returns the schema of a JSON Patch document.
+func jsonPatchSchema() *Schema {
+	return &Schema{
+		Type: TypeArray,
+		Items: &Schema{
+			Type:     TypeObject,
+			Required: []string{"op", "path"},
+			Properties: map[string]*Schema{
+				"op": {
+					Type: TypeString,
+					Enum: []any{"add", "remove", "replace", "move", "copy", "test"},
+				},
+				"path":  {Type: TypeString, Description: "JSON Pointer to the target location."},
+				"from":  {Type: TypeString, Description: "JSON Pointer to the source location of move and copy."},
+				"value": {Description: "Value used by add, replace and test."},
+			},
+		},
+	}
+}
+
+// newAutoPatchHandler returns the handler of a generated PATCH operation.
+func newAutoPatchHandler(router *methodRouter, route *BaseRoute) http.HandlerFunc {
+	api := router.api
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		setupRequestLimits(r, w, *route)
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != MediaTypeMergePatch && mediaType != MediaTypeJSONPatch {
+			WriteErr(api, r, w, 0, "", Error415UnsupportedMediaType(fmt.Sprintf(
+				"unsupported media type %q, expected %s or %s", mediaType, MediaTypeMergePatch, MediaTypeJSONPatch,
+			)))
+
+			return
+		}
+
+		patch, err := io.ReadAll(r.Body)
+		if err != nil {
+			WriteErr(api, r, w, 0, "", err)
+
+			return
+		}
+
+		// Sub-requests keep the values of the request context, such as the
+		// request ID and the authenticated user, and are canceled with it.
+		// They are dispatched to the GET and PUT handlers of the path without
+		// routing, so the router's state in the context stays valid.
+		ctx, cancel := context.WithCancel(context.WithValue(context.WithoutCancel(r.Context()), autoPatchContextKey, true))
+		defer cancel()
+		stop := context.AfterFunc(r.Context(), cancel)
+		defer stop()
+
+		current := httptest.NewRecorder()
+		get := newPatchSubRequest(ctx, r, http.MethodGet, nil)
+		get.Header.Set("Accept", "application/json")
+		router.dispatch(route.Path, http.MethodGet)(current, get)
+		if current.Code < 200 || current.Code >= 300 {
+			copyRecordedResponse(w, current)
+
+			return
+		}
+
+		etag := current.Header().Get("ETag")
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && !ifMatchMatches(ifMatch, etag) &&
+			!ifMatchMatches(ifMatch, clientETag(ctx, router, route, r)) {
+			WriteErr(api, r, w, 0, "", Error412PreconditionFailed("resource has been modified"))
+
+			return
+		}
+
+		var doc any
+		if err := json.Unmarshal(current.Body.Bytes(), &doc); err != nil {
+			WriteErr(api, r, w, http.StatusInternalServerError, "failed to decode current resource", err)
+
+			return
+		}
+
+		patched, err := applyPatch(mediaType, doc, patch)
+		if err != nil {
+			WriteErr(api, r, w, 0, "", err)
+
+			return
+		}
+
+		body, err := json.Marshal(patched)
+		if err != nil {
+			WriteErr(api, r, w, http.StatusInternalServerError, "failed to encode patched resource", err)
+
+			return
+		}
+
+		put := newPatchSubRequest(ctx, r, http.MethodPut, body)
+		put.Header.Set("Content-Type", "application/json")
+		if etag != "" {
+			put.Header.Set("If-Match", etag)
+		}
+		router.dispatch(route.Path, http.MethodPut)(w, put)
+	}
+}
+
+// patchDroppedHeaders are the headers of the PATCH request that are not
+// passed on to its sub-requests: they describe the patch document or are
+// handled by the PATCH operation. Accept-Encoding applies to the PATCH
+// response, the sub-request responses are decoded as they are.
+var patchDroppedHeaders = []string{
+	"Content-Type", "Content-Length", "Content-Encoding", "Accept-Encoding", HeaderIdempotencyKey,
+	"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since",
+}
+
+// newPatchSubRequest creates a GET or PUT request for the resource of a PATCH
+// request, keeping its headers except for patchDroppedHeaders. The request is
+// a clone, so router path values remain available to the handler.
+func newPatchSubRequest(ctx context.Context, r *http.Request, method string, body []byte) *http.Request {
+	req := r.Clone(ctx)
+	req.Method = method
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = nil
+	for _, name := range patchDroppedHeaders {
+		req.Header.Del(name)
+	}
+
+	return req
+}
+
+// clientETag returns the ETag of the resource in the format the client of a
+// PATCH request accepts, which it may have read its If-Match ETag from. The
+// patch itself applies to the JSON format.
+func clientETag(ctx context.Context, router *methodRouter, route *BaseRoute, r *http.Request) string {
+	recorder := httptest.NewRecorder()
+	router.dispatch(route.Path, http.MethodGet)(recorder, newPatchSubRequest(ctx, r, http.MethodGet, nil))
+	if recorder.Code < 200 || recorder.Code >= 300 {
+		return ""
+	}
+
+	return recorder.Header().Get("ETag")
+}
+
+// isAutoPatchSubRequest reports whether r is a GET or PUT sub-request of a
+// generated PATCH operation.
+func isAutoPatchSubRequest(r *http.Request) bool {
+	return r.Context().Value(autoPatchContextKey) != nil
+}
+
+// skipForAutoPatchSubRequests returns a middleware running the middlewares for
+// every request except the sub-requests of generated PATCH operations using
+// one of the methods, which go straight to the next handler.
+func skipForAutoPatchSubRequests(middlewares Middlewares, methods ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if len(middlewares) == 0 {
+			return next
+		}
+
+		chain := middlewares.Apply(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isAutoPatchSubRequest(r) && slices.Contains(methods, r.Method) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			chain.ServeHTTP(w, r)
+		})
+	}
+}
+
+// copyRecordedResponse writes a recorded response to w.
+func copyRecordedResponse(w http.ResponseWriter, recorder *httptest.ResponseRecorder) {
+	maps.Copy(w.Header(), recorder.Header())
+	w.WriteHeader(recorder.Code)
+	_, _ = w.Write(recorder.Body.Bytes())
+}
+
+// ifMatchMatches reports whether an If-Match list matches the ETag using the
+// strong comparison function.
+func ifMatchMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || (etag != "" && !strings.HasPrefix(etag, "W/") && candidate == etag) {
+			return true
+		}
+	}
+
+	return false
+}
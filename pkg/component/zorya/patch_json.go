@@ -0,0 +1,317 @@
+package zorya
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonPatchOperation is a single JSON Patch (RFC 6902) operation.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyPatch applies a JSON Merge Patch or JSON Patch document to a
+// JSON-decoded value. Malformed documents are reported as 400 errors and
+// patches that cannot be applied as 422 errors.
+func applyPatch(mediaType string, doc any, patch []byte) (any, error) {
+	if mediaType == MediaTypeMergePatch {
+		var p any
+		if err := json.Unmarshal(patch, &p); err != nil {
+			return nil, Error400BadRequest("invalid merge patch document", err)
+		}
+
+		return mergePatch(doc, p), nil
+	}
+
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, Error400BadRequest("invalid JSON patch document", err)
+	}
+
+	for i, op := range ops {
+		var err error
+		if doc, err = applyJSONPatchOperation(doc, op); err != nil {
+			return nil, Error422UnprocessableEntity("failed to apply patch", &ErrorDetail{
+				Code:     op.Op,
+				Message:  err.Error(),
+				Location: fmt.Sprintf("body[%d]", i),
+			})
+		}
+	}
+
+	return doc, nil
+}
+
+// mergePatch applies a JSON Merge Patch (RFC 7396) to target.
+func mergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any, len(patchObj))
+	}
+
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+		} else {
+			targetObj[name] = mergePatch(targetObj[name], value)
+		}
+	}
+
+	return targetObj
+}
+
+// applyJSONPatchOperation applies a single JSON Patch operation to doc.
+func applyJSONPatchOperation(doc any, op jsonPatchOperation) (any, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if op.Op == "add" || op.Op == "replace" || op.Op == "test" {
+		if op.Value == nil {
+			return nil, fmt.Errorf("%s operation requires a value", op.Op)
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return jsonPointerAdd(doc, path, value)
+	case "remove":
+		doc, _, err = jsonPointerRemove(doc, path)
+
+		return doc, err
+	case "replace":
+		return jsonPointerUpdate(doc, path, func(any) (any, error) {
+			return value, nil
+		})
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "copy" {
+			if value, err = jsonPointerGet(doc, from); err != nil {
+				return nil, err
+			}
+
+			return jsonPointerAdd(doc, path, deepCopyJSON(value))
+		}
+
+		if len(path) > len(from) && slices.Equal(path[:len(from)], from) {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		if doc, value, err = jsonPointerRemove(doc, from); err != nil {
+			return nil, err
+		}
+
+		return jsonPointerAdd(doc, path, value)
+	case "test":
+		current, err := jsonPointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("test failed: value at %q does not match", op.Path)
+		}
+
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", op.Op)
+	}
+}
+
+// parseJSONPointer splits a JSON Pointer (RFC 6901) into unescaped tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// jsonPointerGet returns the value at path.
+func jsonPointerGet(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", token)
+			}
+			doc = value
+		case []any:
+			idx, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[idx]
+		default:
+			return nil, fmt.Errorf("cannot traverse %s at %q", jsonTypeOf(doc), token)
+		}
+	}
+
+	return doc, nil
+}
+
+// jsonPointerUpdate replaces the existing value at path with fn's result and
+// returns the updated document.
+func jsonPointerUpdate(doc any, path []string, fn func(any) (any, error)) (any, error) {
+	if len(path) == 0 {
+		return fn(doc)
+	}
+
+	token := path[0]
+	switch container := doc.(type) {
+	case map[string]any:
+		child, ok := container[token]
+		if !ok {
+			return nil, fmt.Errorf("path %q not found", token)
+		}
+		updated, err := jsonPointerUpdate(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = updated
+
+		return container, nil
+	case []any:
+		idx, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := jsonPointerUpdate(container[idx], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		container[idx] = updated
+
+		return container, nil
+	default:
+		return nil, fmt.Errorf("cannot traverse %s at %q", jsonTypeOf(doc), token)
+	}
+}
+
+// jsonPointerAdd adds value at path. Array elements are inserted and "-"
+// appends to an array.
+func jsonPointerAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[len(path)-1]
+
+	return jsonPointerUpdate(doc, path[:len(path)-1], func(parent any) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			container[token] = value
+
+			return container, nil
+		case []any:
+			if token == "-" {
+				return append(container, value), nil
+			}
+			idx, err := arrayIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+
+			return slices.Insert(container, idx, value), nil
+		default:
+			return nil, fmt.Errorf("cannot add to %s at %q", jsonTypeOf(parent), token)
+		}
+	})
+}
+
+// jsonPointerRemove removes the value at path and returns the updated
+// document and the removed value.
+func jsonPointerRemove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+
+	token := path[len(path)-1]
+	var removed any
+	doc, err := jsonPointerUpdate(doc, path[:len(path)-1], func(parent any) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", token)
+			}
+			removed = value
+			delete(container, token)
+
+			return container, nil
+		case []any:
+			idx, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = container[idx]
+
+			return slices.Delete(container, idx, idx+1), nil
+		default:
+			return nil, fmt.Errorf("cannot remove from %s at %q", jsonTypeOf(parent), token)
+		}
+	})
+
+	return doc, removed, err
+}
+
+// arrayIndex parses an array index token no greater than maxIndex.
+func arrayIndex(token string, maxIndex int) (int, error) {
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || idx > maxIndex || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	return idx, nil
+}
+
+// deepCopyJSON copies a JSON-decoded value.
+func deepCopyJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for name, child := range v {
+			copied[name] = deepCopyJSON(child)
+		}
+
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, child := range v {
+			copied[i] = deepCopyJSON(child)
+		}
+
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,348 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type patchItem struct {
+	Name  string   `validate:"required"`
+	Tags  []string `validate:"required"`
+	Stars int      `validate:"min=0"`
+}
+
+type GetPatchItemInput struct {
+	ID string `schema:"id,location=path,required=true"`
+}
+
+type PatchItemOutput struct {
+	ETag string    `schema:"ETag,location=header"`
+	Body patchItem `body:"structured"`
+}
+
+type PutPatchItemInput struct {
+	ID      string    `schema:"id,location=path,required=true"`
+	IfMatch string    `schema:"If-Match,location=header"`
+	Body    patchItem `body:"structured"`
+}
+
+// patchItemStore is an in-memory versioned item store.
+type patchItemStore struct {
+	items    map[string]patchItem
+	versions map[string]int
+	ifMatch  string
+}
+
+func (s *patchItemStore) etag(id string) string {
+	return `"` + strconv.Itoa(s.versions[id]) + `"`
+}
+
+func newPatchTestAPI(t *testing.T) (*patchItemStore, API, *chi.Mux) {
+	t.Helper()
+
+	store := &patchItemStore{
+		items:    map[string]patchItem{"1": {Name: "Widget", Tags: []string{"new"}, Stars: 3}},
+		versions: map[string]int{"1": 1},
+	}
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithValidator(NewPlaygroundValidator(validator.New())))
+
+	Get(api, "/items/{id}", func(ctx context.Context, input *GetPatchItemInput) (*PatchItemOutput, error) {
+		item, ok := store.items[input.ID]
+		if !ok {
+			return nil, Error404NotFound("item not found")
+		}
+
+		return &PatchItemOutput{ETag: store.etag(input.ID), Body: item}, nil
+	}, func(r *BaseRoute) {
+		r.Operation = &Operation{OperationID: "get-item"}
+	})
+	Put(api, "/items/{id}", func(ctx context.Context, input *PutPatchItemInput) (*PatchItemOutput, error) {
+		store.ifMatch = input.IfMatch
+		if input.IfMatch != "" && input.IfMatch != store.etag(input.ID) {
+			return nil, Error412PreconditionFailed("resource has been modified")
+		}
+		store.items[input.ID] = input.Body
+		store.versions[input.ID]++
+
+		return &PatchItemOutput{ETag: store.etag(input.ID), Body: input.Body}, nil
+	}, func(r *BaseRoute) {
+		r.Operation = &Operation{OperationID: "put-item", Tags: []string{"items"}}
+	})
+	AutoPatch(api)
+
+	return store, api, router
+}
+
+func patchItemRequest(router http.Handler, contentType, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/items/1", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestAutoPatch_MergePatch(t *testing.T) {
+	store, _, router := newPatchTestAPI(t)
+
+	recorder := patchItemRequest(router, MediaTypeMergePatch, `{"Stars": 5}`, nil)
+
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"Name":"Widget","Tags":["new"],"Stars":5}`, recorder.Body.String())
+	assert.Equal(t, `"2"`, recorder.Header().Get("ETag"))
+	assert.Equal(t, patchItem{Name: "Widget", Tags: []string{"new"}, Stars: 5}, store.items["1"])
+	// The ETag returned by GET is forwarded to PUT.
+	assert.Equal(t, `"1"`, store.ifMatch)
+}
+
+func TestAutoPatch_JSONPatch(t *testing.T) {
+	store, _, router := newPatchTestAPI(t)
+
+	recorder := patchItemRequest(router, MediaTypeJSONPatch, `[
+		{"op": "test", "path": "/Name", "value": "Widget"},
+		{"op": "replace", "path": "/Name", "value": "Gadget"},
+		{"op": "add", "path": "/Tags/-", "value": "sale"},
+		{"op": "copy", "from": "/Tags/0", "path": "/Tags/0"},
+		{"op": "remove", "path": "/Tags/1"}
+	]`, nil)
+
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, patchItem{Name: "Gadget", Tags: []string{"new", "sale"}, Stars: 3}, store.items["1"])
+}
+
+func TestAutoPatch_JSONPatchFailure(t *testing.T) {
+	store, _, router := newPatchTestAPI(t)
+
+	recorder := patchItemRequest(router, MediaTypeJSONPatch, `[
+		{"op": "replace", "path": "/Name", "value": "Gadget"},
+		{"op": "test", "path": "/Stars", "value": 4}
+	]`, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	var problem ErrorModel
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "test", problem.Errors[0].Code)
+	assert.Equal(t, "body[1]", problem.Errors[0].Location)
+	assert.Equal(t, "Widget", store.items["1"].Name)
+
+	recorder = patchItemRequest(router, MediaTypeJSONPatch, `{"op": "add"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestAutoPatch_ValidatesPatchedResource(t *testing.T) {
+	store, _, router := newPatchTestAPI(t)
+
+	recorder := patchItemRequest(router, MediaTypeMergePatch, `{"Stars": -1}`, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, 3, store.items["1"].Stars)
+}
+
+func TestAutoPatch_IfMatch(t *testing.T) {
+	store, _, router := newPatchTestAPI(t)
+
+	recorder := patchItemRequest(router, MediaTypeMergePatch, `{"Stars": 5}`, map[string]string{"If-Match": `"0"`})
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	assert.Equal(t, 3, store.items["1"].Stars)
+
+	recorder = patchItemRequest(router, MediaTypeMergePatch, `{"Stars": 5}`, map[string]string{"If-Match": `"0", "1"`})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 5, store.items["1"].Stars)
+}
+
+func TestAutoPatch_Errors(t *testing.T) {
+	_, _, router := newPatchTestAPI(t)
+
+	recorder := patchItemRequest(router, "application/json", `{"Stars": 5}`, nil)
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+
+	req := httptest.NewRequest(http.MethodPatch, "/items/2", bytes.NewReader([]byte(`{"Stars": 5}`)))
+	req.Header.Set("Content-Type", MediaTypeMergePatch)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+}
+
+func TestAutoPatch_OpenAPI(t *testing.T) {
+	_, api, router := newPatchTestAPI(t)
+
+	op := api.OpenAPI().Paths["/items/{id}"].Patch
+	require.NotNil(t, op)
+	assert.Equal(t, "patch-item", op.OperationID)
+	assert.Equal(t, []string{"items"}, op.Tags)
+	assert.Contains(t, op.RequestBody.Content, MediaTypeMergePatch)
+	assert.Contains(t, op.RequestBody.Content, MediaTypeJSONPatch)
+	assert.Contains(t, op.Responses, "200")
+	assert.Contains(t, op.Responses, "412")
+	assert.Contains(t, op.Responses, "415")
+
+	paramNames := make([]string, 0, len(op.Parameters))
+	for _, p := range op.Parameters {
+		paramNames = append(paramNames, p.In+":"+p.Name)
+	}
+	assert.Equal(t, []string{"path:id", "header:If-Match"}, paramNames)
+
+	req := httptest.NewRequest(http.MethodOptions, "/items/1", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, "GET, HEAD, OPTIONS, PATCH, PUT", recorder.Header().Get("Allow"))
+	assert.Equal(t, MediaTypeJSONPatch+", "+MediaTypeMergePatch, recorder.Header().Get("Accept-Patch"))
+}
+
+func TestAutoPatch_MiddlewareChain(t *testing.T) {
+	type requestIDKey struct{}
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	var methods []string
+	api.UseMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			if r.Method == http.MethodPatch {
+				r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, "req-1"))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	item := patchItem{Name: "Widget", Tags: []string{"new"}, Stars: 3}
+	var requestIDs []any
+	Get(api, "/items/{id}", func(ctx context.Context, input *GetPatchItemInput) (*PatchItemOutput, error) {
+		requestIDs = append(requestIDs, ctx.Value(requestIDKey{}))
+
+		return &PatchItemOutput{Body: item}, nil
+	})
+	Put(api, "/items/{id}", func(ctx context.Context, input *PutPatchItemInput) (*PatchItemOutput, error) {
+		requestIDs = append(requestIDs, ctx.Value(requestIDKey{}))
+		item = input.Body
+
+		return &PatchItemOutput{Body: item}, nil
+	}, RateLimit(NewMemoryRateLimitStore(), 1, time.Minute))
+	AutoPatch(api)
+
+	recorder := patchItemRequest(router, MediaTypeMergePatch, `{"Stars": 5}`, nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	// The PUT sub-request skips the API middlewares, which ran for the PATCH
+	// request, while the GET sub-request is authorized like a GET request.
+	assert.Equal(t, []string{http.MethodPatch, http.MethodGet}, methods)
+	assert.Equal(t, []any{"req-1", "req-1"}, requestIDs)
+	assert.Equal(t, "0", recorder.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, 5, item.Stars)
+
+	// The PATCH operation takes the rate limit of the PUT operation.
+	recorder = patchItemRequest(router, MediaTypeMergePatch, `{"Stars": 6}`, nil)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, 5, item.Stars)
+}
+
+func TestAutoPatch_Compress(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	api.UseMiddleware(Compress(CompressionConfig{MinSize: -1}))
+
+	item := patchItem{Name: "Widget", Tags: []string{"new"}, Stars: 3}
+	Get(api, "/items/{id}", func(ctx context.Context, input *GetPatchItemInput) (*PatchItemOutput, error) {
+		return &PatchItemOutput{Body: item}, nil
+	})
+	Put(api, "/items/{id}", func(ctx context.Context, input *PutPatchItemInput) (*PatchItemOutput, error) {
+		item = input.Body
+
+		return &PatchItemOutput{Body: item}, nil
+	})
+	AutoPatch(api)
+
+	recorder := patchItemRequest(router, MediaTypeMergePatch, `{"Stars": 5}`, map[string]string{"Accept-Encoding": "gzip"})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, EncodingGzip, recorder.Header().Get("Content-Encoding"))
+	assert.JSONEq(t, `{"Name": "Widget", "Tags": ["new"], "Stars": 5}`,
+		decompress(t, EncodingGzip, recorder.Body.Bytes()))
+	assert.Equal(t, 5, item.Stars)
+}
+
+func TestAutoPatch_GetAuthorization(t *testing.T) {
+	// Clients may replace items but not read them, so they cannot probe
+	// them with JSON Patch test operations either.
+	denied := chi.NewMux()
+	deniedAPI := NewAPI(&testChiAdapter{router: denied}, WithValidator(NewPlaygroundValidator(validator.New())))
+	deniedAPI.UseMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				WriteErr(deniedAPI, r, w, 0, "", Error403Forbidden("reading items is not allowed"))
+
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	item := patchItem{Name: "Widget", Tags: []string{"new"}, Stars: 3}
+	Get(deniedAPI, "/items/{id}", func(ctx context.Context, input *GetPatchItemInput) (*PatchItemOutput, error) {
+		return &PatchItemOutput{Body: item}, nil
+	})
+	Put(deniedAPI, "/items/{id}", func(ctx context.Context, input *PutPatchItemInput) (*PatchItemOutput, error) {
+		item = input.Body
+
+		return &PatchItemOutput{Body: item}, nil
+	})
+	AutoPatch(deniedAPI)
+
+	recorder := patchItemRequest(denied, MediaTypeJSONPatch, `[{"op": "test", "path": "/Stars", "value": 3}]`, nil)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Without the restriction the same patch succeeds.
+	_, _, router := newPatchTestAPI(t)
+	recorder = patchItemRequest(router, MediaTypeJSONPatch, `[{"op": "test", "path": "/Stars", "value": 3}]`, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestAutoPatch_IfMatchOtherFormat(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	item := patchItem{Name: "Widget", Tags: []string{"new"}, Stars: 3}
+	Get(api, "/items/{id}", func(ctx context.Context, input *GetPatchItemInput) (*PatchItemOutput, error) {
+		return &PatchItemOutput{Body: item}, nil
+	}, AutoETag())
+	Put(api, "/items/{id}", func(ctx context.Context, input *PutPatchItemInput) (*PatchItemOutput, error) {
+		item = input.Body
+
+		return &PatchItemOutput{Body: item}, nil
+	})
+	AutoPatch(api)
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("Accept", "application/cbor")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// The If-Match ETag of the CBOR representation is accepted.
+	recorder = patchItemRequest(router, MediaTypeMergePatch, `{"Stars": 5}`,
+		map[string]string{"If-Match": etag, "Accept": "application/cbor"})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, 5, item.Stars)
+
+	// It no longer matches once the resource changed.
+	recorder = patchItemRequest(router, MediaTypeMergePatch, `{"Stars": 6}`,
+		map[string]string{"If-Match": etag, "Accept": "application/cbor"})
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	assert.Equal(t, 5, item.Stars)
+}
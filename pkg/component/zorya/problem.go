@@ -0,0 +1,305 @@
+package zorya
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// NoExtensions is the extension type of problem types without extension members.
+type NoExtensions struct{}
+
+// ProblemInfo describes a problem type registered in a ProblemCatalog.
+type ProblemInfo struct {
+	// Name identifies the problem type in the catalog, e.g. user-email-taken.
+	Name string
+
+	// Type is the type URI of the problem type: the catalog base URI followed
+	// by the name.
+	Type string
+
+	// Title is the summary of the problem type, the same for every occurrence.
+	Title string
+
+	// Status is the HTTP status code of the problem type.
+	Status int
+
+	// Description explains the problem type in the catalog.
+	Description string
+
+	// Extensions is the type of the extension members, nil if there are none.
+	Extensions reflect.Type
+}
+
+// ProblemInfoProvider is implemented by problem types. See DefineProblem.
+type ProblemInfoProvider interface {
+	ProblemInfo() ProblemInfo
+}
+
+// ProblemCatalog holds the problem types of an API, so they have stable type
+// URIs, are documented per operation and can be served to developers. See
+// DefineProblem and ServeProblems.
+type ProblemCatalog struct {
+	base string
+
+	mu    sync.RWMutex
+	types []ProblemInfo
+}
+
+// NewProblemCatalog creates a catalog whose type URIs start with base, an
+// absolute URI such as https://api.example.com/problems or a path such as
+// /problems.
+func NewProblemCatalog(base string) *ProblemCatalog {
+	return &ProblemCatalog{base: strings.TrimSuffix(base, "/")}
+}
+
+// Types returns the problem types of the catalog, in definition order.
+func (c *ProblemCatalog) Types() []ProblemInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]ProblemInfo(nil), c.types...)
+}
+
+// Lookup returns the problem type named name.
+func (c *ProblemCatalog) Lookup(name string) (ProblemInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, info := range c.types {
+		if info.Name == name {
+			return info, true
+		}
+	}
+
+	return ProblemInfo{}, false
+}
+
+// add registers a problem type, panicking on a duplicate name.
+func (c *ProblemCatalog) add(info ProblemInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, existing := range c.types {
+		if existing.Name == info.Name {
+			panic("zorya.DefineProblem: duplicate problem type " + info.Name)
+		}
+	}
+	c.types = append(c.types, info)
+}
+
+// ProblemOption configures a problem type.
+type ProblemOption func(*ProblemInfo)
+
+// ProblemDescription sets the description of a problem type shown in the catalog.
+func ProblemDescription(description string) ProblemOption {
+	return func(info *ProblemInfo) {
+		info.Description = description
+	}
+}
+
+// ProblemType is a problem type whose occurrences carry the extension members
+// of E, a struct with json and schema tags. Use NoExtensions for problem
+// types without extension members.
+type ProblemType[E any] struct {
+	info ProblemInfo
+}
+
+// DefineProblem registers a problem type in catalog. Problem types are
+// typically package variables, defined once:
+//
+//	type EmailTaken struct {
+//		Email string `json:"email" schema:"email"`
+//	}
+//
+//	var ErrEmailTaken = zorya.DefineProblem[EmailTaken](problems, "user-email-taken",
+//		http.StatusConflict, "Email already taken")
+//
+//	return nil, ErrEmailTaken.New("an account already uses this email", EmailTaken{Email: input.Body.Email})
+//
+// It panics if the name is empty or contains a slash, if the name is already
+// defined, if status is not an error status or if E is not a struct.
+func DefineProblem[E any](
+	catalog *ProblemCatalog,
+	name string,
+	status int,
+	title string,
+	opts ...ProblemOption,
+) *ProblemType[E] {
+	if name == "" || strings.Contains(name, "/") {
+		panic(fmt.Sprintf("zorya.DefineProblem: invalid name %q", name))
+	}
+	if status < 400 || status > 599 {
+		panic(fmt.Sprintf("zorya.DefineProblem: %s has status %d, expected 4xx or 5xx", name, status))
+	}
+
+	extensions := reflect.TypeFor[E]()
+	if extensions.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("zorya.DefineProblem: extensions of %s must be a struct, got %s", name, extensions))
+	}
+	if extensions.NumField() == 0 {
+		extensions = nil
+	}
+
+	info := ProblemInfo{
+		Name:       name,
+		Type:       catalog.base + "/" + name,
+		Title:      title,
+		Status:     status,
+		Extensions: extensions,
+	}
+	for _, opt := range opts {
+		opt(&info)
+	}
+	catalog.add(info)
+
+	return &ProblemType[E]{info: info}
+}
+
+// ProblemInfo returns the description of the problem type.
+func (t *ProblemType[E]) ProblemInfo() ProblemInfo {
+	return t.info
+}
+
+// New returns an occurrence of the problem type with the given detail,
+// extension members and optional error details.
+func (t *ProblemType[E]) New(detail string, extensions E, errs ...error) *Problem[E] {
+	p := &Problem[E]{
+		ErrorModel: ErrorModel{
+			Type:   t.info.Type,
+			Title:  t.info.Title,
+			Status: t.info.Status,
+			Detail: detail,
+		},
+		Extensions: extensions,
+	}
+	for _, err := range errs {
+		p.Add(err)
+	}
+
+	return p
+}
+
+// Problem is an occurrence of a problem type. It is written like an
+// ErrorModel, with the members of Extensions next to the standard members.
+// Standard members take precedence over extension members of the same name.
+type Problem[E any] struct {
+	ErrorModel
+
+	// Extensions are the extension members of the problem.
+	Extensions E
+}
+
+// MarshalJSON writes the standard and extension members as one object.
+func (p *Problem[E]) MarshalJSON() ([]byte, error) {
+	members, err := p.members()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(members)
+}
+
+// MarshalCBOR writes the standard and extension members as one map.
+func (p *Problem[E]) MarshalCBOR() ([]byte, error) {
+	members, err := p.members()
+	if err != nil {
+		return nil, err
+	}
+
+	return cbor.Marshal(cborValue(members))
+}
+
+// members returns the extension members overlaid with the standard members.
+func (p *Problem[E]) members() (map[string]any, error) {
+	members, err := jsonMembers(p.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode problem extensions: %w", err)
+	}
+	standard, err := jsonMembers(&p.ErrorModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode problem: %w", err)
+	}
+	maps.Copy(members, standard)
+
+	return members, nil
+}
+
+// errorModel returns the standard members of the problem.
+func (p *Problem[E]) errorModel() *ErrorModel {
+	return &p.ErrorModel
+}
+
+// localize returns a copy of the problem with its title, detail and error
+// messages translated.
+func (p *Problem[E]) localize(locale *Locale) StatusError {
+	localized := *p
+	if model, ok := localizeError(&p.ErrorModel, locale).(*ErrorModel); ok {
+		localized.ErrorModel = *model
+	}
+
+	return &localized
+}
+
+// jsonMembers returns the members of the JSON object v is encoded to.
+func jsonMembers(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	members := map[string]any{}
+	if err := decoder.Decode(&members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// cborValue replaces the JSON numbers in v with integers or floats, which CBOR
+// would otherwise encode as text strings.
+func cborValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+
+		return v.String()
+	case map[string]any:
+		for key, value := range v {
+			v[key] = cborValue(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = cborValue(value)
+		}
+	}
+
+	return v
+}
+
+// Problems declares the problem types a route may return. Their statuses are
+// added to Errors and each status documents its problem types as a oneOf:
+//
+//	zorya.Post(api, "/users", createUser, zorya.Problems(ErrEmailTaken))
+func Problems(types ...ProblemInfoProvider) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		for _, t := range types {
+			info := t.ProblemInfo()
+			r.Problems = append(r.Problems, info)
+			r.Errors = append(r.Errors, info.Status)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package zorya
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// problemDocsTemplate renders the catalog index, with Problems, or the page of
+// a problem type, with Problem.
+var problemDocsTemplate = template.Must(template.New("problems").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+	<title>{{if .Problem}}{{.Problem.Title}}{{else}}Problem Types{{end}}</title>
+</head>
+<body>
+{{- if .Problem}}
+	<h1>{{.Problem.Title}}</h1>
+	<dl>
+		<dt>Type</dt><dd><code>{{.Problem.Type}}</code></dd>
+		<dt>Status</dt><dd>{{.Problem.Status}} {{.StatusText}}</dd>
+	</dl>
+	{{- if .Problem.Description}}
+	<p>{{.Problem.Description}}</p>
+	{{- end}}
+	{{- if .Members}}
+	<h2>Extension Members</h2>
+	<table>
+		<tr><th>Name</th><th>Type</th><th>Description</th></tr>
+		{{- range .Members}}
+		<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{.Description}}</td></tr>
+		{{- end}}
+	</table>
+	{{- end}}
+	<p><a href="{{.Index}}">All problem types</a></p>
+{{- else}}
+	<h1>Problem Types</h1>
+	<table>
+		<tr><th>Type</th><th>Status</th><th>Title</th></tr>
+		{{- range .Problems}}
+		<tr><td><a href="{{.Type}}"><code>{{.Name}}</code></a></td><td>{{.Status}}</td><td>{{.Title}}</td></tr>
+		{{- end}}
+	</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// problemMember is an extension member listed on a problem type page.
+type problemMember struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// ServeProblems serves the problem types of catalog as HTML pages at their
+// type URIs, and an index of them at the catalog base URI, so the type of a
+// problem document links to its explanation. The pages are served at the
+// path of the base URI; call it after the problem types are defined.
+func ServeProblems(api API, catalog *ProblemCatalog) {
+	base := catalog.base
+	if u, err := url.Parse(base); err == nil {
+		base = u.Path
+	}
+	if !strings.HasPrefix(base, "/") {
+		base = "/" + base
+	}
+
+	problems := catalog.Types()
+	index := renderProblemDocs(map[string]any{"Problems": problems})
+	handle := func(path string, page []byte) {
+		api.Adapter().Handle(&BaseRoute{Method: http.MethodGet, Path: path}, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(page)
+		})
+	}
+
+	handle(base, index)
+	for _, problem := range problems {
+		handle(base+"/"+problem.Name, renderProblemDocs(map[string]any{
+			"Problem":    problem,
+			"StatusText": http.StatusText(problem.Status),
+			"Members":    problemMembers(api.Registry(), problem),
+			"Index":      catalog.base,
+		}))
+	}
+}
+
+// renderProblemDocs renders a catalog page.
+func renderProblemDocs(data map[string]any) []byte {
+	var buf bytes.Buffer
+	if err := problemDocsTemplate.Execute(&buf, data); err != nil {
+		return []byte(err.Error())
+	}
+
+	return buf.Bytes()
+}
+
+// problemMembers lists the extension members of a problem type from their
+// schema, sorted by name.
+func problemMembers(registry Registry, problem ProblemInfo) []problemMember {
+	if problem.Extensions == nil {
+		return nil
+	}
+
+	s := registry.Schema(problem.Extensions, false, problem.Extensions.Name())
+	members := make([]problemMember, 0, len(s.Properties))
+	for name, property := range s.Properties {
+		typ := property.Type
+		if property.Ref != "" {
+			typ = TypeObject
+		}
+		members = append(members, problemMember{Name: name, Type: typ, Description: property.Description})
+	}
+	slices.SortFunc(members, func(a, b problemMember) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return members
+}
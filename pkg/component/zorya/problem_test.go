@@ -0,0 +1,261 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type EmailTakenExtensions struct {
+	Email string `json:"email" schema:"email" openapi:"description=Email address already in use."`
+}
+
+type CreateProblemUserInput struct {
+	Body struct {
+		Email string `json:"email" schema:"email"`
+	} `body:"structured"`
+}
+
+type ProblemUserOutput struct {
+	Body struct {
+		Email string `json:"email"`
+	} `body:"structured"`
+}
+
+func newProblemTestAPI(t *testing.T) (*chi.Mux, API, *ProblemCatalog) {
+	t.Helper()
+
+	catalog := NewProblemCatalog("https://api.example.com/problems/")
+	emailTaken := DefineProblem[EmailTakenExtensions](catalog, "user-email-taken", http.StatusConflict,
+		"Email already taken", ProblemDescription("An account already uses the email address."))
+	quotaExceeded := DefineProblem[NoExtensions](catalog, "quota-exceeded", http.StatusConflict, "Quota exceeded")
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Post(api, "/users", func(ctx context.Context, input *CreateProblemUserInput) (*ProblemUserOutput, error) {
+		switch input.Body.Email {
+		case "taken@example.com":
+			return nil, emailTaken.New("an account already uses this email", EmailTakenExtensions{Email: input.Body.Email})
+		case "quota@example.com":
+			return nil, quotaExceeded.New("too many accounts", NoExtensions{})
+		}
+
+		return &ProblemUserOutput{}, nil
+	}, Problems(emailTaken, quotaExceeded, emailTaken))
+	ServeProblems(api, catalog)
+
+	return router, api, catalog
+}
+
+func TestProblems_Write(t *testing.T) {
+	router, _, _ := newProblemTestAPI(t)
+
+	post := func(email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"email": "`+email+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	recorder := post("taken@example.com")
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "https://api.example.com/problems/user-email-taken",
+		"title": "Email already taken",
+		"status": 409,
+		"detail": "an account already uses this email",
+		"email": "taken@example.com"
+	}`, recorder.Body.String())
+
+	recorder = post("quota@example.com")
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.JSONEq(t, `{
+		"type": "https://api.example.com/problems/quota-exceeded",
+		"title": "Quota exceeded",
+		"status": 409,
+		"detail": "too many accounts"
+	}`, recorder.Body.String())
+}
+
+func TestProblems_StandardMembersWin(t *testing.T) {
+	catalog := NewProblemCatalog("/problems")
+	type Clashing struct {
+		Status string `json:"status"`
+		Limit  int    `json:"limit"`
+	}
+	problem := DefineProblem[Clashing](catalog, "clash", http.StatusTooManyRequests, "Clash")
+
+	data, err := json.Marshal(problem.New("slow down", Clashing{Status: "ignored", Limit: 10}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "/problems/clash",
+		"title": "Clash",
+		"status": 429,
+		"detail": "slow down",
+		"limit": 10
+	}`, string(data))
+}
+
+func TestProblems_CBOR(t *testing.T) {
+	catalog := NewProblemCatalog("/problems")
+	type Limited struct {
+		Limit int     `json:"limit"`
+		Ratio float64 `json:"ratio"`
+		Sizes []int   `json:"sizes"`
+		Email string  `json:"email"`
+	}
+	problem := DefineProblem[Limited](catalog, "limited", http.StatusTooManyRequests, "Limited")
+
+	data, err := cbor.Marshal(problem.New("slow down", Limited{Limit: 10, Ratio: 0.5, Sizes: []int{1, 2}, Email: "a@example.com"}))
+	require.NoError(t, err)
+
+	var members map[string]any
+	require.NoError(t, cbor.Unmarshal(data, &members))
+	assert.Equal(t, map[string]any{
+		"type":   "/problems/limited",
+		"title":  "Limited",
+		"status": uint64(http.StatusTooManyRequests),
+		"detail": "slow down",
+		"limit":  uint64(10),
+		"ratio":  0.5,
+		"sizes":  []any{uint64(1), uint64(2)},
+		"email":  "a@example.com",
+	}, members)
+}
+
+func TestDefineProblem_Panics(t *testing.T) {
+	catalog := NewProblemCatalog("/problems")
+	DefineProblem[NoExtensions](catalog, "gone", http.StatusGone, "Gone")
+
+	assert.Panics(t, func() { DefineProblem[NoExtensions](catalog, "gone", http.StatusGone, "Gone") })
+	assert.Panics(t, func() { DefineProblem[NoExtensions](catalog, "", http.StatusGone, "Gone") })
+	assert.Panics(t, func() { DefineProblem[NoExtensions](catalog, "a/b", http.StatusGone, "Gone") })
+	assert.Panics(t, func() { DefineProblem[NoExtensions](catalog, "ok", http.StatusOK, "OK") })
+	assert.Panics(t, func() { DefineProblem[string](catalog, "text", http.StatusGone, "Gone") })
+
+	info, ok := catalog.Lookup("gone")
+	require.True(t, ok)
+	assert.Equal(t, "/problems/gone", info.Type)
+	assert.Nil(t, info.Extensions)
+	assert.Len(t, catalog.Types(), 1)
+}
+
+func TestProblems_OpenAPI(t *testing.T) {
+	_, api, _ := newProblemTestAPI(t)
+
+	op := api.OpenAPI().Paths["/users"].Post
+	require.NotNil(t, op)
+	require.Contains(t, op.Responses, "409")
+
+	data, err := json.Marshal(op.Responses["409"].Content["application/problem+json"].Schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"oneOf": [
+		{
+			"title": "Email already taken",
+			"description": "An account already uses the email address.",
+			"allOf": [
+				{"$ref": "#/components/schemas/ErrorModel"},
+				{"$ref": "#/components/schemas/EmailTakenExtensions"},
+				{"type": "object", "properties": {"type": {"type": "string", "enum": ["https://api.example.com/problems/user-email-taken"]}}, "required": ["type"]}
+			]
+		},
+		{
+			"title": "Quota exceeded",
+			"allOf": [
+				{"$ref": "#/components/schemas/ErrorModel"},
+				{"type": "object", "properties": {"type": {"type": "string", "enum": ["https://api.example.com/problems/quota-exceeded"]}}, "required": ["type"]}
+			]
+		},
+		{
+			"description": "Problem of another type.",
+			"allOf": [{"$ref": "#/components/schemas/ErrorModel"}],
+			"not": {"type": "object", "properties": {"type": {"type": "string", "enum": [
+				"https://api.example.com/problems/user-email-taken",
+				"https://api.example.com/problems/quota-exceeded"
+			]}}, "required": ["type"]}
+		}
+	]}`, string(data))
+
+	extensions := api.OpenAPI().Components.Schemas["EmailTakenExtensions"]
+	require.NotNil(t, extensions)
+	assert.Contains(t, extensions.Properties, "email")
+
+	// Other statuses keep the generic error schema.
+	assert.Equal(t, "#/components/schemas/ErrorModel", op.Responses["500"].Content["application/problem+json"].Schema.Ref)
+}
+
+func TestServeProblems(t *testing.T) {
+	router, _, _ := newProblemTestAPI(t)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/problems/user-email-taken", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+	page := recorder.Body.String()
+	assert.Contains(t, page, "<h1>Email already taken</h1>")
+	assert.Contains(t, page, "<code>https://api.example.com/problems/user-email-taken</code>")
+	assert.Contains(t, page, "409 Conflict")
+	assert.Contains(t, page, "An account already uses the email address.")
+	assert.Contains(t, page, "<tr><td><code>email</code></td><td>string</td><td>Email address already in use.</td></tr>")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/problems", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `<a href="https://api.example.com/problems/quota-exceeded"><code>quota-exceeded</code></a>`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/problems/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestProblems_Localized(t *testing.T) {
+	_, api := newLocalizedTestAPI(t)
+	catalog := NewProblemCatalog("/problems")
+	notFound := DefineProblem[EmailTakenExtensions](catalog, "user-missing", http.StatusNotFound, "Not Found")
+	problem := notFound.New("user not found", EmailTakenExtensions{Email: "a@example.com"})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de")
+	recorder := httptest.NewRecorder()
+	WriteErr(api, req, recorder, 0, "", problem)
+
+	assert.JSONEq(t, `{
+		"type": "/problems/user-missing",
+		"title": "Nicht gefunden",
+		"status": 404,
+		"detail": "Benutzer nicht gefunden",
+		"email": "a@example.com"
+	}`, recorder.Body.String())
+	assert.Equal(t, "user not found", problem.Detail)
+}
+
+func TestProblems_FailedJobKeepsType(t *testing.T) {
+	catalog := NewProblemCatalog("/problems")
+	unsupported := DefineProblem[NoExtensions](catalog, "unsupported-format", http.StatusUnprocessableEntity, "Unsupported format")
+	api := NewAPI(&testChiAdapter{router: chi.NewMux()})
+	store := NewMemoryJobStore()
+	jobs := NewJobs(api, store)
+
+	accepted, err := jobs.Start(t.Context(), func(ctx context.Context, job *Job) (any, error) {
+		return nil, unsupported.New("image/x-unknown is not supported", NoExtensions{})
+	})
+	require.NoError(t, err)
+	jobs.Wait()
+
+	status, err := store.Get(t.Context(), accepted.Body.ID)
+	require.NoError(t, err)
+	require.NotNil(t, status.Error)
+	assert.Equal(t, "/problems/unsupported-format", status.Error.Type)
+	assert.Equal(t, "Unsupported format", status.Error.Title)
+}
@@ -0,0 +1,368 @@
+package zorya
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// IETF RateLimit header fields (draft-ietf-httpapi-ratelimit-headers).
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// RateLimitAlgorithm selects how a RateLimitStore counts requests.
+type RateLimitAlgorithm string
+
+const (
+	// TokenBucket refills Limit tokens evenly over Window and allows bursts of
+	// up to Limit requests.
+	TokenBucket RateLimitAlgorithm = "token-bucket"
+
+	// SlidingWindow allows at most Limit requests in any period of length Window.
+	SlidingWindow RateLimitAlgorithm = "sliding-window"
+)
+
+// RateLimitPolicy is the quota a RateLimitStore enforces for a key.
+type RateLimitPolicy struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+}
+
+// RateLimitResult is the outcome of counting a request against a RateLimitPolicy.
+type RateLimitResult struct {
+	// Allowed reports whether the request is within the quota.
+	Allowed bool
+
+	// Remaining is the number of requests still allowed right now.
+	Remaining int
+
+	// Reset is the time until the quota is fully restored.
+	Reset time.Duration
+
+	// RetryAfter is the time until the next request is allowed. It is only
+	// set when the request is not allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore counts requests per key. Implementations must be safe for
+// concurrent use.
+type RateLimitStore interface {
+	// Take counts a request for key against policy. Requests that are not
+	// allowed are not counted.
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+
+	// Refund uncounts a request counted by Take, when another limit of the
+	// route rejects it.
+	Refund(ctx context.Context, key string, policy RateLimitPolicy) error
+}
+
+// RateLimitKeyFunc returns the key requests are counted under, such as the
+// client IP or the authenticated user ID.
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RouteRateLimit configures a request rate limit for a route.
+type RouteRateLimit struct {
+	// Store counts requests.
+	Store RateLimitStore
+
+	// Algorithm, Limit and Window define the quota.
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+
+	// Key returns the key requests are counted under. Defaults to the client
+	// IP taken from the connection.
+	Key RateLimitKeyFunc
+
+	// Scope groups the routes sharing a quota. Routes with an empty scope have
+	// their own quota.
+	Scope string
+}
+
+// RateLimitOption configures a rate limit.
+type RateLimitOption func(*RouteRateLimit)
+
+// RateLimitUsing selects the algorithm counting requests. Defaults to TokenBucket.
+func RateLimitUsing(algorithm RateLimitAlgorithm) RateLimitOption {
+	return func(l *RouteRateLimit) {
+		l.Algorithm = algorithm
+	}
+}
+
+// RateLimitKey sets the function returning the key requests are counted under.
+func RateLimitKey(key RateLimitKeyFunc) RateLimitOption {
+	return func(l *RouteRateLimit) {
+		l.Key = key
+	}
+}
+
+// RateLimitScope makes every route using the same scope and store share a quota.
+func RateLimitScope(scope string) RateLimitOption {
+	return func(l *RouteRateLimit) {
+		l.Scope = scope
+	}
+}
+
+// RateLimitByIP returns a key function counting requests per client IP.
+//
+// The client IP is the address of the connection. When the connection comes
+// from one of the trusted proxies, the X-Forwarded-For header is read from
+// right to left and the first address that is not a trusted proxy is used.
+//
+//	zorya.RateLimitKey(zorya.RateLimitByIP(netip.MustParsePrefix("10.0.0.0/8")))
+func RateLimitByIP(trustedProxies ...netip.Prefix) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		return "ip:" + clientIP(r, trustedProxies)
+	}
+}
+
+// RateLimit limits the request rate of a route to limit requests per window.
+//
+// Requests are counted per client IP unless RateLimitKey sets another key
+// function. Responses carry the RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers, and requests over the limit
+// get 429 Too Many Requests with a Retry-After header. The option can be used
+// several times to combine limits, e.g. a burst limit and a daily quota.
+//
+//	zorya.Post(api, "/login", login,
+//		zorya.RateLimit(store, 5, time.Minute, zorya.RateLimitUsing(zorya.SlidingWindow)),
+//	)
+func RateLimit(store RateLimitStore, limit int, window time.Duration, opts ...RateLimitOption) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.RateLimits = append(r.RateLimits, newRouteRateLimit(store, limit, window, opts))
+	}
+}
+
+// newRouteRateLimit creates a rate limit with defaults and applies options.
+func newRouteRateLimit(store RateLimitStore, limit int, window time.Duration, opts []RateLimitOption) *RouteRateLimit {
+	if limit <= 0 || window <= 0 {
+		panic("zorya.RateLimit() requires a positive limit and window.")
+	}
+
+	l := &RouteRateLimit{
+		Store:     store,
+		Algorithm: TokenBucket,
+		Limit:     limit,
+		Window:    window,
+		Key:       RateLimitByIP(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// groupRateLimitScopes numbers the quotas shared by the routes of a group.
+var groupRateLimitScopes atomic.Int64
+
+// UseRateLimit limits the request rate of all routes in the group. The routes
+// share one quota unless RateLimitScope is set. See RateLimit.
+func (g *Group) UseRateLimit(store RateLimitStore, limit int, window time.Duration, opts ...RateLimitOption) {
+	opts = append([]RateLimitOption{
+		RateLimitScope("group-" + strconv.FormatInt(groupRateLimitScopes.Add(1), 10)),
+	}, opts...)
+	l := newRouteRateLimit(store, limit, window, opts)
+
+	g.UseRouteOptions(func(r *BaseRoute) {
+		r.RateLimits = append(r.RateLimits, l)
+	})
+}
+
+// newRateLimitMiddleware returns the middleware enforcing the rate limits of
+// the route, or nil if the route has none.
+func newRateLimitMiddleware(api API, route *BaseRoute) Middleware {
+	if len(route.RateLimits) == 0 {
+		return nil
+	}
+
+	limits := route.RateLimits
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				reported *RouteRateLimit
+				result   RateLimitResult
+				taken    []rateLimitTake
+			)
+
+			// Requests rejected by a limit are not counted by the others.
+			refund := func() {
+				ctx := context.WithoutCancel(r.Context())
+				for _, t := range taken {
+					_ = t.limit.Store.Refund(ctx, t.key, t.limit.policy())
+				}
+			}
+
+			for _, l := range limits {
+				scope := l.Scope
+				if scope == "" {
+					scope = route.Method + " " + route.Path
+				}
+
+				key := scope + " " + l.Key(r)
+				res, err := l.Store.Take(r.Context(), key, l.policy())
+				if err != nil {
+					refund()
+					WriteErr(api, r, w, http.StatusInternalServerError, "failed to check rate limit", err)
+
+					return
+				}
+
+				// Report the limit closest to being exceeded.
+				if reported == nil || !res.Allowed || (result.Allowed && res.Remaining < result.Remaining) {
+					reported, result = l, res
+				}
+				if !res.Allowed {
+					refund()
+
+					break
+				}
+				taken = append(taken, rateLimitTake{limit: l, key: key})
+			}
+
+			writeRateLimitHeaders(w.Header(), reported, result)
+
+			if !result.Allowed {
+				retryAfter := ceilSeconds(result.RetryAfter)
+				w.Header().Set(HeaderRetryAfter, strconv.Itoa(retryAfter))
+				WriteErr(api, r, w, 0, "", Error429TooManyRequests(
+					fmt.Sprintf("rate limit exceeded, retry in %d seconds", retryAfter),
+				))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitTake is a request counted by a limit, refunded if another limit
+// rejects the request.
+type rateLimitTake struct {
+	limit *RouteRateLimit
+	key   string
+}
+
+// policy returns the quota of the limit.
+func (l *RouteRateLimit) policy() RateLimitPolicy {
+	return RateLimitPolicy{Algorithm: l.Algorithm, Limit: l.Limit, Window: l.Window}
+}
+
+// writeRateLimitHeaders sets the RateLimit header fields of a response.
+func writeRateLimitHeaders(h http.Header, l *RouteRateLimit, result RateLimitResult) {
+	h.Set(HeaderRateLimitLimit, strconv.Itoa(l.Limit))
+	h.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+	h.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+	h.Set(HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%d", l.Limit, ceilSeconds(l.Window)))
+}
+
+// ceilSeconds rounds a duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// clientIP returns the IP of the client sending the request, trusting the
+// X-Forwarded-For header only when the connection comes from a trusted proxy.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isTrustedProxy(addr, trustedProxies) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !isTrustedProxy(addr, trustedProxies) {
+			break
+		}
+	}
+
+	return addr.String()
+}
+
+// isTrustedProxy reports whether addr belongs to one of the trusted proxies.
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// documentRateLimit documents the 429 response of a route with rate limits.
+func documentRateLimit(route *BaseRoute) {
+	if len(route.RateLimits) == 0 {
+		return
+	}
+
+	route.Errors = append(route.Errors, http.StatusTooManyRequests)
+}
+
+// documentRateLimitHeaders documents the RateLimit and Retry-After headers of
+// a route with rate limits. It runs once the responses have been generated.
+func documentRateLimitHeaders(route *BaseRoute) {
+	if len(route.RateLimits) == 0 {
+		return
+	}
+
+	headers := map[string]*Param{
+		HeaderRateLimitLimit: {
+			Description: "Maximum number of requests allowed in the current window.",
+			Schema:      &Schema{Type: TypeInteger},
+		},
+		HeaderRateLimitRemaining: {
+			Description: "Number of requests remaining in the current window.",
+			Schema:      &Schema{Type: TypeInteger},
+		},
+		HeaderRateLimitReset: {
+			Description: "Number of seconds until the quota is fully restored.",
+			Schema:      &Schema{Type: TypeInteger},
+		},
+		HeaderRateLimitPolicy: {
+			Description: "Quota policy, as the limit and the window in seconds (e.g. `100;w=60`).",
+			Schema:      &Schema{Type: TypeString},
+		},
+	}
+
+	for _, status := range []int{getDefaultStatus(route), http.StatusTooManyRequests} {
+		resp := getResponse(route.Operation, status)
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]*Param)
+		}
+		for name, header := range headers {
+			resp.Headers[name] = header
+		}
+	}
+
+	getResponse(route.Operation, http.StatusTooManyRequests).Headers[HeaderRetryAfter] = &Param{
+		Description: "Number of seconds to wait before retrying the request.",
+		Schema:      &Schema{Type: TypeInteger},
+	}
+}
@@ -0,0 +1,140 @@
+package zorya
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryRateLimitStore is an in-memory RateLimitStore. Counters are lost on
+// restart and not shared between instances, so limits apply per instance.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryRateLimitEntry
+	lastSweep time.Time
+}
+
+type memoryRateLimitEntry struct {
+	// tokens and updated hold the state of a token bucket.
+	tokens  float64
+	updated time.Time
+
+	// hits holds the times of the requests allowed in the current window.
+	hits []time.Time
+
+	expiresAt time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{entries: make(map[string]*memoryRateLimitEntry)}
+}
+
+// Take counts a request for key against policy.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		entry = &memoryRateLimitEntry{tokens: float64(policy.Limit), updated: now}
+		s.entries[key] = entry
+	}
+
+	switch policy.Algorithm {
+	case TokenBucket, "":
+		return entry.takeToken(now, policy), nil
+	case SlidingWindow:
+		return entry.takeHit(now, policy), nil
+	default:
+		return RateLimitResult{}, fmt.Errorf("unsupported rate limit algorithm %q", policy.Algorithm)
+	}
+}
+
+// Refund gives back a request counted by Take for key.
+func (s *MemoryRateLimitStore) Refund(_ context.Context, key string, policy RateLimitPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+
+	switch policy.Algorithm {
+	case TokenBucket, "":
+		entry.tokens = math.Min(float64(policy.Limit), entry.tokens+1)
+	case SlidingWindow:
+		if len(entry.hits) > 0 {
+			entry.hits = entry.hits[:len(entry.hits)-1]
+		}
+	default:
+		return fmt.Errorf("unsupported rate limit algorithm %q", policy.Algorithm)
+	}
+
+	return nil
+}
+
+// takeToken takes a token from the bucket, refilling it for the time elapsed
+// since the last request.
+func (e *memoryRateLimitEntry) takeToken(now time.Time, policy RateLimitPolicy) RateLimitResult {
+	limit := float64(policy.Limit)
+	perToken := policy.Window / time.Duration(policy.Limit)
+
+	e.tokens = math.Min(limit, e.tokens+float64(now.Sub(e.updated))/float64(perToken))
+	e.updated = now
+
+	result := RateLimitResult{Allowed: e.tokens >= 1}
+	if result.Allowed {
+		e.tokens--
+	} else {
+		result.RetryAfter = time.Duration((1 - e.tokens) * float64(perToken))
+	}
+	result.Remaining = int(e.tokens)
+	result.Reset = time.Duration((limit - e.tokens) * float64(perToken))
+	e.expiresAt = now.Add(result.Reset)
+
+	return result
+}
+
+// takeHit records a request if fewer than Limit requests were allowed during
+// the last Window.
+func (e *memoryRateLimitEntry) takeHit(now time.Time, policy RateLimitPolicy) RateLimitResult {
+	start := now.Add(-policy.Window)
+	expired := 0
+	for expired < len(e.hits) && !e.hits[expired].After(start) {
+		expired++
+	}
+	e.hits = e.hits[expired:]
+
+	result := RateLimitResult{Allowed: len(e.hits) < policy.Limit}
+	if result.Allowed {
+		e.hits = append(e.hits, now)
+	} else {
+		result.RetryAfter = e.hits[0].Add(policy.Window).Sub(now)
+	}
+	result.Remaining = policy.Limit - len(e.hits)
+	result.Reset = e.hits[len(e.hits)-1].Add(policy.Window).Sub(now)
+	e.expiresAt = now.Add(result.Reset)
+
+	return result
+}
+
+// sweep removes expired entries, at most once a minute.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package zorya
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type GreetingOutput struct {
+	Body struct {
+		Message string
+	} `body:"structured"`
+}
+
+func getGreetingFrom(router http.Handler, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func greetingHandler(ctx context.Context, input *struct{}) (*GreetingOutput, error) {
+	output := &GreetingOutput{}
+	output.Body.Message = "Hello"
+
+	return output, nil
+}
+
+func TestRateLimit_TokenBucket(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Get(api, "/greeting", greetingHandler, RateLimit(NewMemoryRateLimitStore(), 2, time.Minute))
+
+	first := getGreetingFrom(router, "/greeting", "192.0.2.1:1234")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "1", first.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "30", first.Header().Get(HeaderRateLimitReset))
+	assert.Equal(t, "2;w=60", first.Header().Get(HeaderRateLimitPolicy))
+
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+
+	limited := getGreetingFrom(router, "/greeting", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "application/problem+json", limited.Header().Get("Content-Type"))
+	assert.Equal(t, "0", limited.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "30", limited.Header().Get(HeaderRetryAfter))
+
+	// Other clients have their own quota.
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", "192.0.2.2:1234").Code)
+}
+
+func TestRateLimit_SlidingWindow(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Get(api, "/greeting", greetingHandler,
+		RateLimit(NewMemoryRateLimitStore(), 2, 50*time.Millisecond, RateLimitUsing(SlidingWindow)),
+	)
+
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+	limited := getGreetingFrom(router, "/greeting", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "1", limited.Header().Get(HeaderRetryAfter))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+}
+
+func TestRateLimit_CustomKey(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Get(api, "/greeting", greetingHandler, RateLimit(NewMemoryRateLimitStore(), 1, time.Minute,
+		RateLimitKey(func(r *http.Request) string { return r.URL.Query().Get("tenant") }),
+	))
+
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting?tenant=a", "192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, getGreetingFrom(router, "/greeting?tenant=a", "192.0.2.2:1234").Code)
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting?tenant=b", "192.0.2.1:1234").Code)
+}
+
+func TestGroup_UseRateLimit(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	grp := NewGroup(api, "/v1")
+	grp.UseRateLimit(NewMemoryRateLimitStore(), 2, time.Minute)
+	Get(grp, "/a", greetingHandler)
+	Get(grp, "/b", greetingHandler, RateLimit(NewMemoryRateLimitStore(), 5, time.Minute))
+
+	// The routes of the group share the group quota.
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/v1/a", "192.0.2.1:1234").Code)
+	second := getGreetingFrom(router, "/v1/b", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, second.Code)
+	// The most restrictive limit is reported.
+	assert.Equal(t, "2", second.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "0", second.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, http.StatusTooManyRequests, getGreetingFrom(router, "/v1/a", "192.0.2.1:1234").Code)
+}
+
+func TestRateLimit_RejectedRequestsAreRefunded(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Get(api, "/greeting", greetingHandler,
+		RateLimit(store, 10, time.Minute),
+		RateLimit(store, 1, time.Minute, RateLimitScope("burst")),
+	)
+
+	assert.Equal(t, http.StatusOK, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, getGreetingFrom(router, "/greeting", "192.0.2.1:1234").Code)
+
+	// Only the allowed request was counted by the first limit.
+	result, err := store.Take(t.Context(), "GET /greeting ip:192.0.2.1", RateLimitPolicy{
+		Algorithm: TokenBucket, Limit: 10, Window: time.Minute,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 8, result.Remaining)
+}
+
+func TestMemoryRateLimitStore_Refund(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := t.Context()
+
+	for _, algorithm := range []RateLimitAlgorithm{TokenBucket, SlidingWindow} {
+		policy := RateLimitPolicy{Algorithm: algorithm, Limit: 1, Window: time.Minute}
+		key := string(algorithm)
+
+		result, err := store.Take(ctx, key, policy)
+		require.NoError(t, err)
+		require.True(t, result.Allowed, algorithm)
+
+		require.NoError(t, store.Refund(ctx, key, policy))
+		result, err = store.Take(ctx, key, policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed, algorithm)
+
+		result, err = store.Take(ctx, key, policy)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed, algorithm)
+	}
+
+	// Unknown keys are ignored.
+	assert.NoError(t, store.Refund(ctx, "unknown", RateLimitPolicy{Algorithm: TokenBucket, Limit: 1, Window: time.Minute}))
+}
+
+func TestRateLimitByIP_TrustedProxies(t *testing.T) {
+	key := RateLimitByIP(netip.MustParsePrefix("10.0.0.0/8"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.9, 10.0.0.2")
+	assert.Equal(t, "ip:203.0.113.9", key(req))
+
+	// Forwarded headers from untrusted clients are ignored.
+	req.RemoteAddr = "192.0.2.1:1234"
+	assert.Equal(t, "ip:192.0.2.1", key(req))
+}
+
+func TestRateLimit_OpenAPI(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Get(api, "/greeting", greetingHandler, RateLimit(NewMemoryRateLimitStore(), 2, time.Minute))
+
+	op := api.OpenAPI().Paths["/greeting"].Get
+	require.Contains(t, op.Responses, "429")
+	assert.Contains(t, op.Responses["429"].Headers, HeaderRetryAfter)
+	assert.Contains(t, op.Responses["429"].Headers, HeaderRateLimitRemaining)
+	assert.Contains(t, op.Responses["200"].Headers, HeaderRateLimitLimit)
+	assert.NotContains(t, op.Responses["200"].Headers, HeaderRetryAfter)
+}
@@ -0,0 +1,154 @@
+package zorya
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// HeaderRequestID is the header read by Recoverer for the request ID by default.
+const HeaderRequestID = "X-Request-Id"
+
+// RecovererOption configures Recoverer.
+type RecovererOption func(*recoverer)
+
+// RecoverRequestID sets the function returning the ID of a request, e.g. the
+// ID stored by a router middleware:
+//
+//	zorya.RecoverRequestID(func(r *http.Request) string {
+//		return middleware.GetReqID(r.Context())
+//	})
+//
+// Defaults to the X-Request-Id request header.
+func RecoverRequestID(requestID func(r *http.Request) string) RecovererOption {
+	return func(rec *recoverer) {
+		rec.requestID = requestID
+	}
+}
+
+// Recoverer returns a middleware recovering from panics in handlers and the
+// middlewares that run after it. It logs the panic and its stack with the
+// request ID and writes a 500 problem whose instance is the request ID, so
+// clients can report it. Requests without an ID get a random one.
+//
+//	api.UseMiddleware(zorya.Recoverer(api))
+//
+// If the response has already started, the problem cannot be written and the
+// connection is aborted, so clients do not mistake a truncated body for a
+// complete one. http.ErrAbortHandler panics are passed on unchanged.
+func Recoverer(api API, opts ...RecovererOption) Middleware {
+	rec := &recoverer{
+		api: api,
+		requestID: func(r *http.Request) string {
+			return r.Header.Get(HeaderRequestID)
+		},
+	}
+	for _, opt := range opts {
+		opt(rec)
+	}
+
+	return rec.middleware
+}
+
+type recoverer struct {
+	api       API
+	requestID func(r *http.Request) string
+}
+
+func (rec *recoverer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoverWriter{ResponseWriter: w}
+
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			rec.recovered(rw, r, v)
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// recovered logs a recovered panic and writes its problem.
+func (rec *recoverer) recovered(w *recoverWriter, r *http.Request, v any) {
+	stack := debug.Stack()
+	if hp, ok := v.(*handlerPanic); ok {
+		v, stack = hp.value, hp.stack
+	}
+
+	id := rec.requestID(r)
+	if id == "" {
+		id = rand.Text()
+	}
+
+	rec.api.Logger().ErrorContext(r.Context(), "handler panicked",
+		slog.String("request_id", id),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Any("panic", v),
+		slog.String("stack", string(stack)),
+	)
+
+	if w.wroteHeader {
+		panic(http.ErrAbortHandler)
+	}
+
+	problem := &ErrorModel{
+		Status:   http.StatusInternalServerError,
+		Title:    http.StatusText(http.StatusInternalServerError),
+		Detail:   "internal server error",
+		Instance: id,
+	}
+	WriteErr(rec.api, r, w, 0, "", problem)
+}
+
+// handlerPanic carries a panic and its stack from the goroutine of a handler
+// to the request goroutine, where Recoverer handles it. See Timeout.
+type handlerPanic struct {
+	value any
+	stack []byte
+}
+
+// String returns the panic value and its stack, as printed by net/http for
+// panics not handled by Recoverer.
+func (p *handlerPanic) String() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+// recoverWriter records whether the response has started.
+type recoverWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *recoverWriter) WriteHeader(status int) {
+	if status >= http.StatusOK {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoverWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer, which starts the response.
+func (w *recoverWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *recoverWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
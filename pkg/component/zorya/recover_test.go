@@ -0,0 +1,113 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRecovererTestAPI(t *testing.T, opts ...RecovererOption) (*chi.Mux, *bytes.Buffer) {
+	t.Helper()
+
+	var logs bytes.Buffer
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	api.UseMiddleware(Recoverer(api, opts...))
+
+	Get(api, "/panic", func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		panic("boom")
+	})
+	Get(api, "/ok", func(ctx context.Context, input *struct{}) (*GreetingOutput, error) {
+		output := &GreetingOutput{}
+		output.Body.Message = "hello"
+
+		return output, nil
+	})
+
+	return router, &logs
+}
+
+func TestRecoverer_Problem(t *testing.T) {
+	router, logs := newRecovererTestAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(HeaderRequestID, "req-42")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.JSONEq(t, `{
+		"title": "Internal Server Error",
+		"status": 500,
+		"detail": "internal server error",
+		"instance": "req-42"
+	}`, recorder.Body.String())
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "handler panicked", entry["msg"])
+	assert.Equal(t, "req-42", entry["request_id"])
+	assert.Equal(t, "boom", entry["panic"])
+	assert.Contains(t, entry["stack"], "recover_test.go")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRecoverer_RequestID(t *testing.T) {
+	type requestIDKey struct{}
+	router, logs := newRecovererTestAPI(t, RecoverRequestID(func(r *http.Request) string {
+		id, _ := r.Context().Value(requestIDKey{}).(string)
+
+		return id
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req = req.WithContext(context.WithValue(req.Context(), requestIDKey{}, "ctx-7"))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Contains(t, recorder.Body.String(), `"instance":"ctx-7"`)
+	assert.Contains(t, logs.String(), `"request_id":"ctx-7"`)
+
+	// Requests without an ID get a generated one, logged with the panic.
+	logs.Reset()
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	var problem ErrorModel
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	require.NotEmpty(t, problem.Instance)
+	assert.Contains(t, logs.String(), `"request_id":"`+problem.Instance+`"`)
+}
+
+func TestRecoverer_AbortsStartedResponses(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithLogger(slog.New(slog.DiscardHandler)))
+	api.UseMiddleware(Recoverer(api))
+	api.UseMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"partial":`))
+			panic("late")
+		})
+	})
+	Get(api, "/partial", func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return &struct{}{}, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/partial", nil))
+	})
+	assert.False(t, strings.Contains(recorder.Body.String(), "Internal Server Error"))
+}
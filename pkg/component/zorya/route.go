@@ -2,6 +2,7 @@ package zorya
 
 import (
 	"net/http"
+	"reflect"
 	"time"
 )
 
@@ -45,6 +46,11 @@ type BaseRoute struct {
 	// If < 0, disables the limit (no size restriction).
 	MaxBodyBytes int64
 
+	// Timeout limits the time the handler may take. If > 0, the handler
+	// context is canceled after the timeout and a 503 problem is written.
+	// See Timeout.
+	Timeout time.Duration
+
 	// Errors is a list of HTTP status codes that the handler may return. If
 	// not specified, then a default error response is added to the OpenAPI.
 	// This is a convenience for handlers that return a fixed set of errors
@@ -54,10 +60,47 @@ type BaseRoute struct {
 	// or `huma.NewErrorWithContext`.
 	Errors []int
 
+	// Problems are the problem types the handler may return, documented as a
+	// oneOf under their status codes. See Problems.
+	Problems []ProblemInfo
+
 	// Security holds authorization requirements for this route.
 	// Routes without Security are public by default (anonymous access allowed).
 	// Adding any security requirement makes the route protected.
 	Security *RouteSecurity
+
+	// ETag enables automatic strong ETags and If-None-Match handling for GET
+	// and HEAD routes. See AutoETag.
+	ETag bool
+
+	// Idempotency enables Idempotency-Key handling for the route. See IdempotencyKey.
+	Idempotency *RouteIdempotency
+
+	// RateLimits are the request rate limits of the route. See RateLimit.
+	RateLimits []*RouteRateLimit
+
+	// Deprecation describes the deprecation of the route. See Deprecated.
+	Deprecation *RouteDeprecation
+
+	// Transformers run on the response body of the route before the group and
+	// API transformers.
+	Transformers []Transformer
+
+	// SparseFields enables the fields query parameter selecting the response
+	// fields to return. See SparseFields.
+	SparseFields bool
+
+	// Events maps Server-Sent Event type names to their data types. It is only
+	// used to document EventStream responses in OpenAPI. See SSEEvents.
+	Events map[string]reflect.Type
+
+	// inputType and outputType are the handler's input and output types, set
+	// by Register. See Routes.
+	inputType  reflect.Type
+	outputType reflect.Type
+
+	// api is the API or group the route was registered with, set by Register.
+	api API
 }
 
 // RouteSecurity defines authorization requirements for a route.
@@ -0,0 +1,75 @@
+package zorya
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+// RouteInfo describes an operation registered with Register or one of its
+// helpers such as Get and Post.
+type RouteInfo struct {
+	// Method is the HTTP method of the operation.
+	Method string
+
+	// Path is the full path of the operation, including group prefixes.
+	Path string
+
+	// Operation is the OpenAPI operation.
+	Operation *Operation
+
+	// Input and Output are the handler's input and output struct types.
+	Input  reflect.Type
+	Output reflect.Type
+}
+
+// Routes returns the operations registered with the API and its groups,
+// sorted by path and method. Operations generated by AutoPatch and the
+// documentation endpoints are not included, as they have no input and output
+// types. With media type versioning, a path lists the operation of the first
+// version registered for it.
+//
+//	for _, route := range zorya.Routes(api) {
+//		fmt.Println(route.Method, route.Path, route.Input, route.Output)
+//	}
+func Routes(api API) []RouteInfo {
+	router, ok := rootMethodRouter(api)
+	if !ok {
+		return nil
+	}
+
+	router.mu.RLock()
+	defer router.mu.RUnlock()
+
+	var routes []RouteInfo
+	for path, p := range router.paths {
+		for method, route := range p.routes {
+			routes = append(routes, RouteInfo{
+				Method:    method,
+				Path:      path,
+				Operation: route.Operation,
+				Input:     route.inputType,
+				Output:    route.outputType,
+			})
+		}
+	}
+
+	slices.SortFunc(routes, func(a, b RouteInfo) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(methodOrder(a.Method), methodOrder(b.Method)),
+			cmp.Compare(a.Method, b.Method),
+		)
+	})
+
+	return routes
+}
+
+// methodOrder orders methods as they are usually listed: reads, then writes.
+func methodOrder(method string) int {
+	if i := slices.Index(dispatchedMethods, method); i != -1 {
+		return i
+	}
+
+	return len(dispatchedMethods)
+}
@@ -0,0 +1,190 @@
+package zorya
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	contentTypeSchemaJSON = "application/schema+json"
+
+	// jsonSchemaDialect is the JSON Schema dialect used by OpenAPI 3.1.
+	jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+	// schemaDefsPrefix is the ref prefix for schemas bundled into `$defs`.
+	schemaDefsPrefix = "#/$defs/"
+)
+
+// registerSchemasEndpoint registers the `{SchemasPath}/{name}` endpoint if configured.
+// Schemas are looked up on every request, so types registered after NewAPI are served too.
+func registerSchemasEndpoint(a API, adapter Adapter) {
+	schemasPath := a.Config().SchemasPath
+	if schemasPath == "" {
+		return
+	}
+
+	route := &BaseRoute{
+		Method: http.MethodGet,
+		Path:   schemasPath + "/{name}",
+	}
+	adapter.Handle(route, func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(adapter.ExtractRouterParams(r, route)["name"], ".json")
+
+		b, err := standaloneSchema(a.Registry().Map(), name, schemasPath)
+		if err != nil {
+			WriteErr(a, r, w, http.StatusInternalServerError, "failed to marshal schema", err)
+
+			return
+		}
+		if b == nil {
+			WriteErr(a, r, w, http.StatusNotFound, "schema not found: "+name)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", contentTypeSchemaJSON)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	})
+}
+
+// standaloneSchema returns the named schema as a JSON Schema 2020-12 document.
+// Every schema it references, directly or transitively, is bundled into `$defs`
+// and refs are rewritten to point there, so the document resolves on its own.
+// Returns nil if the schema does not exist.
+func standaloneSchema(schemas map[string]*Schema, name, schemasPath string) ([]byte, error) {
+	if _, ok := schemas[name]; !ok {
+		return nil, nil
+	}
+
+	defs := map[string]any{}
+	root, err := bundleSchema(schemas, name, defs)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ok := root.(map[string]any)
+	if !ok {
+		doc = map[string]any{}
+	}
+	// The root schema refers to itself through `#` rather than a `$defs` entry.
+	delete(defs, name)
+	rewriteRefs(doc, name)
+	for _, def := range defs {
+		rewriteRefs(def, name)
+	}
+
+	doc["$schema"] = jsonSchemaDialect
+	doc["$id"] = schemasPath + "/" + name
+	if len(defs) > 0 {
+		doc["$defs"] = defs
+	}
+
+	return json.Marshal(doc)
+}
+
+// bundleSchema converts the named schema into its generic JSON form and adds every
+// schema it references to defs. Refs are rewritten to `#/$defs/{name}`.
+func bundleSchema(schemas map[string]*Schema, name string, defs map[string]any) (any, error) {
+	b, err := json.Marshal(schemas[name])
+	if err != nil {
+		return nil, err
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	// Reserve the slot before walking to stop infinite recursion on cyclic types.
+	defs[name] = v
+
+	var refs []string
+	collectRefs(v, &refs)
+	sort.Strings(refs)
+
+	for _, ref := range refs {
+		if _, seen := defs[ref]; seen {
+			continue
+		}
+		if _, ok := schemas[ref]; !ok {
+			continue
+		}
+		if _, err := bundleSchema(schemas, ref, defs); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// collectRefs rewrites every `$ref` in v to point at `$defs` and appends
+// the referenced schema names to refs.
+func collectRefs(v any, refs *[]string) {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if ref, ok := child.(string); ok && k == "$ref" {
+				name := ref[strings.LastIndex(ref, "/")+1:]
+				node[k] = schemaDefsPrefix + name
+				*refs = append(*refs, name)
+
+				continue
+			}
+			collectRefs(child, refs)
+		}
+	case []any:
+		for _, child := range node {
+			collectRefs(child, refs)
+		}
+	}
+}
+
+// rewriteRefs points refs to the root schema at `#`, since it is not part of `$defs`.
+func rewriteRefs(v any, rootName string) {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if ref, ok := child.(string); ok && k == "$ref" && ref == schemaDefsPrefix+rootName {
+				node[k] = "#"
+
+				continue
+			}
+			rewriteRefs(child, rootName)
+		}
+	case []any:
+		for _, child := range node {
+			rewriteRefs(child, rootName)
+		}
+	}
+}
+
+// describedByLink returns the `Link` header value pointing at the schema of the
+// default success response body, or an empty string if it has no named schema.
+func describedByLink(a API, route *BaseRoute) string {
+	if a.Config() == nil || a.Config().SchemasPath == "" || route.Operation == nil {
+		return ""
+	}
+
+	resp := route.Operation.Responses[strconv.Itoa(getDefaultStatus(route))]
+	if resp == nil {
+		return ""
+	}
+
+	contentTypes := slices.Sorted(maps.Keys(resp.Content))
+	for _, ct := range contentTypes {
+		mt := resp.Content[ct]
+		if mt == nil || mt.Schema == nil || mt.Schema.Ref == "" {
+			continue
+		}
+		name := mt.Schema.Ref[strings.LastIndex(mt.Schema.Ref, "/")+1:]
+
+		return "<" + a.Config().SchemasPath + "/" + name + `>; rel="describedby"`
+	}
+
+	return ""
+}
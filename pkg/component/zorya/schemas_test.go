@@ -0,0 +1,145 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SchemaAddress struct {
+	City string `json:"city"`
+}
+
+type SchemaPerson struct {
+	Name     string         `json:"name"`
+	Address  SchemaAddress  `json:"address"`
+	Children []SchemaPerson `json:"children"`
+}
+
+type GetSchemaPersonOutput struct {
+	Body SchemaPerson `body:"structured"`
+}
+
+func TestSchemasEndpoint_EndToEnd(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/people/{id}", func(ctx context.Context, input *GetUserInput) (*GetSchemaPersonOutput, error) {
+		return &GetSchemaPersonOutput{Body: SchemaPerson{Name: "Ann"}}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/schemas/SchemaPerson", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/schema+json", recorder.Header().Get("Content-Type"))
+
+	wantedJSON := `{
+  "$defs": {
+    "SchemaAddress": {
+      "properties": {
+        "City": {
+          "type": "string"
+        }
+      },
+      "type": "object"
+    }
+  },
+  "$id": "/schemas/SchemaPerson",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "properties": {
+    "Address": {
+      "$ref": "#/$defs/SchemaAddress"
+    },
+    "Children": {
+      "items": {
+        "$ref": "#"
+      },
+      "type": ["array", "null"]
+    },
+    "Name": {
+      "type": "string"
+    }
+  },
+  "type": "object"
+}`
+	assert.JSONEq(t, wantedJSON, recorder.Body.String())
+
+	// The .json suffix is accepted as well.
+	req = httptest.NewRequest(http.MethodGet, "/schemas/SchemaAddress.json", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var address map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &address))
+	assert.NotContains(t, address, "$defs")
+}
+
+func TestSchemasEndpoint_NotFound(t *testing.T) {
+	router := chi.NewMux()
+	NewAPI(&testChiAdapter{router: router})
+
+	req := httptest.NewRequest(http.MethodGet, "/schemas/Missing", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+}
+
+func TestSchemasEndpoint_DescribedByLink(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/people/{id}", func(ctx context.Context, input *GetUserInput) (*GetSchemaPersonOutput, error) {
+		return &GetSchemaPersonOutput{Body: SchemaPerson{Name: "Ann"}}, nil
+	})
+	Get(api, "/files/{file_id}", func(ctx context.Context, input *DownloadFileInput) (*DownloadFileOutput, error) {
+		return &DownloadFileOutput{Body: []byte("data")}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/people/1", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `</schemas/SchemaPerson>; rel="describedby"`, recorder.Header().Get("Link"))
+
+	req = httptest.NewRequest(http.MethodGet, "/files/1", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Link"))
+}
+
+func TestSchemasEndpoint_Disabled(t *testing.T) {
+	router := chi.NewMux()
+	config := DefaultConfig()
+	config.SchemasPath = ""
+	api := NewAPI(&testChiAdapter{router: router}, WithConfig(config))
+
+	Get(api, "/people/{id}", func(ctx context.Context, input *GetUserInput) (*GetSchemaPersonOutput, error) {
+		return &GetSchemaPersonOutput{}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/people/1", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Empty(t, recorder.Header().Get("Link"))
+
+	req = httptest.NewRequest(http.MethodGet, "/schemas/SchemaPerson", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
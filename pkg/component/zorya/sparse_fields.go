@@ -0,0 +1,453 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const fieldSelectionContextKey contextKey = "field_selection_context"
+
+// fieldsParam is the query parameter selecting the response fields.
+const fieldsParam = "fields"
+
+// SparseFields enables sparse fieldsets for a route. Clients list the response
+// body fields they need in the fields query parameter, separated by commas,
+// with dots for nested fields. Fields of objects in arrays are selected the same
+// way, so `?fields=id,url,thumbnails.small` keeps the id and url of the body and
+// the small property of each of its thumbnails:
+//
+//	zorya.Get(api, "/media", listMedia, zorya.SparseFields())
+//
+// Requested fields are checked against the documented response body schema and
+// unknown fields are rejected with a 400 problem before the handler runs. The
+// parameter is documented in OpenAPI. Use Group.UseRouteOptions to enable it
+// for every route of a group.
+func SparseFields() func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		if r.SparseFields {
+			return
+		}
+		r.SparseFields = true
+		r.Transformers = append(r.Transformers, FieldsTransformer)
+	}
+}
+
+// FieldsTransformer is a Transformer pruning successful response bodies to the
+// fields selected by the fields query parameter of routes using SparseFields.
+// Structs become maps keyed by their JSON field names, and selected values
+// keep their Go types, so every format encodes them as it would unpruned.
+// Bodies of other requests are returned unchanged.
+func FieldsTransformer(r *http.Request, status int, result any) (any, error) {
+	selection, ok := r.Context().Value(fieldSelectionContextKey).(fieldSelection)
+	if !ok || status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return result, nil
+	}
+
+	return selection.pruneValue(reflect.ValueOf(result))
+}
+
+// fieldSelection is a tree of selected fields. A nil selection keeps the whole
+// value.
+type fieldSelection map[string]fieldSelection
+
+// add selects the field at path, a list of property names.
+func (s fieldSelection) add(path []string) {
+	node := s
+	for i, name := range path {
+		child, ok := node[name]
+		if ok && child == nil {
+			// A parent field is already selected as a whole.
+			return
+		}
+		if i == len(path)-1 {
+			node[name] = nil
+
+			return
+		}
+		if !ok {
+			child = fieldSelection{}
+			node[name] = child
+		}
+		node = child
+	}
+}
+
+// pruneValue returns the value of v with only the selected fields. Values
+// with their own JSON encoding are pruned in their JSON form.
+func (s fieldSelection) pruneValue(v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if s == nil {
+		return v.Interface(), nil
+	}
+
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return s.pruneJSON(v.Interface())
+	}
+
+	//nolint:exhaustive // Other kinds have no fields to select
+	switch v.Kind() {
+	case reflect.Struct:
+		fields, ok := jsonFieldsFor(t)
+		if !ok {
+			return s.pruneJSON(v.Interface())
+		}
+
+		pruned := make(map[string]any, len(s))
+		for _, field := range fields {
+			child, selected := s[field.name]
+			if !selected {
+				continue
+			}
+			value, err := v.FieldByIndexErr(field.index)
+			if err != nil {
+				// Nil embedded struct pointer.
+				continue
+			}
+			if (field.omitEmpty && isEmptyJSONValue(value)) || (field.omitZero && value.IsZero()) {
+				continue
+			}
+			if pruned[field.name], err = child.pruneValue(value); err != nil {
+				return nil, err
+			}
+		}
+
+		return pruned, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return s.pruneJSON(v.Interface())
+		}
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		pruned := make(map[string]any, len(s))
+		for name, child := range s {
+			value := v.MapIndex(reflect.ValueOf(name).Convert(t.Key()))
+			if !value.IsValid() {
+				continue
+			}
+			var err error
+			if pruned[name], err = child.pruneValue(value); err != nil {
+				return nil, err
+			}
+		}
+
+		return pruned, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 || (v.Kind() == reflect.Slice && v.IsNil()) {
+			return v.Interface(), nil
+		}
+
+		pruned := make([]any, v.Len())
+		for i := range pruned {
+			var err error
+			if pruned[i], err = s.pruneValue(v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+
+		return pruned, nil
+	default:
+		return v.Interface(), nil
+	}
+}
+
+// pruneJSON returns value with only the selected fields, pruned in its JSON
+// form.
+func (s fieldSelection) pruneJSON(value any) (any, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal body for field selection: %w", err)
+	}
+
+	// Keep numbers as written, so large integers survive the round trip.
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode body for field selection: %w", err)
+	}
+
+	return s.prune(decoded), nil
+}
+
+// prune returns a decoded JSON value with only the selected fields. Arrays
+// are pruned element by element.
+func (s fieldSelection) prune(value any) any {
+	if s == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		pruned := make(map[string]any, len(s))
+		for name, child := range s {
+			if field, ok := v[name]; ok {
+				pruned[name] = child.prune(field)
+			}
+		}
+
+		return pruned
+	case []any:
+		for i := range v {
+			v[i] = s.prune(v[i])
+		}
+
+		return v
+	default:
+		return value
+	}
+}
+
+// jsonField is a struct field as encoded by encoding/json.
+type jsonField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	omitZero  bool
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// jsonFieldsCache caches the jsonFields of struct types.
+	jsonFieldsCache sync.Map
+)
+
+// jsonFields caches the result of parseJSONFields.
+type jsonFields struct {
+	fields []jsonField
+	ok     bool
+}
+
+// jsonFieldsFor returns the fields of a struct type as encoded by
+// encoding/json, or false if they cannot be read through reflection.
+func jsonFieldsFor(t reflect.Type) ([]jsonField, bool) {
+	if cached, ok := jsonFieldsCache.Load(t); ok {
+		fields := cached.(*jsonFields)
+
+		return fields.fields, fields.ok
+	}
+
+	fields := &jsonFields{ok: true}
+	fields.fields = parseJSONFields(t, nil, map[string]bool{}, &fields.ok)
+	jsonFieldsCache.Store(t, fields)
+
+	return fields.fields, fields.ok
+}
+
+// parseJSONFields returns the fields of struct type t, skipping names in
+// seen. Fields of embedded structs come after the fields of t, so they are
+// shadowed by them. ok is cleared for unexported embedded structs, whose
+// promoted fields reflection cannot read.
+func parseJSONFields(t reflect.Type, index []int, seen map[string]bool, ok *bool) []jsonField {
+	var fields []jsonField
+	var embedded []reflect.StructField
+
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		tag := structField.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if structField.Anonymous && name == "" && deref(structField.Type).Kind() == reflect.Struct {
+			if !structField.IsExported() {
+				*ok = false
+			}
+			embedded = append(embedded, structField)
+
+			continue
+		}
+		if !structField.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		fields = append(fields, jsonField{
+			name:      name,
+			index:     append(slices.Clone(index), i),
+			omitEmpty: slices.Contains(strings.Split(options, ","), "omitempty"),
+			omitZero:  slices.Contains(strings.Split(options, ","), "omitzero"),
+		})
+	}
+
+	for _, structField := range embedded {
+		fields = append(fields, parseJSONFields(deref(structField.Type), append(slices.Clone(index), structField.Index...), seen, ok)...)
+	}
+
+	return fields
+}
+
+// isEmptyJSONValue reports whether encoding/json omits v from fields tagged
+// omitempty.
+func isEmptyJSONValue(v reflect.Value) bool {
+	//nolint:exhaustive // Other kinds are never empty
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+// newSparseFieldsMiddleware parses the fields query parameter of routes using
+// SparseFields, checks it against the response body schema and stores the
+// selection in the request context for FieldsTransformer.
+func newSparseFieldsMiddleware(api API, route *BaseRoute) Middleware {
+	if !route.SparseFields {
+		return nil
+	}
+
+	body := documentedResponseSchema(route.Operation, getDefaultStatus(route), "application/json")
+	registry := api.Registry()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			selection, details := parseFieldSelection(registry, body, r.URL.Query()[fieldsParam])
+			if len(details) > 0 {
+				WriteErr(api, r, w, 0, "", Error400BadRequest("invalid fields", details...))
+
+				return
+			}
+			if len(selection) == 0 {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), fieldSelectionContextKey, selection)))
+		})
+	}
+}
+
+// parseFieldSelection parses the values of the fields query parameter and
+// checks every field against the body schema.
+func parseFieldSelection(registry Registry, body *Schema, values []string) (fieldSelection, []error) {
+	selection := fieldSelection{}
+	var details []error
+
+	for _, value := range values {
+		for field := range strings.SplitSeq(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+
+			path := strings.Split(field, ".")
+			if !hasFieldPath(registry, body, path) {
+				details = append(details, &ErrorDetail{
+					Code:     "unknown_field",
+					Message:  fmt.Sprintf("unknown field %q", field),
+					Location: "query." + fieldsParam,
+				})
+
+				continue
+			}
+			selection.add(path)
+		}
+	}
+
+	return selection, details
+}
+
+// hasFieldPath reports whether the body schema has the property at path.
+func hasFieldPath(registry Registry, s *Schema, path []string) bool {
+	for _, name := range path {
+		if s = fieldSchema(registry, s, name); s == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fieldSchema returns the schema of the property name of s, looking through
+// references and arrays, or nil if s has no such property.
+func fieldSchema(registry Registry, s *Schema, name string) *Schema {
+	for s != nil {
+		if s.Ref != "" {
+			s = registry.SchemaFromRef(s.Ref)
+
+			continue
+		}
+		if s.Type == TypeArray {
+			s = s.Items
+
+			continue
+		}
+
+		break
+	}
+	if s == nil || name == "" {
+		return nil
+	}
+
+	if property, ok := s.Properties[name]; ok {
+		return property
+	}
+	if additional, ok := s.AdditionalProperties.(*Schema); ok {
+		return additional
+	}
+
+	return nil
+}
+
+// documentSparseFields documents the fields query parameter and its 400
+// response on routes using SparseFields.
+func documentSparseFields(route *BaseRoute) {
+	if !route.SparseFields {
+		return
+	}
+
+	for _, p := range route.Operation.Parameters {
+		if p.In == "query" && p.Name == fieldsParam {
+			return
+		}
+	}
+
+	explode := false
+	route.Operation.Parameters = append(route.Operation.Parameters, &Param{
+		Name: fieldsParam,
+		In:   "query",
+		Description: "Response fields to return, separated by commas, with dots for nested fields " +
+			"(e.g. id,thumbnails.small). Returns all fields if omitted; unknown fields return 400.",
+		Style:   "form",
+		Explode: &explode,
+		Schema:  &Schema{Type: TypeArray, Items: &Schema{Type: TypeString}},
+	})
+	route.Errors = append(route.Errors, http.StatusBadRequest)
+}
@@ -0,0 +1,284 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MediaThumbnails struct {
+	Small string `json:"small" schema:"small"`
+	Large string `json:"large" schema:"large"`
+}
+
+type MediaTag struct {
+	ID    int    `json:"id" schema:"id"`
+	Label string `json:"label" schema:"label"`
+}
+
+type MediaView struct {
+	ID         int64             `json:"id" schema:"id"`
+	URL        string            `json:"url" schema:"url"`
+	Title      string            `json:"title" schema:"title"`
+	Thumbnails MediaThumbnails   `json:"thumbnails" schema:"thumbnails"`
+	Tags       []MediaTag        `json:"tags" schema:"tags"`
+	Meta       map[string]string `json:"meta" schema:"meta"`
+}
+
+type GetMediaOutput struct {
+	ETag string    `schema:"ETag,location=header"`
+	Body MediaView `body:"structured"`
+}
+
+func testMediaView() MediaView {
+	return MediaView{
+		ID:         9007199254740993,
+		URL:        "https://cdn.example.com/1.jpg",
+		Title:      "Sunset",
+		Thumbnails: MediaThumbnails{Small: "s.jpg", Large: "l.jpg"},
+		Tags:       []MediaTag{{ID: 1, Label: "sky"}, {ID: 2, Label: "sea"}},
+		Meta:       map[string]string{"camera": "x100", "lens": "23mm"},
+	}
+}
+
+func newSparseFieldsTestAPI(t *testing.T) (*chi.Mux, API) {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/media/{id}", func(ctx context.Context, input *struct {
+		ID int `schema:"id,location=path"`
+	}) (*GetMediaOutput, error) {
+		return &GetMediaOutput{ETag: `"v1"`, Body: testMediaView()}, nil
+	}, SparseFields())
+
+	Get(api, "/media", func(ctx context.Context, input *struct{}) (*Page[MediaView], error) {
+		page := &Page[MediaView]{}
+		page.Body.Items = []MediaView{testMediaView(), testMediaView()}
+		page.Body.NextCursor = "next"
+
+		return page, nil
+	}, SparseFields())
+
+	Get(api, "/plain", func(ctx context.Context, input *struct{}) (*GetMediaOutput, error) {
+		return &GetMediaOutput{Body: testMediaView()}, nil
+	})
+
+	return router, api
+}
+
+func serveSparseFields(router http.Handler, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestSparseFields_Prune(t *testing.T) {
+	router, _ := newSparseFieldsTestAPI(t)
+
+	tests := map[string]string{
+		"/media/1?fields=id,url":                         `{"id": 9007199254740993, "url": "https://cdn.example.com/1.jpg"}`,
+		"/media/1?fields=id,thumbnails.small":            `{"id": 9007199254740993, "thumbnails": {"small": "s.jpg"}}`,
+		"/media/1?fields=thumbnails.small,thumbnails":    `{"thumbnails": {"small": "s.jpg", "large": "l.jpg"}}`,
+		"/media/1?fields=tags.label":                     `{"tags": [{"label": "sky"}, {"label": "sea"}]}`,
+		"/media/1?fields=meta.camera&fields=title":       `{"title": "Sunset", "meta": {"camera": "x100"}}`,
+		"/media/1?fields=%20id%20,,url":                  `{"id": 9007199254740993, "url": "https://cdn.example.com/1.jpg"}`,
+		"/media?fields=items.id,items.thumbnails.small":  `{"items": [{"id": 9007199254740993, "thumbnails": {"small": "s.jpg"}}, {"id": 9007199254740993, "thumbnails": {"small": "s.jpg"}}]}`,
+		"/media?fields=next_cursor":                      `{"next_cursor": "next"}`,
+		"/plain?fields=id":                               `{"id": 9007199254740993, "url": "https://cdn.example.com/1.jpg", "title": "Sunset", "thumbnails": {"small": "s.jpg", "large": "l.jpg"}, "tags": [{"id": 1, "label": "sky"}, {"id": 2, "label": "sea"}], "meta": {"camera": "x100", "lens": "23mm"}}`,
+		"/media/1?fields=":                               `{"id": 9007199254740993, "url": "https://cdn.example.com/1.jpg", "title": "Sunset", "thumbnails": {"small": "s.jpg", "large": "l.jpg"}, "tags": [{"id": 1, "label": "sky"}, {"id": 2, "label": "sea"}], "meta": {"camera": "x100", "lens": "23mm"}}`,
+		"/media/1?fields=url&unrelated=thumbnails.small": `{"url": "https://cdn.example.com/1.jpg"}`,
+	}
+	for target, expected := range tests {
+		recorder := serveSparseFields(router, target)
+
+		require.Equal(t, http.StatusOK, recorder.Code, target)
+		assert.JSONEq(t, expected, recorder.Body.String(), target)
+	}
+}
+
+func TestSparseFields_KeepsHeaders(t *testing.T) {
+	router, _ := newSparseFieldsTestAPI(t)
+
+	recorder := serveSparseFields(router, "/media/1?fields=id")
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `"v1"`, recorder.Header().Get("ETag"))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "9007199254740993")
+}
+
+type MediaFile struct {
+	ID      int64     `json:"id" schema:"id"`
+	Ratio   float64   `json:"ratio" schema:"ratio"`
+	Data    []byte    `json:"data" schema:"data"`
+	TakenAt time.Time `json:"taken_at" schema:"taken_at"`
+	Name    string    `json:"name" schema:"name"`
+}
+
+func TestSparseFields_CBOR(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Get(api, "/files/{id}", func(ctx context.Context, input *struct {
+		ID int `schema:"id,location=path"`
+	}) (*struct {
+		Body MediaFile `body:"structured"`
+	}, error) {
+		output := &struct {
+			Body MediaFile `body:"structured"`
+		}{}
+		output.Body = MediaFile{
+			ID:      9007199254740993,
+			Ratio:   1.5,
+			Data:    []byte{0xca, 0xfe},
+			TakenAt: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+			Name:    "sunset.jpg",
+		}
+
+		return output, nil
+	}, SparseFields())
+
+	get := func(target string) map[any]any {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", "application/cbor")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		require.Equal(t, "application/cbor", recorder.Header().Get("Content-Type"))
+
+		var body map[any]any
+		require.NoError(t, cbor.Unmarshal(recorder.Body.Bytes(), &body))
+
+		return body
+	}
+
+	full := get("/files/1")
+	pruned := get("/files/1?fields=id,ratio,data,taken_at")
+
+	assert.Equal(t, uint64(9007199254740993), pruned["id"])
+	assert.InDelta(t, 1.5, pruned["ratio"], 0)
+	assert.Equal(t, []byte{0xca, 0xfe}, pruned["data"])
+	assert.Equal(t, full["taken_at"], pruned["taken_at"])
+	assert.NotContains(t, pruned, "name")
+}
+
+func TestSparseFields_UnknownFields(t *testing.T) {
+	router, _ := newSparseFieldsTestAPI(t)
+
+	recorder := serveSparseFields(router, "/media/1?fields=id,password,thumbnails.huge,url.length,tags..id")
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "invalid fields",
+		"errors": [
+			{"code": "unknown_field", "message": "unknown field \"password\"", "location": "query.fields"},
+			{"code": "unknown_field", "message": "unknown field \"thumbnails.huge\"", "location": "query.fields"},
+			{"code": "unknown_field", "message": "unknown field \"url.length\"", "location": "query.fields"},
+			{"code": "unknown_field", "message": "unknown field \"tags..id\"", "location": "query.fields"}
+		]
+	}`, recorder.Body.String())
+
+	// Page bodies are checked against their own schema.
+	recorder = serveSparseFields(router, "/media?fields=id")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestSparseFields_SkipsHandlerOnInvalidFields(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	called := false
+	Get(api, "/media/{id}", func(ctx context.Context, input *struct {
+		ID int `schema:"id,location=path"`
+	}) (*GetMediaOutput, error) {
+		called = true
+
+		return &GetMediaOutput{Body: testMediaView()}, nil
+	}, SparseFields())
+
+	recorder := serveSparseFields(router, "/media/1?fields=unknown")
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.False(t, called)
+}
+
+func TestSparseFields_OpenAPI(t *testing.T) {
+	_, api := newSparseFieldsTestAPI(t)
+
+	op := api.OpenAPI().Paths["/media/{id}"].Get
+	var fields *Param
+	for _, param := range op.Parameters {
+		if param.Name == "fields" {
+			fields = param
+		}
+	}
+	require.NotNil(t, fields)
+
+	data, err := json.Marshal(fields)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "fields",
+		"in": "query",
+		"description": "Response fields to return, separated by commas, with dots for nested fields (e.g. id,thumbnails.small). Returns all fields if omitted; unknown fields return 400.",
+		"style": "form",
+		"explode": false,
+		"schema": {"type": "array", "items": {"type": "string"}}
+	}`, string(data))
+	assert.Contains(t, op.Responses, "400")
+
+	for _, param := range api.OpenAPI().Paths["/plain"].Get.Parameters {
+		assert.NotEqual(t, "fields", param.Name)
+	}
+}
+
+func TestSparseFields_ResponseValidationSeesFullBody(t *testing.T) {
+	router := chi.NewMux()
+	config := DefaultConfig()
+	config.ResponseValidation = ResponseValidationFail
+	api := NewAPI(&testChiAdapter{router: router}, WithConfig(config))
+
+	type RequiredBody struct {
+		ID   int    `json:"id" schema:"id" validate:"required"`
+		Name string `json:"name" schema:"name" validate:"required"`
+	}
+	type RequiredOutput struct {
+		Body RequiredBody `body:"structured"`
+	}
+
+	Get(api, "/required", func(ctx context.Context, input *struct{}) (*RequiredOutput, error) {
+		return &RequiredOutput{Body: RequiredBody{ID: 1, Name: "one"}}, nil
+	}, SparseFields())
+
+	recorder := serveSparseFields(router, "/required?fields=id")
+
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"id": 1}`, recorder.Body.String())
+}
+
+func TestFieldSelection_Add(t *testing.T) {
+	selection := fieldSelection{}
+	selection.add([]string{"a", "b", "c"})
+	selection.add([]string{"a", "d"})
+	selection.add([]string{"e"})
+	selection.add([]string{"e", "f"})
+
+	assert.Equal(t, fieldSelection{
+		"a": {"b": {"c": nil}, "d": nil},
+		"e": nil,
+	}, selection)
+}
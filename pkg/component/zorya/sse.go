@@ -0,0 +1,254 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const contentTypeEventStream = "text/event-stream"
+
+// DefaultHeartbeatInterval is the default interval between heartbeat comments
+// sent on idle event streams to keep proxies from closing the connection.
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// defaultEventName is the event type assumed by browsers when no `event:` field is sent.
+const defaultEventName = "message"
+
+// errorEventName is the event type used to report an error returned by the stream producer.
+const errorEventName = "error"
+
+// ErrInvalidEventField is returned when an event ID or type contains a line break.
+var ErrInvalidEventField = errors.New("event id and type must not contain line breaks")
+
+// Event is a single Server-Sent Event.
+type Event[T any] struct {
+	// ID sets the event ID. Clients send the last received ID back in the
+	// Last-Event-ID header when they reconnect.
+	ID string
+
+	// Event is the event type. When empty, clients treat the event as "message".
+	Event string
+
+	// Data is the event payload. Strings are sent verbatim, everything else is
+	// encoded as JSON.
+	Data T
+
+	// Retry tells the client how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// EventSender writes an event to the stream and flushes it to the client.
+type EventSender[T any] func(Event[T]) error
+
+// EventStream is a response body that is serialized as text/event-stream.
+// Use it as the body field of an output struct:
+//
+//	type ProgressOutput struct {
+//		Body zorya.EventStream[Progress] `body:"structured"`
+//	}
+//
+// The Stream function is called once response headers are written. It should
+// send events until it is done or ctx is cancelled because the client went away.
+type EventStream[T any] struct {
+	// Heartbeat is the interval between comment lines sent to keep the connection open.
+	// If == 0, uses DefaultHeartbeatInterval.
+	// If < 0, disables heartbeats.
+	Heartbeat time.Duration
+
+	// Stream produces events. lastEventID holds the Last-Event-ID request header,
+	// allowing reconnecting clients to resume where they left off.
+	Stream func(ctx context.Context, lastEventID string, send EventSender[T]) error
+}
+
+// eventStreamBody is implemented by all EventStream instantiations.
+// It lets the response writer and schema extractor handle event streams
+// without knowing the event data type.
+type eventStreamBody interface {
+	eventDataType() reflect.Type
+	writeEventStream(w http.ResponseWriter, r *http.Request, status int)
+}
+
+var eventStreamBodyType = reflect.TypeOf((*eventStreamBody)(nil)).Elem()
+
+func (s EventStream[T]) eventDataType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (s EventStream[T]) writeEventStream(w http.ResponseWriter, r *http.Request, status int) {
+	w.Header().Set("Content-Type", contentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disable response buffering in nginx so events reach the client immediately.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(status)
+
+	ew := &eventWriter{w: w, rc: http.NewResponseController(w)}
+	ew.flush()
+
+	if s.Stream == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	var wg sync.WaitGroup
+	// Heartbeats must stop before the handler returns, the writer is invalid afterwards.
+	defer wg.Wait()
+	defer cancel()
+
+	if interval := s.heartbeatInterval(); interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ew.heartbeat(ctx, interval)
+		}()
+	}
+
+	send := func(e Event[T]) error {
+		data, err := marshalEventData(e.Data)
+		if err != nil {
+			return err
+		}
+
+		return ew.write(e.ID, e.Event, data, e.Retry)
+	}
+
+	if err := s.Stream(ctx, r.Header.Get("Last-Event-ID"), send); err != nil && ctx.Err() == nil {
+		statusErr, _ := processExistingError(err)
+		data, mErr := json.Marshal(statusErr)
+		if mErr != nil {
+			return
+		}
+		_ = ew.write("", errorEventName, data, 0)
+	}
+}
+
+// heartbeatInterval returns the effective heartbeat interval.
+func (s EventStream[T]) heartbeatInterval() time.Duration {
+	if s.Heartbeat == 0 {
+		return DefaultHeartbeatInterval
+	}
+
+	return s.Heartbeat
+}
+
+// eventWriter serializes events onto the response. Writes are guarded by a mutex
+// because heartbeats are sent from a separate goroutine.
+type eventWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// write encodes a single event and flushes it to the client.
+func (ew *eventWriter) write(id, event string, data []byte, retry time.Duration) error {
+	if strings.ContainsAny(id, "\r\n") || strings.ContainsAny(event, "\r\n") {
+		return ErrInvalidEventField
+	}
+
+	var buf bytes.Buffer
+	if id != "" {
+		buf.WriteString("id: " + id + "\n")
+	}
+	if event != "" {
+		buf.WriteString("event: " + event + "\n")
+	}
+	if retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(retry.Milliseconds(), 10) + "\n")
+	}
+	// Parsers end lines at \r\n, \r or \n, so each ends a data line.
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	return ew.send(buf.Bytes())
+}
+
+// heartbeat writes a comment line at the given interval until ctx is done.
+func (ew *eventWriter) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ew.send([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// send writes raw bytes and flushes them.
+func (ew *eventWriter) send(b []byte) error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if _, err := ew.w.Write(b); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	ew.flush()
+
+	return nil
+}
+
+// flush flushes buffered data to the client if the writer supports it.
+func (ew *eventWriter) flush() {
+	_ = ew.rc.Flush()
+}
+
+// marshalEventData encodes event data. Strings and byte slices are sent as is,
+// all other values are encoded as JSON.
+func marshalEventData(v any) ([]byte, error) {
+	switch d := v.(type) {
+	case string:
+		return []byte(d), nil
+	case []byte:
+		return d, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	return data, nil
+}
+
+// SSEEvents documents the named event types an event stream may send.
+// Keys are event type names and values are example instances of the event data.
+// Without this option, the stream is documented with a single "message" event
+// using the EventStream data type.
+//
+// Example:
+//
+//	zorya.Get(api, "/jobs/{id}/events", handler,
+//		zorya.SSEEvents(map[string]any{
+//			"progress": JobProgress{},
+//			"done":     JobResult{},
+//		}),
+//	)
+func SSEEvents(events map[string]any) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		if r.Events == nil {
+			r.Events = make(map[string]reflect.Type, len(events))
+		}
+		for name, sample := range events {
+			r.Events[name] = reflect.TypeOf(sample)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ProgressEvent struct {
+	Percent int    `json:"percent"`
+	Stage   string `json:"stage"`
+}
+
+type ProgressStreamOutput struct {
+	Body EventStream[ProgressEvent] `body:"structured"`
+}
+
+func TestEventStream_WritesEvents(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	var gotLastEventID string
+	Get(api, "/progress", func(ctx context.Context, input *struct{}) (*ProgressStreamOutput, error) {
+		return &ProgressStreamOutput{
+			Body: EventStream[ProgressEvent]{
+				Heartbeat: -1,
+				Stream: func(ctx context.Context, lastEventID string, send EventSender[ProgressEvent]) error {
+					gotLastEventID = lastEventID
+					if err := send(Event[ProgressEvent]{ID: "4", Event: "progress", Data: ProgressEvent{Percent: 50, Stage: "copy"}}); err != nil {
+						return err
+					}
+
+					return send(Event[ProgressEvent]{ID: "5", Data: ProgressEvent{Percent: 100, Stage: "done"}})
+				},
+			},
+		}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/progress", nil)
+	req.Header.Set("Last-Event-ID", "3")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", recorder.Header().Get("Cache-Control"))
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, "3", gotLastEventID)
+	assert.Equal(t,
+		"id: 4\nevent: progress\ndata: {\"percent\":50,\"stage\":\"copy\"}\n\n"+
+			"id: 5\ndata: {\"percent\":100,\"stage\":\"done\"}\n\n",
+		recorder.Body.String())
+}
+
+func TestEventStream_MultilineStringAndError(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	type LogOutput struct {
+		Body EventStream[string] `body:"structured"`
+	}
+
+	Get(api, "/logs", func(ctx context.Context, input *struct{}) (*LogOutput, error) {
+		return &LogOutput{
+			Body: EventStream[string]{
+				Heartbeat: -1,
+				Stream: func(ctx context.Context, lastEventID string, send EventSender[string]) error {
+					if err := send(Event[string]{Data: "line one\nline two"}); err != nil {
+						return err
+					}
+					if err := send(Event[string]{Data: "a\r\nb\rid: injected"}); err != nil {
+						return err
+					}
+					assert.ErrorIs(t, send(Event[string]{Event: "bad\nname"}), ErrInvalidEventField)
+
+					return Error503ServiceUnavailable("log source unavailable")
+				},
+			},
+		}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/logs", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "data: line one\ndata: line two\n\n")
+	assert.Contains(t, body, "data: a\ndata: b\ndata: id: injected\n\n")
+	assert.NotContains(t, body, "\r")
+	assert.Contains(t, body, "event: error\ndata: ")
+	assert.Contains(t, body, `"status":503`)
+	assert.NotContains(t, body, "bad")
+}
+
+func TestEventStream_Heartbeat(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/idle", func(ctx context.Context, input *struct{}) (*ProgressStreamOutput, error) {
+		return &ProgressStreamOutput{
+			Body: EventStream[ProgressEvent]{
+				Heartbeat: 5 * time.Millisecond,
+				Stream: func(ctx context.Context, lastEventID string, send EventSender[ProgressEvent]) error {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(50 * time.Millisecond):
+					}
+
+					return nil
+				},
+			},
+		}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/idle", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Contains(t, recorder.Body.String(), ": heartbeat\n\n")
+}
+
+func TestEventStream_OpenAPI(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	Get(api, "/progress", func(ctx context.Context, input *struct{}) (*ProgressStreamOutput, error) {
+		return &ProgressStreamOutput{}, nil
+	})
+
+	type JobResult struct {
+		URL string `json:"url"`
+	}
+	Get(api, "/jobs", func(ctx context.Context, input *struct{}) (*ProgressStreamOutput, error) {
+		return &ProgressStreamOutput{}, nil
+	}, SSEEvents(map[string]any{
+		"progress": ProgressEvent{},
+		"done":     JobResult{},
+	}))
+
+	resp := api.OpenAPI().Paths["/progress"].Get.Responses["200"]
+	require.Contains(t, resp.Content, "text/event-stream")
+	assert.NotContains(t, resp.Content, "application/json")
+
+	stream := resp.Content["text/event-stream"].Schema
+	assert.Equal(t, TypeArray, stream.Type)
+	require.Len(t, stream.Items.OneOf, 1)
+	assert.Equal(t, "message", stream.Items.OneOf[0].Properties["event"].Const)
+	assert.Equal(t, "#/components/schemas/ProgressEvent", stream.Items.OneOf[0].Properties["data"].Ref)
+	assert.Contains(t, api.OpenAPI().Components.Schemas, "ProgressEvent")
+
+	jobs := api.OpenAPI().Paths["/jobs"].Get.Responses["200"].Content["text/event-stream"].Schema
+	require.Len(t, jobs.Items.OneOf, 2)
+	assert.Equal(t, "done", jobs.Items.OneOf[0].Properties["event"].Const)
+	assert.Equal(t, "#/components/schemas/JobResult", jobs.Items.OneOf[0].Properties["data"].Ref)
+	assert.Equal(t, "progress", jobs.Items.OneOf[1].Properties["event"].Const)
+
+	// The whole spec must still marshal.
+	_, err := json.Marshal(api.OpenAPI())
+	require.NoError(t, err)
+}
@@ -0,0 +1,175 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Timeout limits the time a route's handler may take. The handler context is
+// canceled when the timeout elapses and a 503 problem is written instead of
+// the response:
+//
+//	zorya.Post(api, "/media", createMedia, zorya.Timeout(10*time.Second))
+//
+// The response is buffered until the handler returns, so a handler running
+// late cannot write a partial body; its output is discarded. Handlers should
+// still return once their context is canceled, as they keep running until
+// they do. Do not use Timeout for streaming responses, which cannot be
+// flushed. The 503 response is documented in OpenAPI.
+func Timeout(timeout time.Duration) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.Timeout = timeout
+	}
+}
+
+// newTimeoutMiddleware runs the handler of routes with a Timeout in its own
+// goroutine with a deadline, and writes a 503 problem if it does not return
+// in time.
+func newTimeoutMiddleware(api API, route *BaseRoute) Middleware {
+	if route.Timeout <= 0 {
+		return nil
+	}
+	timeout := route.Timeout
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{w: w, header: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan *handlerPanic, 1)
+			go func() {
+				defer func() {
+					v := recover()
+					tw.finish()
+					if v != nil {
+						panicked <- &handlerPanic{value: v, stack: debug.Stack()}
+
+						return
+					}
+					close(done)
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+			}()
+
+			select {
+			case p := <-panicked:
+				// Re-panic in the request goroutine, where Recoverer can handle it.
+				panic(p)
+			case <-done:
+				tw.flush()
+
+				return
+			case <-ctx.Done():
+			}
+
+			// The handler may have returned right at the deadline, in which
+			// case its response is complete and sent as is.
+			if !tw.timeOut() {
+				select {
+				case p := <-panicked:
+					panic(p)
+				case <-done:
+				}
+				tw.flush()
+
+				return
+			}
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				WriteErr(api, r, w, 0, "", Error503ServiceUnavailable("request timed out"))
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers a response until the handler returns, so it can be
+// discarded when the handler times out.
+type timeoutWriter struct {
+	w      http.ResponseWriter
+	header http.Header
+
+	mu       sync.Mutex
+	status   int
+	body     bytes.Buffer
+	timedOut bool
+	finished bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.status == 0 && status >= http.StatusOK {
+		tw.status = status
+	}
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+
+	return tw.body.Write(b)
+}
+
+// SetReadDeadline sets the read deadline of the connection, so body read
+// timeouts apply to handlers with a timeout.
+func (tw *timeoutWriter) SetReadDeadline(deadline time.Time) error {
+	return http.NewResponseController(tw.w).SetReadDeadline(deadline)
+}
+
+// finish records that the handler returned.
+func (tw *timeoutWriter) finish() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.finished = true
+}
+
+// timeOut discards the response, so later writes of the handler fail. It
+// reports false, keeping the response, if the handler has already returned.
+func (tw *timeoutWriter) timeOut() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.finished {
+		return false
+	}
+	tw.timedOut = true
+
+	return true
+}
+
+// flush writes the buffered response of a returned handler.
+func (tw *timeoutWriter) flush() {
+	maps.Copy(tw.w.Header(), tw.header)
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	tw.w.WriteHeader(tw.status)
+	_, _ = tw.w.Write(tw.body.Bytes())
+}
+
+// documentTimeout documents the 503 response of routes with a Timeout.
+func documentTimeout(route *BaseRoute) {
+	if route.Timeout > 0 {
+		route.Errors = append(route.Errors, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,124 @@
+package zorya
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TimeoutOutput struct {
+	Version string `schema:"X-Version,location=header"`
+	Body    struct {
+		Message string `json:"message"`
+	} `body:"structured"`
+}
+
+func TestTimeout_HandlerInTime(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	Get(api, "/fast", func(ctx context.Context, input *struct{}) (*TimeoutOutput, error) {
+		output := &TimeoutOutput{Version: "v1"}
+		output.Body.Message = "done"
+
+		return output, nil
+	}, Timeout(time.Second))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "v1", recorder.Header().Get("X-Version"))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"message": "done"}`, recorder.Body.String())
+}
+
+func TestTimeout_HandlerTooSlow(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+
+	canceled := make(chan error, 1)
+	Get(api, "/slow", func(ctx context.Context, input *struct{}) (*TimeoutOutput, error) {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+
+		// Output written after the timeout is discarded.
+		output := &TimeoutOutput{Version: "late"}
+		output.Body.Message = "late"
+
+		return output, nil
+	}, Timeout(20*time.Millisecond))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(t, `{
+		"title": "Service Unavailable",
+		"status": 503,
+		"detail": "request timed out"
+	}`, recorder.Body.String())
+	assert.Empty(t, recorder.Header().Get("X-Version"))
+	assert.ErrorIs(t, <-canceled, context.DeadlineExceeded)
+}
+
+func TestTimeoutWriter_FinishedBeforeTimeOut(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	tw := &timeoutWriter{w: recorder, header: make(http.Header)}
+	_, err := tw.Write([]byte("done"))
+	require.NoError(t, err)
+
+	// A handler returning right at the deadline keeps its response.
+	tw.finish()
+	assert.False(t, tw.timeOut())
+	tw.flush()
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "done", recorder.Body.String())
+
+	// A handler still running loses it.
+	tw = &timeoutWriter{w: httptest.NewRecorder(), header: make(http.Header)}
+	assert.True(t, tw.timeOut())
+	_, err = tw.Write([]byte("late"))
+	assert.ErrorIs(t, err, http.ErrHandlerTimeout)
+}
+
+func TestTimeout_PanicsReachRecoverer(t *testing.T) {
+	var logs bytes.Buffer
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	api.UseMiddleware(Recoverer(api))
+	Get(api, "/panic", func(ctx context.Context, input *struct{}) (*TimeoutOutput, error) {
+		panic("boom")
+	}, Timeout(time.Second))
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(HeaderRequestID, "req-1")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"instance":"req-1"`)
+	assert.Contains(t, logs.String(), `"panic":"boom"`)
+	// The stack is the one of the handler goroutine.
+	assert.Contains(t, logs.String(), "timeout_test.go")
+}
+
+func TestTimeout_OpenAPI(t *testing.T) {
+	api := NewAPI(&testChiAdapter{router: chi.NewMux()})
+	handler := func(ctx context.Context, input *struct{}) (*TimeoutOutput, error) {
+		return &TimeoutOutput{}, nil
+	}
+	Get(api, "/limited", handler, Timeout(time.Second))
+	Get(api, "/unlimited", handler)
+
+	require.NotNil(t, api.OpenAPI().Paths["/limited"].Get)
+	assert.Contains(t, api.OpenAPI().Paths["/limited"].Get.Responses, "503")
+	assert.NotContains(t, api.OpenAPI().Paths["/unlimited"].Get.Responses, "503")
+}
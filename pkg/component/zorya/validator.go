@@ -36,14 +36,22 @@ func (v *PlaygroundValidator) Validate(ctx context.Context, input any, metadata
 		}}
 	}
 
+	// Render messages in the request locale when localization is enabled
+	locale := GetLocale(ctx)
+
 	errs := make([]error, len(validationErrors))
 	for i, e := range validationErrors {
 		location := locationForNamespace(metadata, e.Namespace())
 
+		message := e.Error() // Human-readable message from validator
+		if locale != nil {
+			message = e.Translate(locale.Translator) // Falls back to e.Error() without a translation
+		}
+
 		errs[i] = &ErrorDetail{
-			Code:     e.Tag(),   // "required", "email", "min", or custom tag
-			Message:  e.Error(), // Human-readable message from validator
-			Location: location,  // "query.email", "path.id", "header.auth", "body.User.email"
+			Code:     e.Tag(),  // "required", "email", "min", or custom tag
+			Message:  message,  // Human-readable message, localized if possible
+			Location: location, // "query.email", "path.id", "header.auth", "body.User.email"
 		}
 	}
 
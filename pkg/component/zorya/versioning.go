@@ -0,0 +1,429 @@
+package zorya
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/talav/talav/pkg/component/negotiation"
+)
+
+// VersionedGroup is a collection of API versions. Each version is a Group
+// with its own OpenAPI document, docs page and schema registry, served under
+// the version's URL prefix or selected by a vendor media type in the Accept
+// header.
+type VersionedGroup struct {
+	api        API
+	vendor     string
+	negotiator *negotiation.Negotiator
+
+	mu       sync.RWMutex
+	versions []*apiVersion
+	// routes holds the handlers per version of each "METHOD path" when
+	// versions are selected by media type.
+	routes map[string]map[*apiVersion]http.HandlerFunc
+}
+
+// VersionedGroupOption configures a VersionedGroup.
+type VersionedGroupOption func(*VersionedGroup)
+
+// VersionByMediaType selects versions by vendor media type instead of URL
+// prefix. With vendor "acme", version "v2" is requested with
+// `Accept: application/vnd.acme.v2+json` and its routes are registered
+// without a prefix.
+func VersionByMediaType(vendor string) VersionedGroupOption {
+	return func(g *VersionedGroup) {
+		g.vendor = vendor
+	}
+}
+
+// apiVersion is a single version of a VersionedGroup.
+type apiVersion struct {
+	name      string
+	prefix    string
+	mediaType string
+	isDefault bool
+
+	deprecation *RouteDeprecation
+
+	api   *versionAPI
+	group *Group
+}
+
+// VersionOption configures a version of a VersionedGroup.
+type VersionOption func(*apiVersion)
+
+// VersionPrefix sets the URL prefix of the version. Defaults to "/" followed
+// by the version name. With media type versioning the prefix is only used for
+// the version's OpenAPI, docs and schemas endpoints.
+func VersionPrefix(prefix string) VersionOption {
+	return func(v *apiVersion) {
+		v.prefix = prefix
+	}
+}
+
+// VersionMediaType sets the media type selecting the version with media type
+// versioning. Defaults to `application/vnd.{vendor}.{version}+json`.
+func VersionMediaType(mediaType string) VersionOption {
+	return func(v *apiVersion) {
+		v.mediaType = mediaType
+	}
+}
+
+// VersionDefault makes the version answer requests that do not ask for a
+// version with media type versioning. Defaults to the latest version added.
+func VersionDefault() VersionOption {
+	return func(v *apiVersion) {
+		v.isDefault = true
+	}
+}
+
+// VersionDeprecated marks every operation of the version as deprecated, as
+// the Deprecated route option does. The responses carry a `Deprecation`
+// header (RFC 9745) with the time the version was deprecated and, if sunset
+// is not zero, a `Sunset` header (RFC 8594) with the time the version will
+// be removed.
+func VersionDeprecated(deprecatedAt, sunset time.Time) VersionOption {
+	return func(v *apiVersion) {
+		v.deprecation = &RouteDeprecation{Since: deprecatedAt, Sunset: sunset}
+	}
+}
+
+// NewVersionedGroup creates a collection of API versions. By default versions
+// are selected by URL prefix; use VersionByMediaType to select them by the
+// Accept header instead.
+//
+//	versions := zorya.NewVersionedGroup(api)
+//	v1 := versions.Version("v1", zorya.VersionDeprecated(deprecatedAt, sunset))
+//	v2 := versions.Version("v2")
+//
+//	zorya.Get(v1, "/items", listItemsV1) // GET /v1/items, documented at /v1/openapi.json
+//	zorya.Get(v2, "/items", listItemsV2) // GET /v2/items, documented at /v2/openapi.json
+func NewVersionedGroup(api API, opts ...VersionedGroupOption) *VersionedGroup {
+	g := &VersionedGroup{
+		api:        api,
+		negotiator: negotiation.NewMediaNegotiator(),
+		routes:     make(map[string]map[*apiVersion]http.HandlerFunc),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// Version adds a version and returns the group its routes are registered in.
+//
+// The version has its own OpenAPI document, docs page and schemas endpoint,
+// served under its prefix at the paths configured for the API, e.g.
+// `/v1/openapi.json`, `/v1/docs` and `/v1/schemas/{name}`. Panics if the
+// version already exists.
+func (g *VersionedGroup) Version(name string, opts ...VersionOption) *Group {
+	v := &apiVersion{name: name, prefix: "/" + name}
+	if g.vendor != "" {
+		v.mediaType = "application/vnd." + g.vendor + "." + name + "+json"
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	g.mu.Lock()
+	for _, existing := range g.versions {
+		if existing.name == name {
+			g.mu.Unlock()
+			panic(fmt.Errorf("duplicate API version: %s", name))
+		}
+	}
+	g.versions = append(g.versions, v)
+	g.mu.Unlock()
+
+	v.api = newVersionAPI(g, v)
+	if g.vendor != "" {
+		v.group = NewGroup(v.api)
+	} else {
+		v.group = NewGroup(v.api, v.prefix)
+	}
+
+	if v.deprecation != nil {
+		deprecated := Deprecated(v.deprecation.Since, v.deprecation.Sunset, "")
+		v.group.UseRouteOptions(func(r *BaseRoute) {
+			// Routes deprecated on their own keep their deprecation.
+			if r.Deprecation == nil {
+				deprecated(r)
+			}
+		})
+	}
+
+	registerOpenAPIEndpoint(v.api, g.api.Adapter())
+	registerDocsEndpoint(v.api, g.api.Adapter())
+	registerSchemasEndpoint(v.api, g.api.Adapter())
+
+	return v.group
+}
+
+// Versions returns the names of the versions in the order they were added.
+func (g *VersionedGroup) Versions() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	names := make([]string, 0, len(g.versions))
+	for _, v := range g.versions {
+		names = append(names, v.name)
+	}
+
+	return names
+}
+
+// defaultVersion returns the version answering requests that do not select
+// one. Must be called with the lock held.
+func (g *VersionedGroup) defaultVersion() *apiVersion {
+	for _, v := range g.versions {
+		if v.isDefault {
+			return v
+		}
+	}
+
+	return g.versions[len(g.versions)-1]
+}
+
+// requestedVersion returns the version whose media type is listed in the
+// Accept header with the highest quality, or nil. Wildcards do not select a
+// version. Must be called with the lock held.
+func (g *VersionedGroup) requestedVersion(accept string) *apiVersion {
+	if accept == "" {
+		return nil
+	}
+
+	elements, err := g.negotiator.GetOrderedElements(accept)
+	if err != nil {
+		return nil
+	}
+
+	for _, element := range elements {
+		if element.Quality <= 0 {
+			continue
+		}
+		for _, v := range g.versions {
+			if strings.EqualFold(element.Type, v.mediaType) {
+				return v
+			}
+		}
+	}
+
+	return nil
+}
+
+// handle records the handler of a version for a route when versions are
+// selected by media type, registering the route's dispatcher on first use.
+func (g *VersionedGroup) handle(v *apiVersion, route *BaseRoute, handler http.HandlerFunc) {
+	key := route.Method + " " + route.Path
+
+	g.mu.Lock()
+	handlers, ok := g.routes[key]
+	if !ok {
+		handlers = make(map[*apiVersion]http.HandlerFunc)
+		g.routes[key] = handlers
+	}
+	handlers[v] = handler
+	g.mu.Unlock()
+
+	if !ok {
+		g.api.Adapter().Handle(route, g.dispatch(key))
+	}
+}
+
+// dispatch returns the handler selecting the version of a route from the
+// Accept header.
+func (g *VersionedGroup) dispatch(key string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g.mu.RLock()
+		handlers := g.routes[key]
+		requested := g.requestedVersion(r.Header.Get("Accept"))
+		selected := requested
+		if selected == nil {
+			selected = g.defaultVersion()
+		}
+		handler := handlers[selected]
+		var available []string
+		for _, v := range g.versions {
+			if handlers[v] != nil {
+				available = append(available, v.mediaType)
+			}
+		}
+		g.mu.RUnlock()
+
+		addVary(w.Header(), "Accept")
+
+		switch {
+		case handler != nil:
+			handler(w, r)
+		case requested != nil:
+			WriteErr(selected.api, r, w, 0, "", Error404NotFound(
+				fmt.Sprintf("%s %s is not available in version %s", r.Method, r.URL.Path, selected.name),
+			))
+		default:
+			WriteErr(g.api, r, w, 0, "", Error406NotAcceptable(
+				"select a version with one of the media types: "+strings.Join(available, ", "),
+			))
+		}
+	}
+}
+
+// versionAdapter registers the routes of a version selected by media type
+// with its VersionedGroup.
+type versionAdapter struct {
+	Adapter
+	group   *VersionedGroup
+	version *apiVersion
+}
+
+func (a *versionAdapter) Handle(route *BaseRoute, handler http.HandlerFunc) {
+	a.group.handle(a.version, route, handler)
+}
+
+// versionAPI is the API of a single version. It shares everything with the
+// parent API except the OpenAPI document, the schema registry, the paths of
+// the documentation endpoints and, with media type versioning, the adapter
+// and the negotiated response media type.
+type versionAPI struct {
+	API
+	version                 *apiVersion
+	negotiator              *negotiation.Negotiator
+	adapter                 Adapter
+	config                  *Config
+	openAPI                 *OpenAPI
+	registry                Registry
+	requestSchemaExtractor  *requestSchemaExtractor
+	responseSchemaExtractor *ResponseSchemaExtractor
+}
+
+// newVersionAPI creates the API of a version.
+func newVersionAPI(g *VersionedGroup, v *apiVersion) *versionAPI {
+	parent := g.api
+
+	a := &versionAPI{
+		API:        parent,
+		version:    v,
+		negotiator: g.negotiator,
+		adapter:    parent.Adapter(),
+		config:     versionConfig(parent.Config(), v.prefix),
+		openAPI:    versionOpenAPI(parent.OpenAPI(), v, g.vendor == ""),
+		registry:   NewMapRegistry("#/components/schemas/", DefaultSchemaNamer, parent.Metadata()),
+	}
+	if g.vendor != "" {
+		a.adapter = &versionAdapter{Adapter: parent.Adapter(), group: g, version: v}
+	}
+
+	parentRequest := parent.RequestSchemaExtractor()
+	a.requestSchemaExtractor = NewRequestSchemaExtractor(a.registry, parent.Metadata())
+	a.requestSchemaExtractor.contentTypes = parentRequest.contentTypes
+
+	parentResponse := parent.ResponseSchemaExtractor()
+	a.responseSchemaExtractor = NewResponseSchemaExtractor(a.registry, newSchemaBuilder(a.registry, parent.Metadata()), parent.Metadata())
+	a.responseSchemaExtractor.contentTypes = parentResponse.contentTypes
+	a.responseSchemaExtractor.strictNegotiation = parentResponse.strictNegotiation
+
+	if v.mediaType != "" {
+		a.requestSchemaExtractor.contentTypes = append([]string{v.mediaType}, parentRequest.contentTypes...)
+		a.responseSchemaExtractor.contentTypes = append([]string{v.mediaType}, parentResponse.contentTypes...)
+	}
+
+	return a
+}
+
+// versionConfig returns the parent configuration with the documentation
+// endpoints moved under the version prefix.
+func versionConfig(parent *Config, prefix string) *Config {
+	config := *parent
+	for _, path := range []*string{&config.OpenAPIPath, &config.DocsPath, &config.SchemasPath} {
+		if *path != "" {
+			*path = prefix + *path
+		}
+	}
+
+	return &config
+}
+
+// versionOpenAPI returns a new OpenAPI document for a version, sharing the
+// parent document's info, servers and security schemes. With URL versioning
+// the version prefix is added to the server URLs, so paths are documented
+// relative to the version.
+func versionOpenAPI(parent *OpenAPI, v *apiVersion, prefixServers bool) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI:           parent.OpenAPI,
+		JSONSchemaDialect: parent.JSONSchemaDialect,
+		Security:          parent.Security,
+		Tags:              parent.Tags,
+		ExternalDocs:      parent.ExternalDocs,
+		Components:        &Components{Schemas: make(map[string]*Schema)},
+	}
+
+	if parent.Info != nil {
+		info := *parent.Info
+		info.Version = v.name
+		doc.Info = &info
+	} else {
+		doc.Info = &Info{Title: "API", Version: v.name}
+	}
+
+	if parent.Components != nil {
+		doc.Components.SecuritySchemes = maps.Clone(parent.Components.SecuritySchemes)
+	}
+
+	servers := parent.Servers
+	if len(servers) == 0 && prefixServers {
+		servers = []*Server{{URL: ""}}
+	}
+	for _, server := range servers {
+		copied := *server
+		if prefixServers {
+			copied.URL = strings.TrimSuffix(copied.URL, "/") + v.prefix
+		}
+		doc.Servers = append(doc.Servers, &copied)
+	}
+
+	return doc
+}
+
+func (a *versionAPI) Adapter() Adapter {
+	return a.adapter
+}
+
+func (a *versionAPI) Config() *Config {
+	return a.config
+}
+
+func (a *versionAPI) OpenAPI() *OpenAPI {
+	return a.openAPI
+}
+
+func (a *versionAPI) Registry() Registry {
+	return a.registry
+}
+
+func (a *versionAPI) RequestSchemaExtractor() *requestSchemaExtractor {
+	return a.requestSchemaExtractor
+}
+
+func (a *versionAPI) ResponseSchemaExtractor() *ResponseSchemaExtractor {
+	return a.responseSchemaExtractor
+}
+
+// Negotiate answers with the version's media type when the Accept header
+// lists it, and negotiates with the parent API otherwise.
+func (a *versionAPI) Negotiate(accept string) (string, error) {
+	if a.version.mediaType != "" && accept != "" {
+		elements, err := a.negotiator.GetOrderedElements(accept)
+		if err == nil && slices.ContainsFunc(elements, func(h *negotiation.Header) bool {
+			return h.Quality > 0 && strings.EqualFold(h.Type, a.version.mediaType)
+		}) {
+			return a.version.mediaType, nil
+		}
+	}
+
+	return a.API.Negotiate(accept)
+}
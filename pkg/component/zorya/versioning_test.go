@@ -0,0 +1,170 @@
+package zorya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ItemV1 struct {
+	Name string
+}
+
+type ItemV2 struct {
+	Title string
+	Tags  []string
+}
+
+type GetItemV1Output struct {
+	Body ItemV1 `body:"structured"`
+}
+
+type GetItemV2Output struct {
+	Body ItemV2 `body:"structured"`
+}
+
+func getItemV1(ctx context.Context, input *struct{}) (*GetItemV1Output, error) {
+	return &GetItemV1Output{Body: ItemV1{Name: "Widget"}}, nil
+}
+
+func getItemV2(ctx context.Context, input *struct{}) (*GetItemV2Output, error) {
+	return &GetItemV2Output{Body: ItemV2{Title: "Widget", Tags: []string{"new"}}}, nil
+}
+
+func getVersioned(router http.Handler, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestVersionedGroup_URLPrefix(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithConfig(DefaultConfig()))
+	versions := NewVersionedGroup(api)
+	v1 := versions.Version("v1")
+	v2 := versions.Version("v2")
+	Get(v1, "/items/1", getItemV1)
+	Get(v2, "/items/1", getItemV2)
+
+	assert.Equal(t, []string{"v1", "v2"}, versions.Versions())
+
+	recorder := getVersioned(router, "/v1/items/1", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"Name":"Widget"}`, recorder.Body.String())
+	assert.Equal(t, `</v1/schemas/ItemV1>; rel="describedby"`, recorder.Header().Get("Link"))
+
+	recorder = getVersioned(router, "/v2/items/1", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"Title":"Widget","Tags":["new"]}`, recorder.Body.String())
+
+	// Each version has its own document, with paths relative to the version.
+	assert.Empty(t, api.OpenAPI().Paths)
+	assert.Contains(t, v1.OpenAPI().Paths, "/items/1")
+	assert.Equal(t, "v1", v1.OpenAPI().Info.Version)
+	require.Len(t, v1.OpenAPI().Servers, 1)
+	assert.Equal(t, "/v1", v1.OpenAPI().Servers[0].URL)
+
+	// ...and its own schema registry.
+	assert.Contains(t, v1.Registry().Map(), "ItemV1")
+	assert.NotContains(t, v1.Registry().Map(), "ItemV2")
+	assert.Contains(t, v2.Registry().Map(), "ItemV2")
+	assert.Empty(t, api.Registry().Map())
+}
+
+func TestVersionedGroup_DocumentationEndpoints(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithConfig(DefaultConfig()))
+	versions := NewVersionedGroup(api)
+	Get(versions.Version("v1"), "/items/1", getItemV1)
+	Get(versions.Version("v2"), "/items/1", getItemV2)
+
+	recorder := getVersioned(router, "/v2/openapi.json", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &doc))
+	assert.Equal(t, "v2", doc["info"].(map[string]any)["version"])
+	assert.Contains(t, doc["components"].(map[string]any)["schemas"], "ItemV2")
+	assert.NotContains(t, doc["components"].(map[string]any)["schemas"], "ItemV1")
+
+	recorder = getVersioned(router, "/v1/docs", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `apiDescriptionUrl="/v1/openapi.json"`)
+
+	assert.Equal(t, http.StatusOK, getVersioned(router, "/v1/schemas/ItemV1", "").Code)
+	assert.Equal(t, http.StatusNotFound, getVersioned(router, "/v1/schemas/ItemV2", "").Code)
+}
+
+func TestVersionedGroup_MediaType(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router}, WithConfig(DefaultConfig()))
+	versions := NewVersionedGroup(api, VersionByMediaType("acme"))
+	v1 := versions.Version("v1")
+	v2 := versions.Version("v2")
+	Get(v1, "/items/1", getItemV1)
+	Get(v2, "/items/1", getItemV2)
+	Get(v1, "/legacy", getItemV1)
+
+	recorder := getVersioned(router, "/items/1", "application/vnd.acme.v1+json")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"Name":"Widget"}`, recorder.Body.String())
+	assert.Equal(t, "application/vnd.acme.v1+json", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Header().Values("Vary"), "Accept")
+
+	recorder = getVersioned(router, "/items/1", "application/vnd.acme.v2+json, application/vnd.acme.v1+json;q=0.5")
+	assert.JSONEq(t, `{"Title":"Widget","Tags":["new"]}`, recorder.Body.String())
+
+	// Requests without a version get the latest version.
+	recorder = getVersioned(router, "/items/1", "application/json")
+	assert.JSONEq(t, `{"Title":"Widget","Tags":["new"]}`, recorder.Body.String())
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	// Routes missing in the requested or default version.
+	assert.Equal(t, http.StatusNotFound, getVersioned(router, "/legacy", "application/vnd.acme.v2+json").Code)
+	assert.Equal(t, http.StatusNotAcceptable, getVersioned(router, "/legacy", "").Code)
+	assert.Equal(t, http.StatusOK, getVersioned(router, "/legacy", "application/vnd.acme.v1+json").Code)
+
+	// Operations document the version media type.
+	op := v2.OpenAPI().Paths["/items/1"].Get
+	assert.Contains(t, op.Responses["200"].Content, "application/vnd.acme.v2+json")
+	assert.Equal(t, http.StatusOK, getVersioned(router, "/v2/openapi.json", "").Code)
+}
+
+func TestVersionedGroup_Deprecated(t *testing.T) {
+	router := chi.NewMux()
+	api := NewAPI(&testChiAdapter{router: router})
+	versions := NewVersionedGroup(api)
+	deprecatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	v1 := versions.Version("v1", VersionDeprecated(deprecatedAt, sunset))
+	v2 := versions.Version("v2")
+	Get(v1, "/items/1", getItemV1)
+	Get(v2, "/items/1", getItemV2)
+
+	recorder := getVersioned(router, "/v1/items/1", "")
+	assert.Equal(t, "@1767225600", recorder.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 31 Dec 2026 00:00:00 GMT", recorder.Header().Get("Sunset"))
+	assert.True(t, v1.OpenAPI().Paths["/items/1"].Get.Deprecated)
+
+	recorder = getVersioned(router, "/v2/items/1", "")
+	assert.Empty(t, recorder.Header().Get("Deprecation"))
+	assert.False(t, v2.OpenAPI().Paths["/items/1"].Get.Deprecated)
+}
+
+func TestVersionedGroup_DuplicateVersion(t *testing.T) {
+	versions := NewVersionedGroup(NewAPI(&testChiAdapter{router: chi.NewMux()}))
+	versions.Version("v1")
+
+	assert.Panics(t, func() { versions.Version("v1") })
+}
@@ -0,0 +1,135 @@
+// Package zoryatest calls zorya operations in-process from tests.
+//
+// Do encodes an input struct into a request, serves it with the API's
+// adapter and decodes the response into the output struct, or returns the
+// problem document as a *zorya.ErrorModel:
+//
+//	output, err := zoryatest.Do[GetUserInput, GetUserOutput](api, http.MethodGet, "/users/{id}", &GetUserInput{ID: "42"})
+//	require.NoError(t, err)
+//	assert.Equal(t, "42", output.Body.ID)
+//
+// Every response is checked against the operation's OpenAPI document: the
+// status must be documented and JSON bodies must match the documented
+// schema. Responses breaking the contract return a *ContractError.
+package zoryatest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/talav/talav/pkg/component/zorya"
+	"github.com/talav/talav/pkg/component/zorya/client"
+)
+
+// baseURL is the URL requests are sent to. It is never resolved.
+const baseURL = "http://example.com"
+
+// ContractError is returned when a response does not match the operation's
+// OpenAPI document.
+type ContractError struct {
+	Method     string
+	Path       string
+	Status     int
+	Violations []string
+}
+
+func (e *ContractError) Error() string {
+	return fmt.Sprintf("%s %s: response %d does not match the OpenAPI document: %s",
+		e.Method, e.Path, e.Status, strings.Join(e.Violations, "; "))
+}
+
+// Do calls the operation registered for method and path, a path template such
+// as "/users/{id}", with the input struct and decodes the response into a
+// new output struct O.
+//
+// Error responses return a *zorya.ErrorModel, and responses that do not
+// match the operation's OpenAPI document a *ContractError. Editors modify the
+// request before it is served, for example to add authentication headers.
+func Do[I, O any](api zorya.API, method, path string, input *I, editors ...client.RequestEditor) (*O, error) {
+	operation, err := findOperation(api, method, path)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []client.Option{client.WithHTTPClient(&doer{api: api, method: method, path: path, operation: operation})}
+	for _, editor := range editors {
+		opts = append(opts, client.WithRequestEditor(editor))
+	}
+
+	var body any
+	if input != nil {
+		body = input
+	}
+
+	return client.Do[O](context.Background(), client.New(baseURL, opts...), method, path, body)
+}
+
+// findOperation returns the operation registered for method and path.
+func findOperation(api zorya.API, method, path string) (*zorya.Operation, error) {
+	for _, route := range zorya.Routes(api) {
+		if route.Method == method && route.Path == path {
+			return route.Operation, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no operation registered for %s %s", method, path)
+}
+
+// doer serves requests with the API's adapter and checks the responses
+// against the operation.
+type doer struct {
+	api       zorya.API
+	method    string
+	path      string
+	operation *zorya.Operation
+}
+
+func (d *doer) Do(request *http.Request) (*http.Response, error) {
+	request.RemoteAddr = "192.0.2.1:1234"
+
+	recorder := httptest.NewRecorder()
+	d.api.Adapter().ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	if violations := d.check(response, recorder.Body.Bytes()); len(violations) > 0 {
+		return nil, &ContractError{Method: d.method, Path: d.path, Status: response.StatusCode, Violations: violations}
+	}
+
+	return response, nil
+}
+
+// check returns the ways a response breaks the operation's OpenAPI document.
+func (d *doer) check(response *http.Response, body []byte) []string {
+	if d.operation == nil {
+		return nil
+	}
+
+	status := strconv.Itoa(response.StatusCode)
+	if d.operation.Responses[status] == nil && d.operation.Responses["default"] == nil {
+		return []string{"status " + status + " is not documented"}
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if len(body) == 0 || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{"body is not valid JSON: " + err.Error()}
+	}
+
+	var violations []string
+	for _, detail := range zorya.ValidateResponseBody(d.api, d.operation, response.StatusCode, mediaType, value) {
+		violations = append(violations, detail.Error())
+	}
+
+	return violations
+}
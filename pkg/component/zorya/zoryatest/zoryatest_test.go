@@ -0,0 +1,114 @@
+package zoryatest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/talav/talav/pkg/component/zorya"
+	"github.com/talav/talav/pkg/component/zorya/adapters"
+)
+
+type ItemInput struct {
+	ID    string `schema:"id,location=path"`
+	Owner string `schema:"X-Owner,location=header"`
+}
+
+type ItemOutput struct {
+	ETag string `schema:"ETag,location=header"`
+	Body struct {
+		ID     string
+		Owner  string
+		Status string `validate:"oneof=active inactive"`
+	} `body:"structured"`
+}
+
+func newTestAPI(status string) zorya.API {
+	api := zorya.NewAPI(adapters.NewChi(chi.NewMux()))
+	handler := func(ctx context.Context, input *ItemInput) (*ItemOutput, error) {
+		if input.ID == "missing" {
+			return nil, zorya.Error404NotFound("item not found")
+		}
+
+		output := &ItemOutput{ETag: `"v1"`}
+		output.Body.ID = input.ID
+		output.Body.Owner = input.Owner
+		output.Body.Status = status
+
+		return output, nil
+	}
+	zorya.Get(api, "/items/{id}", handler, func(route *zorya.BaseRoute) {
+		route.Errors = []int{http.StatusNotFound}
+	})
+	zorya.Get(api, "/undocumented/{id}", handler)
+
+	return api
+}
+
+func TestDo(t *testing.T) {
+	api := newTestAPI("active")
+
+	output, err := Do[ItemInput, ItemOutput](api, http.MethodGet, "/items/{id}", &ItemInput{ID: "1", Owner: "ann"})
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, output.ETag)
+	assert.Equal(t, "1", output.Body.ID)
+	assert.Equal(t, "ann", output.Body.Owner)
+	assert.Equal(t, "active", output.Body.Status)
+}
+
+func TestDo_RequestEditor(t *testing.T) {
+	api := newTestAPI("active")
+
+	output, err := Do[ItemInput, ItemOutput](api, http.MethodGet, "/items/{id}", &ItemInput{ID: "1"},
+		func(ctx context.Context, r *http.Request) error {
+			r.Header.Set("X-Owner", "bob")
+
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", output.Body.Owner)
+}
+
+func TestDo_ErrorModel(t *testing.T) {
+	api := newTestAPI("active")
+
+	_, err := Do[ItemInput, ItemOutput](api, http.MethodGet, "/items/{id}", &ItemInput{ID: "missing"})
+
+	var problem *zorya.ErrorModel
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "item not found", problem.Detail)
+}
+
+func TestDo_UndocumentedStatus(t *testing.T) {
+	api := newTestAPI("active")
+
+	_, err := Do[ItemInput, ItemOutput](api, http.MethodGet, "/undocumented/{id}", &ItemInput{ID: "missing"})
+
+	var contractErr *ContractError
+	require.ErrorAs(t, err, &contractErr)
+	assert.Equal(t, http.StatusNotFound, contractErr.Status)
+	assert.Equal(t, []string{"status 404 is not documented"}, contractErr.Violations)
+	assert.EqualError(t, err, "GET /undocumented/{id}: response 404 does not match the OpenAPI document: status 404 is not documented")
+}
+
+func TestDo_SchemaViolation(t *testing.T) {
+	api := newTestAPI("archived")
+
+	_, err := Do[ItemInput, ItemOutput](api, http.MethodGet, "/items/{id}", &ItemInput{ID: "1"})
+
+	var contractErr *ContractError
+	require.ErrorAs(t, err, &contractErr)
+	assert.Equal(t, http.StatusOK, contractErr.Status)
+	assert.Equal(t, []string{"value must be one of [active inactive] (body.Status)"}, contractErr.Violations)
+}
+
+func TestDo_UnknownOperation(t *testing.T) {
+	api := newTestAPI("active")
+
+	_, err := Do[ItemInput, ItemOutput](api, http.MethodPost, "/items/{id}", &ItemInput{ID: "1"})
+	require.EqualError(t, err, "no operation registered for POST /items/{id}")
+}
@@ -5,6 +5,7 @@ go 1.25.0
 require (
 	github.com/go-chi/chi/v5 v5.2.3
 	github.com/go-chi/httplog/v3 v3.3.0
+	github.com/go-playground/universal-translator v0.18.1
 	github.com/stretchr/testify v1.11.1
 	github.com/talav/talav/pkg/component/httpserver v0.0.0-20260113020624-483deb756407
 	github.com/talav/talav/pkg/component/zorya v0.0.0-20260104025751-ae831fd7ee9c
@@ -21,7 +22,6 @@ require (
 	github.com/fxamacker/cbor/v2 v2.9.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.11 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
-	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/go-playground/validator/v10 v10.29.0 // indirect
 	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
 	github.com/gofiber/fiber/v2 v2.52.10 // indirect
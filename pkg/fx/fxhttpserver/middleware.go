@@ -32,6 +32,10 @@ const (
 	// PriorityHTTPLog is the priority for HTTPLog middleware.
 	PriorityHTTPLog = 200
 
+	// PriorityCompression is the priority for response compression middleware.
+	// It runs inside HTTPLog, so logged response sizes are the compressed sizes.
+	PriorityCompression = 225
+
 	// PriorityBeforeZorya is the default priority for user middlewares.
 	// Use this or higher for middlewares that should run after infrastructure.
 	PriorityBeforeZorya = 250
@@ -1,11 +1,14 @@
 package fxhttpserver
 
 import (
+	"errors"
 	"log/slog"
+	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/httplog/v3"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/talav/talav/pkg/component/httpserver"
 	"github.com/talav/talav/pkg/component/httpserver/cmd"
 	"github.com/talav/talav/pkg/component/zorya"
@@ -32,6 +35,10 @@ var FxHTTPServerModule = fx.Module(
 	),
 	// Register serve-http command
 	fxcore.AsRootCommand(cmd.NewServeHTTPCmd),
+	// Register generate-client command
+	fxcore.AsRootCommand(cmd.NewGenerateClientCmd),
+	// Register openapi-diff command
+	fxcore.AsRootCommand(cmd.NewOpenAPIDiffCmd),
 )
 
 // MiddlewareParams allows injection of registered middlewares.
@@ -40,14 +47,24 @@ type MiddlewareParams struct {
 	Middlewares []middlewareEntry `group:"httpserver-middlewares"`
 }
 
+// TranslatorParams allows injection of the translator localizing error responses.
+// It is required when api.locales is configured.
+type TranslatorParams struct {
+	fx.In
+	Translator *ut.UniversalTranslator `optional:"true"`
+}
+
 // newFxZoryaAPI creates a new Zorya API instance with router and infrastructure middleware configured.
 // The router is created here, middleware is added, then Zorya adapter wraps it.
-func newFxZoryaAPI(cfg httpserver.Config, logger *slog.Logger, params MiddlewareParams) (zorya.API, error) {
+func newFxZoryaAPI(cfg httpserver.Config, logger *slog.Logger, params MiddlewareParams, translator TranslatorParams) (zorya.API, error) {
 	// Create router
 	router := chi.NewRouter()
 
 	// Build all middlewares (built-in + user)
-	allMiddlewares := buildAllMiddlewares(params.Middlewares, cfg, logger)
+	allMiddlewares, err := buildAllMiddlewares(params.Middlewares, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Sort all middlewares by priority
 	sortedMiddlewares := sortMiddlewares(allMiddlewares)
@@ -63,18 +80,43 @@ func newFxZoryaAPI(cfg httpserver.Config, logger *slog.Logger, params Middleware
 	// Create Zorya adapter with the configured router
 	adapter := adapters.NewChi(router)
 
-	// Create Zorya API with the adapter
-	api := zorya.NewAPI(
-		adapter,
+	zoryaConfig, err := cfg.ToZoryaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []zorya.Option{
 		zorya.WithOpenAPI(cfg.ToZoryaOpenAPI()),
-		zorya.WithConfig(cfg.ToZoryaConfig()),
-	)
+		zorya.WithConfig(zoryaConfig),
+		zorya.WithLogger(logger),
+	}
+
+	// Localize error responses in the configured locales
+	if len(cfg.API.Locales) > 0 {
+		if translator.Translator == nil {
+			return nil, errors.New("httpserver api.locales requires a *ut.UniversalTranslator")
+		}
+		localizer, err := zorya.NewLocalizer(translator.Translator, cfg.API.Locales...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, zorya.WithLocalizer(localizer))
+	}
+
+	// Create Zorya API with the adapter
+	api := zorya.NewAPI(adapter, opts...)
+
+	// Answer handler panics with a 500 problem carrying the request ID
+	api.UseMiddleware(zorya.Recoverer(api, zorya.RecoverRequestID(func(r *http.Request) string {
+		return middleware.GetReqID(r.Context())
+	})))
 
 	return api, nil
 }
 
 // buildAllMiddlewares combines built-in middlewares with user-registered middlewares.
-func buildAllMiddlewares(userMiddlewares []middlewareEntry, cfg httpserver.Config, logger *slog.Logger) []middlewareEntry {
+// It returns an error for invalid middleware settings.
+func buildAllMiddlewares(userMiddlewares []middlewareEntry, cfg httpserver.Config, logger *slog.Logger) ([]middlewareEntry, error) {
 	allMiddlewares := make([]middlewareEntry, 0, len(userMiddlewares)+2)
 
 	// Add built-in RequestID middleware (always)
@@ -109,9 +151,24 @@ func buildAllMiddlewares(userMiddlewares []middlewareEntry, cfg httpserver.Confi
 		})
 	}
 
+	// Add built-in compression middleware (if enabled)
+	// Use order 0 to ensure it's first among same-priority middlewares
+	if cfg.Compression.Enabled {
+		compression, err := httpserver.NewCompressionMiddleware(cfg.Compression)
+		if err != nil {
+			return nil, err
+		}
+		allMiddlewares = append(allMiddlewares, middlewareEntry{
+			middleware: compression,
+			priority:   PriorityCompression,
+			name:       "compression",
+			order:      0,
+		})
+	}
+
 	// Add user middlewares
 	// Order is preserved via the order field assigned at registration time
 	allMiddlewares = append(allMiddlewares, userMiddlewares...)
 
-	return allMiddlewares
+	return allMiddlewares, nil
 }
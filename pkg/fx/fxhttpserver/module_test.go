@@ -1,6 +1,7 @@
 package fxhttpserver
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -181,5 +182,59 @@ func TestModule_MiddlewarePriorityConstants(t *testing.T) {
 	// Verify priority constants are set correctly
 	assert.Equal(t, 100, PriorityRequestID, "PriorityRequestID should be 100")
 	assert.Equal(t, 200, PriorityHTTPLog, "PriorityHTTPLog should be 200")
+	assert.Equal(t, 225, PriorityCompression, "PriorityCompression should be 225")
 	assert.Equal(t, 250, PriorityBeforeZorya, "PriorityBeforeZorya should be 250")
 }
+
+func TestModule_InvalidResponseValidation(t *testing.T) {
+	cfg := httpserver.DefaultConfig()
+	cfg.API.ResponseValidation = "strict"
+
+	var api zorya.API
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlogger.FxLoggerModule,
+		FxHTTPServerModule,
+		fx.Replace(cfg),
+		fx.Populate(&api),
+	)
+
+	require.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), `unknown value "strict"`)
+}
+
+func TestModule_InvalidCompressionEncoding(t *testing.T) {
+	cfg := httpserver.DefaultConfig()
+	cfg.Compression.Enabled = true
+	cfg.Compression.Encodings = []string{"gzip", "br"}
+
+	var api zorya.API
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlogger.FxLoggerModule,
+		FxHTTPServerModule,
+		fx.Replace(cfg),
+		fx.Populate(&api),
+	)
+
+	require.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), `unsupported encoding "br"`)
+}
+
+func TestModule_Recoverer(t *testing.T) {
+	api := setupTestAPI(t, httpserver.DefaultConfig())
+	zorya.Get(api, "/panic", func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set("X-Request-Id", "req-42")
+	rec := httptest.NewRecorder()
+	api.Adapter().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `"instance":"req-42"`)
+}